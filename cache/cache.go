@@ -0,0 +1,290 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cache provides in-memory caches with LRU, LFU
+// and ARC eviction, built on the lists and hash tables of
+// package ds.
+package cache
+
+import (
+	"time"
+
+	"github.com/davidrjenni/lib/ds"
+)
+
+// Policy is an eviction policy.
+type Policy int
+
+const (
+	// LRU evicts the least recently used entry.
+	LRU Policy = iota
+	// LFU evicts the least frequently used entry,
+	// and the least recently used one among those
+	// with the same frequency.
+	LFU
+	// ARC is the adaptive replacement cache by
+	// Megiddo and Modha, which balances recency
+	// and frequency by tracking recently evicted
+	// keys. It requires a capacity in entries.
+	ARC
+)
+
+// Reason is the reason why an entry was removed.
+type Reason int
+
+const (
+	// Evicted entries made room for other entries.
+	Evicted Reason = iota
+	// Expired entries outlived their time to live.
+	Expired
+	// Removed entries were removed by Remove.
+	Removed
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Config configures a cache.
+type Config[K comparable, V any] struct {
+	Policy   Policy                   // eviction policy
+	Capacity int                      // maximum number of entries, unlimited if 0
+	MaxBytes int64                    // maximum total size of the entries, unlimited if 0
+	Size     func(k K, v V) int64     // size of an entry, 1 if nil
+	TTL      time.Duration            // time to live of an entry, unlimited if 0
+	OnEvict  func(k K, v V, r Reason) // called when an entry is removed, if not nil
+}
+
+// Stats are the statistics of a cache.
+type Stats struct {
+	Hits        uint64 // number of successful lookups
+	Misses      uint64 // number of failed lookups
+	Evictions   uint64 // number of evicted entries
+	Expirations uint64 // number of expired entries
+}
+
+// HitRate returns the ratio of hits to lookups.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s *Stats) add(t Stats) {
+	s.Hits += t.Hits
+	s.Misses += t.Misses
+	s.Evictions += t.Evictions
+	s.Expirations += t.Expirations
+}
+
+// entry represents a cached entry.
+type entry[K comparable, V any] struct {
+	k    K
+	v    V
+	size int64                      // size of the entry
+	exp  time.Time                  // expiration time, zero if none
+	e    *ds.Element[*entry[K, V]]  // handle in the list of the policy
+	b    *ds.Element[*bucket[K, V]] // LFU: frequency bucket
+	t2   bool                       // ARC: entry is in T2
+}
+
+// Cache is a cache with a bounded number or total size
+// of entries. It is not safe for concurrent use; see
+// Sharded for a concurrency-safe cache.
+type Cache[K comparable, V any] struct {
+	c     Config[K, V]
+	m     ds.LinearHashTable[K, *entry[K, V]]
+	p     policy[K, V]
+	bytes int64
+	stats Stats
+	now   func() time.Time
+}
+
+// New returns an empty cache with the given
+// configuration. It panics if the policy is
+// ARC and the capacity is not positive.
+func New[K comparable, V any](c Config[K, V]) *Cache[K, V] {
+	ca := &Cache[K, V]{c: c, now: time.Now}
+	switch c.Policy {
+	case LFU:
+		ca.p = new(lfu[K, V])
+	case ARC:
+		if c.Capacity <= 0 {
+			panic("cache: ARC requires a capacity")
+		}
+		ca.p = &arc[K, V]{c: c.Capacity}
+	default:
+		ca.p = new(lru[K, V])
+	}
+	return ca
+}
+
+// Len returns the number of entries.
+func (c *Cache[K, V]) Len() int { return c.m.Len() }
+
+// Bytes returns the total size of the entries.
+func (c *Cache[K, V]) Bytes() int64 { return c.bytes }
+
+// Stats returns the statistics of the cache.
+func (c *Cache[K, V]) Stats() Stats { return c.stats }
+
+// Get returns the value of the given key
+// and records the access. Expired entries
+// are removed.
+//
+// This operation has an expected time
+// complexity of O(1).
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	e, ok := c.m.Get(k)
+	if ok && c.expired(e) {
+		c.delete(e, Expired)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		return *new(V), false
+	}
+	c.stats.Hits++
+	c.p.hit(e)
+	return e.v, true
+}
+
+// Peek returns the value of the given key
+// without recording the access.
+//
+// This operation has an expected time
+// complexity of O(1).
+func (c *Cache[K, V]) Peek(k K) (V, bool) {
+	e, ok := c.m.Get(k)
+	if !ok || c.expired(e) {
+		return *new(V), false
+	}
+	return e.v, true
+}
+
+// Put sets the value of the given key and evicts
+// entries as needed. Entries which are larger than
+// the maximum total size are not cached, but passed
+// to OnEvict.
+//
+// This operation has an amortized expected time
+// complexity of O(1) per evicted entry.
+func (c *Cache[K, V]) Put(k K, v V) {
+	size := int64(1)
+	if c.c.Size != nil {
+		size = c.c.Size(k, v)
+	}
+	var exp time.Time
+	if c.c.TTL > 0 {
+		exp = c.now().Add(c.c.TTL)
+	}
+
+	if e, ok := c.m.Get(k); ok {
+		c.bytes += size - e.size
+		e.v, e.size, e.exp = v, size, exp
+		c.p.hit(e)
+		if c.c.MaxBytes > 0 && size > c.c.MaxBytes {
+			c.delete(e, Evicted)
+			return
+		}
+		c.evict(0, 0)
+		return
+	}
+
+	if c.c.MaxBytes > 0 && size > c.c.MaxBytes {
+		c.stats.Evictions++
+		if c.c.OnEvict != nil {
+			c.c.OnEvict(k, v, Evicted)
+		}
+		return
+	}
+	c.p.admit(k)
+	c.evict(1, size)
+	e := &entry[K, V]{k: k, v: v, size: size, exp: exp}
+	c.m.Put(k, e)
+	c.bytes += size
+	c.p.add(e)
+}
+
+// Remove removes the given key and returns its value.
+//
+// This operation has an amortized expected
+// time complexity of O(1).
+func (c *Cache[K, V]) Remove(k K) (V, bool) {
+	e, ok := c.m.Get(k)
+	if !ok {
+		return *new(V), false
+	}
+	c.delete(e, Removed)
+	return e.v, true
+}
+
+// RemoveExpired removes all expired entries
+// and returns their number.
+//
+// This operation has a time complexity of O(n).
+func (c *Cache[K, V]) RemoveExpired() int {
+	var es []*entry[K, V]
+	for _, e := range c.m.All() {
+		if c.expired(e) {
+			es = append(es, e)
+		}
+	}
+	for _, e := range es {
+		c.delete(e, Expired)
+	}
+	return len(es)
+}
+
+// expired reports whether the entry is expired.
+func (c *Cache[K, V]) expired(e *entry[K, V]) bool {
+	return !e.exp.IsZero() && !c.now().Before(e.exp)
+}
+
+// evict evicts entries until n more entries
+// with the given total size fit into the cache.
+// Victims which have expired count as expired.
+func (c *Cache[K, V]) evict(n int, size int64) {
+	for c.m.Len() > 0 &&
+		((c.c.Capacity > 0 && c.m.Len()+n > c.c.Capacity) ||
+			(c.c.MaxBytes > 0 && c.bytes+size > c.c.MaxBytes)) {
+		e := c.p.evict()
+		r := Evicted
+		if c.expired(e) {
+			r = Expired
+		}
+		c.drop(e, r)
+	}
+}
+
+// delete removes the entry for the given reason.
+func (c *Cache[K, V]) delete(e *entry[K, V], r Reason) {
+	c.p.remove(e)
+	c.drop(e, r)
+}
+
+// drop removes the entry, which is no longer
+// in the policy, for the given reason.
+func (c *Cache[K, V]) drop(e *entry[K, V], r Reason) {
+	c.m.Remove(e.k)
+	c.bytes -= e.size
+	switch r {
+	case Evicted:
+		c.stats.Evictions++
+	case Expired:
+		c.stats.Expirations++
+	}
+	if c.c.OnEvict != nil {
+		c.c.OnEvict(e.k, e.v, r)
+	}
+}
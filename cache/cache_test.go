@@ -0,0 +1,449 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// evictions records the keys passed to OnEvict.
+type evictions struct {
+	keys    []int
+	reasons []Reason
+}
+
+func (e *evictions) onEvict(k, _ int, r Reason) {
+	e.keys = append(e.keys, k)
+	e.reasons = append(e.reasons, r)
+}
+
+func TestLRU(t *testing.T) {
+	var ev evictions
+	c := New(Config[int, int]{Capacity: 3, OnEvict: ev.onEvict})
+	for k := 1; k <= 3; k++ {
+		c.Put(k, k)
+	}
+	c.Get(1)
+	c.Put(4, 4)
+	c.Peek(2)
+	c.Put(5, 5)
+	if want := []int{2, 3}; !slices.Equal(ev.keys, want) {
+		t.Errorf("want %v, got %v", want, ev.keys)
+	}
+	c.Put(1, 10)
+	c.Put(6, 6)
+	if v, ok := c.Get(1); !ok || v != 10 {
+		t.Errorf("want %d, got %d", 10, v)
+	}
+	if _, ok := c.Get(4); ok {
+		t.Errorf("4 should have been evicted")
+	}
+	if v, ok := c.Remove(6); !ok || v != 6 {
+		t.Errorf("want %d, got %d", 6, v)
+	}
+	if want := []Reason{Evicted, Evicted, Evicted, Removed}; !slices.Equal(ev.reasons, want) {
+		t.Errorf("want %v, got %v", want, ev.reasons)
+	}
+	if c.Len() != 2 {
+		t.Errorf("want %d, got %d", 2, c.Len())
+	}
+}
+
+func TestLFU(t *testing.T) {
+	var ev evictions
+	c := New(Config[int, int]{Policy: LFU, Capacity: 3, OnEvict: ev.onEvict})
+	for k := 1; k <= 3; k++ {
+		c.Put(k, k)
+	}
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Get(3)
+	c.Put(4, 4) // 1 has frequency 3, 2 and 3 have 2, 3 is the most recent
+	c.Put(5, 5) // 4 has frequency 1
+	c.Get(5)
+	c.Put(6, 6) // 3 and 5 have frequency 2, 5 is the most recent
+	if want := []int{2, 4, 3}; !slices.Equal(ev.keys, want) {
+		t.Errorf("want %v, got %v", want, ev.keys)
+	}
+}
+
+func TestARC(t *testing.T) {
+	const n = 100
+	for _, p := range []Policy{LRU, ARC} {
+		c := New(Config[int, int]{Policy: p, Capacity: n})
+		// the hot keys are accessed twice
+		for k := 0; k < n/2; k++ {
+			c.Put(k, k)
+			c.Get(k)
+		}
+		// a scan of keys accessed once
+		for k := n; k < 20*n; k++ {
+			c.Put(k, k)
+		}
+		hot := 0
+		for k := 0; k < n/2; k++ {
+			if _, ok := c.Peek(k); ok {
+				hot++
+			}
+		}
+		if p == ARC && hot != n/2 {
+			t.Errorf("ARC: want %d hot keys, got %d", n/2, hot)
+		}
+		if p == LRU && hot != 0 {
+			t.Errorf("LRU: want %d hot keys, got %d", 0, hot)
+		}
+	}
+
+	// hits in B1 increase the target size of T1
+	c := New(Config[int, int]{Policy: ARC, Capacity: 4})
+	for k := 0; k < 6; k++ {
+		c.Put(k, k)
+		if k < 2 {
+			c.Get(k)
+		}
+	}
+	a := c.p.(*arc[int, int])
+	if a.p != 0 || a.b1.Len() != 2 {
+		t.Fatalf("want p = 0 and 2 keys in B1, got %d and %d", a.p, a.b1.Len())
+	}
+	c.Put(2, 2)
+	if e := a.t2.Front().Value(); a.p != 1 || !e.t2 || e.k != 2 {
+		t.Errorf("want p = 1 and 2 in T2, got %d and %d", a.p, e.k)
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	var ev evictions
+	c := New(Config[int, string]{
+		MaxBytes: 10,
+		Size:     func(_ int, v string) int64 { return int64(len(v)) },
+		OnEvict:  func(k int, _ string, r Reason) { ev.onEvict(k, 0, r) },
+	})
+	c.Put(1, "abcd")
+	c.Put(2, "efgh")
+	c.Put(3, "ijkl")
+	if c.Len() != 2 || c.Bytes() != 8 {
+		t.Errorf("want 2 entries of 8 bytes, got %d of %d", c.Len(), c.Bytes())
+	}
+	c.Put(4, "too large for the cache")
+	if _, ok := c.Get(4); ok {
+		t.Errorf("4 should not have been cached")
+	}
+	c.Put(3, "ijklmnop")
+	if c.Len() != 1 || c.Bytes() != 8 {
+		t.Errorf("want 1 entry of 8 bytes, got %d of %d", c.Len(), c.Bytes())
+	}
+	if want := []int{1, 4, 2}; !slices.Equal(ev.keys, want) {
+		t.Errorf("want %v, got %v", want, ev.keys)
+	}
+	if s := c.Stats(); s.Evictions != 3 || s.Misses != 1 {
+		t.Errorf("want 3 evictions and 1 miss, got %d and %d", s.Evictions, s.Misses)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var ev evictions
+	c := New(Config[int, int]{TTL: time.Minute, OnEvict: ev.onEvict})
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	c.Put(1, 1)
+	c.Put(2, 2)
+	now = now.Add(30 * time.Second)
+	c.Put(3, 3)
+	c.Put(1, 1) // renews the entry
+	if _, ok := c.Get(2); !ok {
+		t.Errorf("2 should not have expired")
+	}
+	now = now.Add(30 * time.Second)
+	if _, ok := c.Peek(2); ok {
+		t.Errorf("2 should have expired")
+	}
+	if _, ok := c.Get(2); ok {
+		t.Errorf("2 should have expired")
+	}
+	now = now.Add(30 * time.Second)
+	if n := c.RemoveExpired(); n != 2 {
+		t.Errorf("want %d, got %d", 2, n)
+	}
+	if c.Len() != 0 {
+		t.Errorf("want %d, got %d", 0, c.Len())
+	}
+	s := c.Stats()
+	if s.Expirations != 3 || s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("want 3 expirations, 1 hit and 1 miss, got %+v", s)
+	}
+	if r := s.HitRate(); r != 0.5 {
+		t.Errorf("want %f, got %f", 0.5, r)
+	}
+}
+
+func TestEvictExpired(t *testing.T) {
+	var ev evictions
+	c := New(Config[int, int]{Capacity: 2, TTL: time.Minute, OnEvict: ev.onEvict})
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+	c.Put(1, 1)
+	now = now.Add(30 * time.Second)
+	c.Put(2, 2)
+	now = now.Add(30 * time.Second)
+	c.Put(3, 3) // evicts the expired 1
+	c.Put(4, 4) // evicts 2
+	if want := []Reason{Expired, Evicted}; !slices.Equal(ev.reasons, want) {
+		t.Errorf("want %v, got %v", want, ev.reasons)
+	}
+	if s := c.Stats(); s.Expirations != 1 || s.Evictions != 1 {
+		t.Errorf("want 1 expiration and 1 eviction, got %+v", s)
+	}
+}
+
+// checkCache checks that the entries of the cache
+// and of its policy are consistent and within the
+// limits of the configuration.
+func checkCache(t *testing.T, c *Cache[int, int]) {
+	t.Helper()
+	if c.c.Capacity > 0 && c.Len() > c.c.Capacity {
+		t.Fatalf("want at most %d entries, got %d", c.c.Capacity, c.Len())
+	}
+	var bytes int64
+	for _, e := range c.m.All() {
+		bytes += e.size
+	}
+	if bytes != c.bytes || (c.c.MaxBytes > 0 && bytes > c.c.MaxBytes) {
+		t.Fatalf("want %d bytes, got %d", bytes, c.bytes)
+	}
+	n := 0
+	switch p := c.p.(type) {
+	case *lru[int, int]:
+		n = p.l.Len()
+	case *lfu[int, int]:
+		f := 0
+		for b := p.b.Front(); b != nil; b = p.b.Next(b) {
+			if b.Value().f <= f || b.Value().l.Len() == 0 {
+				t.Fatalf("invalid bucket of frequency %d", b.Value().f)
+			}
+			f = b.Value().f
+			n += b.Value().l.Len()
+		}
+	case *arc[int, int]:
+		n = p.t1.Len() + p.t2.Len()
+		if p.t1.Len()+p.b1.Len() > p.c || n+p.b1.Len()+p.b2.Len() > 2*p.c {
+			t.Fatalf("too many keys: %d, %d, %d, %d", p.t1.Len(), p.t2.Len(), p.b1.Len(), p.b2.Len())
+		}
+		if p.g.Len() != p.b1.Len()+p.b2.Len() || p.p < 0 || p.p > p.c {
+			t.Fatalf("invalid ghosts or target size %d", p.p)
+		}
+	}
+	if n != c.Len() {
+		t.Fatalf("want %d entries in the policy, got %d", c.Len(), n)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, p := range []Policy{LRU, LFU, ARC} {
+		c := New(Config[int, int]{
+			Policy:   p,
+			Capacity: 50,
+			MaxBytes: 200,
+			Size:     func(k, _ int) int64 { return int64(k%7 + 1) },
+		})
+		z := rand.NewZipf(r, 1.2, 1, 500)
+		for i := 0; i < 20000; i++ {
+			k := int(z.Uint64())
+			switch r.Intn(4) {
+			case 0:
+				c.Put(k, i)
+			case 1:
+				c.Remove(k)
+			default:
+				c.Get(k)
+			}
+			checkCache(t, c)
+		}
+		if s := c.Stats(); s.HitRate() == 0 || s.Evictions == 0 {
+			t.Errorf("policy %d: unexpected stats %+v", p, s)
+		}
+	}
+}
+
+func TestSharded(t *testing.T) {
+	const workers, n = 4, 5000
+	s := NewSharded(8, Config[int, int]{Policy: ARC, Capacity: 100})
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < n; i++ {
+				k := r.Intn(200)
+				if v, ok := s.Get(k); ok && v != k {
+					t.Errorf("want %d, got %d", k, v)
+				}
+				s.Put(k, k)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if s.Len() > 100 {
+		t.Errorf("want at most %d entries, got %d", 100, s.Len())
+	}
+	st := s.Stats()
+	if st.Hits+st.Misses != workers*n {
+		t.Errorf("want %d lookups, got %d", workers*n, st.Hits+st.Misses)
+	}
+	if v, ok := s.Remove(199); ok && v != 199 {
+		t.Errorf("want %d, got %d", 199, v)
+	}
+}
+
+func TestShardedLimits(t *testing.T) {
+	for _, tc := range []struct {
+		n, capacity int
+		want        []int
+	}{
+		{4, 10, []int{3, 3, 2, 2}},
+		{4, 8, []int{2, 2, 2, 2}},
+		{4, 2, []int{1, 1, 1, 1}},
+		{4, 0, []int{0, 0, 0, 0}},
+	} {
+		s := NewSharded(tc.n, Config[int, int]{Capacity: tc.capacity, MaxBytes: int64(tc.capacity)})
+		for i := range s.s {
+			cfg := s.s[i].c.c
+			if c := cfg.Capacity; c != tc.want[i] {
+				t.Errorf("capacity %d, shard %d: want %d, got %d", tc.capacity, i, tc.want[i], c)
+			}
+			if b := cfg.MaxBytes; b != int64(tc.want[i]) {
+				t.Errorf("capacity %d, shard %d: want %d bytes, got %d", tc.capacity, i, tc.want[i], b)
+			}
+		}
+	}
+}
+
+// listLRU is an LRU cache on a map and container/list.
+type listLRU struct {
+	c int
+	m map[int]*list.Element
+	l *list.List
+}
+
+type listEntry struct{ k, v int }
+
+func newListLRU(c int) *listLRU {
+	return &listLRU{c: c, m: make(map[int]*list.Element), l: list.New()}
+}
+
+func (c *listLRU) Get(k int) (int, bool) {
+	e, ok := c.m[k]
+	if !ok {
+		return 0, false
+	}
+	c.l.MoveToFront(e)
+	return e.Value.(*listEntry).v, true
+}
+
+func (c *listLRU) Put(k, v int) {
+	if e, ok := c.m[k]; ok {
+		e.Value.(*listEntry).v = v
+		c.l.MoveToFront(e)
+		return
+	}
+	if c.l.Len() >= c.c {
+		e := c.l.Back()
+		c.l.Remove(e)
+		delete(c.m, e.Value.(*listEntry).k)
+	}
+	c.m[k] = c.l.PushFront(&listEntry{k, v})
+}
+
+type cache interface {
+	Get(k int) (int, bool)
+	Put(k, v int)
+}
+
+// zipfKeys returns keys in [0, 2^16)
+// with a Zipf distribution.
+func zipfKeys() []int {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.1, 1, 1<<16-1)
+	ks := make([]int, 1<<14)
+	for i := range ks {
+		ks[i] = int(z.Uint64())
+	}
+	return ks
+}
+
+func BenchmarkCache(b *testing.B) {
+	const capacity = 1 << 12
+	ks := zipfKeys()
+	caches := []struct {
+		name string
+		new  func() cache
+	}{
+		{"MapList", func() cache { return newListLRU(capacity) }},
+		{"LRU", func() cache { return New(Config[int, int]{Capacity: capacity}) }},
+		{"LFU", func() cache { return New(Config[int, int]{Policy: LFU, Capacity: capacity}) }},
+		{"ARC", func() cache { return New(Config[int, int]{Policy: ARC, Capacity: capacity}) }},
+	}
+	for _, c := range caches {
+		b.Run(c.name, func(b *testing.B) {
+			ca := c.new()
+			hits := 0
+			for i := 0; i < b.N; i++ {
+				k := ks[i%len(ks)]
+				if _, ok := ca.Get(k); ok {
+					hits++
+				} else {
+					ca.Put(k, k)
+				}
+			}
+			b.ReportMetric(float64(hits)/float64(b.N), "hits/op")
+		})
+	}
+}
+
+func BenchmarkSharded(b *testing.B) {
+	const capacity = 1 << 12
+	ks := zipfKeys()
+	var mu sync.Mutex
+	l := newListLRU(capacity)
+	caches := map[string]cache{
+		"MutexMapList": cacheFunc{
+			get: func(k int) (int, bool) { mu.Lock(); defer mu.Unlock(); return l.Get(k) },
+			put: func(k, v int) { mu.Lock(); defer mu.Unlock(); l.Put(k, v) },
+		},
+	}
+	for _, n := range []int{1, 16} {
+		caches[fmt.Sprintf("Sharded%d", n)] = NewSharded(n, Config[int, int]{Capacity: capacity})
+	}
+	for name, c := range caches {
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := rand.Intn(len(ks)); pb.Next(); i++ {
+					k := ks[i%len(ks)]
+					if _, ok := c.Get(k); !ok {
+						c.Put(k, k)
+					}
+				}
+			})
+		})
+	}
+}
+
+// cacheFunc implements cache by functions.
+type cacheFunc struct {
+	get func(k int) (int, bool)
+	put func(k, v int)
+}
+
+func (c cacheFunc) Get(k int) (int, bool) { return c.get(k) }
+func (c cacheFunc) Put(k, v int)          { c.put(k, v) }
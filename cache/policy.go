@@ -0,0 +1,193 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import "github.com/davidrjenni/lib/ds"
+
+// policy decides which entry to evict.
+// All operations take constant time.
+type policy[K comparable, V any] interface {
+	// admit is called before a new entry
+	// with the given key is made room for.
+	admit(k K)
+	// add adds a new entry.
+	add(e *entry[K, V])
+	// hit records an access of an entry.
+	hit(e *entry[K, V])
+	// remove removes an entry.
+	remove(e *entry[K, V])
+	// evict removes and returns the entry
+	// to evict. The policy is not empty.
+	evict() *entry[K, V]
+}
+
+// --- LRU -------
+
+// lru keeps the entries in order of their
+// last access, the most recent at the front.
+type lru[K comparable, V any] struct {
+	l ds.DList[*entry[K, V]]
+}
+
+func (p *lru[K, V]) admit(K)               {}
+func (p *lru[K, V]) add(e *entry[K, V])    { e.e = p.l.PushFront(e) }
+func (p *lru[K, V]) hit(e *entry[K, V])    { p.l.MoveToFront(e.e) }
+func (p *lru[K, V]) remove(e *entry[K, V]) { p.l.RemoveElement(e.e) }
+
+func (p *lru[K, V]) evict() *entry[K, V] {
+	e, _ := p.l.RemoveElement(p.l.Back())
+	return e
+}
+
+// --- LFU -------
+
+// bucket contains the entries with the same
+// frequency, the most recently used at the front.
+type bucket[K comparable, V any] struct {
+	f int                    // frequency
+	l ds.DList[*entry[K, V]] // entries
+}
+
+// lfu keeps the buckets in ascending order of their
+// frequency, as described in "An O(1) algorithm for
+// implementing the LFU cache eviction scheme" by
+// Shah, Mitra and Matani.
+type lfu[K comparable, V any] struct {
+	b ds.DList[*bucket[K, V]]
+}
+
+func (p *lfu[K, V]) admit(K) {}
+
+func (p *lfu[K, V]) add(e *entry[K, V]) {
+	b := p.b.Front()
+	if b == nil || b.Value().f != 1 {
+		b = p.b.PushFront(&bucket[K, V]{f: 1})
+	}
+	e.b, e.e = b, b.Value().l.PushFront(e)
+}
+
+func (p *lfu[K, V]) hit(e *entry[K, V]) {
+	b := e.b
+	n := p.b.Next(b)
+	if n == nil || n.Value().f != b.Value().f+1 {
+		n = p.b.InsertAfter(&bucket[K, V]{f: b.Value().f + 1}, b)
+	}
+	p.remove(e)
+	e.b, e.e = n, n.Value().l.PushFront(e)
+}
+
+func (p *lfu[K, V]) remove(e *entry[K, V]) {
+	l := &e.b.Value().l
+	l.RemoveElement(e.e)
+	if l.Len() == 0 {
+		p.b.RemoveElement(e.b)
+	}
+}
+
+func (p *lfu[K, V]) evict() *entry[K, V] {
+	e := p.b.Front().Value().l.Back().Value()
+	p.remove(e)
+	return e
+}
+
+// --- ARC -------
+
+// ghost is a recently evicted key.
+type ghost[K any] struct {
+	e  *ds.Element[K] // handle in B1 or B2
+	b2 bool           // key is in B2
+}
+
+// arc keeps the entries seen once recently in T1 and
+// those seen at least twice in T2. The keys recently
+// evicted from T1 and T2 are kept in B1 and B2. Hits
+// in B1 and B2 adapt the target size p of T1.
+type arc[K comparable, V any] struct {
+	c      int // capacity
+	p      int // target size of T1
+	t1, t2 ds.DList[*entry[K, V]]
+	b1, b2 ds.DList[K]
+	g      ds.LinearHashTable[K, ghost[K]]
+	toT2   bool // admitted key was a ghost
+	fromB2 bool // admitted key was in B2
+}
+
+func (p *arc[K, V]) admit(k K) {
+	g, ok := p.g.Get(k)
+	p.toT2, p.fromB2 = ok, ok && g.b2
+	if !ok {
+		return
+	}
+	b1, b2 := p.b1.Len(), p.b2.Len()
+	if g.b2 {
+		p.p = max(0, p.p-max(b1/b2, 1))
+		p.b2.RemoveElement(g.e)
+	} else {
+		p.p = min(p.c, p.p+max(b2/b1, 1))
+		p.b1.RemoveElement(g.e)
+	}
+	p.g.Remove(k)
+}
+
+func (p *arc[K, V]) add(e *entry[K, V]) {
+	if e.t2 = p.toT2; e.t2 {
+		e.e = p.t2.PushFront(e)
+	} else {
+		e.e = p.t1.PushFront(e)
+	}
+	p.toT2, p.fromB2 = false, false
+	p.trim()
+}
+
+func (p *arc[K, V]) hit(e *entry[K, V]) {
+	if e.t2 {
+		p.t2.MoveToFront(e.e)
+		return
+	}
+	p.t1.RemoveElement(e.e)
+	e.e, e.t2 = p.t2.PushFront(e), true
+}
+
+func (p *arc[K, V]) remove(e *entry[K, V]) {
+	if e.t2 {
+		p.t2.RemoveElement(e.e)
+	} else {
+		p.t1.RemoveElement(e.e)
+	}
+}
+
+func (p *arc[K, V]) evict() *entry[K, V] {
+	n := p.t1.Len()
+	if n > 0 && (n > p.p || (p.fromB2 && n == p.p) || p.t2.Len() == 0) {
+		e, _ := p.t1.RemoveElement(p.t1.Back())
+		p.g.Put(e.k, ghost[K]{e: p.b1.PushFront(e.k)})
+		p.trim()
+		return e
+	}
+	e, _ := p.t2.RemoveElement(p.t2.Back())
+	p.g.Put(e.k, ghost[K]{e: p.b2.PushFront(e.k), b2: true})
+	p.trim()
+	return e
+}
+
+// trim removes the least recently evicted keys,
+// such that T1 and B1 contain at most c keys and
+// all lists together at most 2c keys.
+func (p *arc[K, V]) trim() {
+	for p.t1.Len()+p.b1.Len() > p.c && p.b1.Len() > 0 {
+		k, _ := p.b1.RemoveElement(p.b1.Back())
+		p.g.Remove(k)
+	}
+	for p.t1.Len()+p.t2.Len()+p.b1.Len()+p.b2.Len() > 2*p.c {
+		b := &p.b2
+		if b.Len() == 0 {
+			if b = &p.b1; b.Len() == 0 {
+				return
+			}
+		}
+		k, _ := b.RemoveElement(b.Back())
+		p.g.Remove(k)
+	}
+}
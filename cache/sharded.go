@@ -0,0 +1,142 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"sync"
+
+	"github.com/davidrjenni/lib/ds"
+)
+
+// shard is a cache guarded by a mutex.
+type shard[K comparable, V any] struct {
+	mu sync.Mutex
+	c  *Cache[K, V]
+}
+
+// Sharded is a cache which is safe for concurrent use.
+// It partitions the keys by their hash among caches,
+// each guarded by its own mutex. The capacity and the
+// maximum total size are split evenly among the shards,
+// the first shards getting the remainder, and the policy
+// applies per shard. OnEvict is called while the shard
+// is locked.
+type Sharded[K comparable, V any] struct {
+	s    []shard[K, V]
+	hash ds.Hash[K]
+}
+
+// NewSharded returns an empty cache with n shards
+// and the given configuration. If the capacity or
+// the maximum total size is less than n, each shard
+// still gets a limit of 1, such that the cache may
+// exceed it. It panics under the same conditions
+// as New.
+func NewSharded[K comparable, V any](n int, c Config[K, V]) *Sharded[K, V] {
+	n = max(n, 1)
+	s := &Sharded[K, V]{s: make([]shard[K, V], n), hash: ds.HashCode[K]}
+	for i := range s.s {
+		d := c
+		d.Capacity = int(share(int64(c.Capacity), n, i))
+		d.MaxBytes = share(c.MaxBytes, n, i)
+		s.s[i].c = New(d)
+	}
+	return s
+}
+
+// share returns the part of the limit l of the
+// shard i of n. The first l mod n shards get one
+// more than the others. A positive limit gives
+// each shard at least 1.
+func share(l int64, n, i int) int64 {
+	if l <= 0 {
+		return l
+	}
+	p := l / int64(n)
+	if int64(i) < l%int64(n) {
+		p++
+	}
+	return max(p, 1)
+}
+
+// shard returns the shard of the given key.
+func (s *Sharded[K, V]) shard(k K) *shard[K, V] {
+	return &s.s[s.hash(k)%uint64(len(s.s))]
+}
+
+// Get returns the value of the given key
+// and records the access.
+func (s *Sharded[K, V]) Get(k K) (V, bool) {
+	sh := s.shard(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.c.Get(k)
+}
+
+// Peek returns the value of the given key
+// without recording the access.
+func (s *Sharded[K, V]) Peek(k K) (V, bool) {
+	sh := s.shard(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.c.Peek(k)
+}
+
+// Put sets the value of the given key
+// and evicts entries as needed.
+func (s *Sharded[K, V]) Put(k K, v V) {
+	sh := s.shard(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.c.Put(k, v)
+}
+
+// Remove removes the given key and returns its value.
+func (s *Sharded[K, V]) Remove(k K) (V, bool) {
+	sh := s.shard(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.c.Remove(k)
+}
+
+// RemoveExpired removes all expired
+// entries and returns their number.
+func (s *Sharded[K, V]) RemoveExpired() int {
+	n := 0
+	s.each(func(c *Cache[K, V]) { n += c.RemoveExpired() })
+	return n
+}
+
+// Len returns the number of entries.
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	s.each(func(c *Cache[K, V]) { n += c.Len() })
+	return n
+}
+
+// Bytes returns the total size of the entries.
+func (s *Sharded[K, V]) Bytes() int64 {
+	var n int64
+	s.each(func(c *Cache[K, V]) { n += c.Bytes() })
+	return n
+}
+
+// Stats returns the statistics
+// summed over all shards.
+func (s *Sharded[K, V]) Stats() Stats {
+	var st Stats
+	s.each(func(c *Cache[K, V]) { st.add(c.Stats()) })
+	return st
+}
+
+// each calls f for each shard, one at a time.
+func (s *Sharded[K, V]) each(f func(c *Cache[K, V])) {
+	for i := range s.s {
+		sh := &s.s[i]
+		sh.mu.Lock()
+		f(sh.c)
+		sh.mu.Unlock()
+	}
+}
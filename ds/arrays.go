@@ -4,53 +4,122 @@
 
 package ds
 
-import "math"
+import (
+	"context"
+	"errors"
+	"iter"
+	"math"
+	"slices"
+	"sync"
+)
 
-// --- Stack -------
+// --- ArrayStack -------
 
-// Stack implements a stack
+// ArrayStack implements a stack
 // on top of a dynamic array.
-type Stack struct {
-	a Array // backing dynamic array
+type ArrayStack[T any] struct {
+	a Array[T] // backing dynamic array
 }
 
 // Push pushes a value onto the stack.
 //
 // This operation has an amortized time
 // complexity of O(1).
-func (s *Stack) Push(v V) { s.a.Add(s.Len(), v) }
+func (s *ArrayStack[T]) Push(v T) { s.a.Add(s.Len(), v) }
 
 // Pop removes and returns a value from
 // the top of the stack.
 //
 // This operation has an amortized time
 // complexity of O(1).
-func (s *Stack) Pop() (V, bool) { return s.a.Remove(s.Len() - 1) }
+func (s *ArrayStack[T]) Pop() (T, bool) { return s.a.Remove(s.Len() - 1) }
 
 // Len returns the number of
 // elements on the stack.
-func (s *Stack) Len() int { return s.a.Len() }
+func (s *ArrayStack[T]) Len() int { return s.a.Len() }
+
+// All returns an iterator over the indices and
+// elements of the stack, from the
+// bottom to the top.
+func (s *ArrayStack[T]) All() iter.Seq2[int, T] { return s.a.All() }
+
+// Backward returns an iterator over the indices and
+// elements of the stack, from the
+// top to the bottom.
+func (s *ArrayStack[T]) Backward() iter.Seq2[int, T] { return s.a.Backward() }
+
+// --- Growth -------
+
+// Growth is the policy by which Array,
+// ArrayQueue and Dequeue resize their
+// backing slices. The zero value doubles
+// the capacity when the backing slice is
+// full and shrinks it to twice the number
+// of elements when it holds less than a
+// third, as in the book.
+type Growth struct {
+	// Factor is the ratio of the new capacity
+	// to the number of elements; it defaults
+	// to 2 if it is not greater than 1. The
+	// backing slice shrinks when it holds less
+	// than 1/(Factor+1) elements per slot.
+	Factor float64
+	// Min is the minimum capacity, which is
+	// allocated when the policy is set.
+	Min int
+	// NoShrink disables shrinking.
+	NoShrink bool
+}
+
+// size returns the length of a new backing
+// slice for n elements, which is at least n+1.
+func (g Growth) size(n int) int {
+	f := g.Factor
+	if f <= 1 {
+		f = 2
+	}
+	return max(max(int(f*float64(n)), n+1), g.Min)
+}
+
+// shrink reports whether a backing slice of
+// length m holding n elements should shrink.
+func (g Growth) shrink(m, n int) bool {
+	f := g.Factor
+	if f <= 1 {
+		f = 2
+	}
+	return !g.NoShrink && float64(m) >= (f+1)*float64(n) && g.size(n) < m
+}
 
 // --- Dynamic Array -------
 
 // Array implements a dynamic array, which
 // grows and shrinks as needed.
-type Array struct {
-	s []V // backing slice
-	n int // number of elements
+type Array[T any] struct {
+	s []T    // backing slice
+	n int    // number of elements
+	g Growth // resize policy
 }
 
 // Len returns the number
 // of elements in the array.
-func (a *Array) Len() int { return a.n }
+func (a *Array[T]) Len() int { return a.n }
+
+// All returns an iterator over the indices and
+// elements of the array, in order.
+func (a *Array[T]) All() iter.Seq2[int, T] { return forward[T](a) }
+
+// Backward returns an iterator over the indices and
+// elements of the array, in reverse order.
+func (a *Array[T]) Backward() iter.Seq2[int, T] { return backward[T](a) }
 
 // Get returns the element at the
 // given index.
 //
 // This operation has a time complexity of O(1).
-func (a *Array) Get(i int) (V, bool) {
+func (a *Array[T]) Get(i int) (T, bool) {
 	if i < 0 || i > a.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	return a.s[i], true
 }
@@ -59,9 +128,9 @@ func (a *Array) Get(i int) (V, bool) {
 // index and returns the old one.
 //
 // This operation has a time complexity of O(1).
-func (a *Array) Set(i int, v V) (V, bool) {
+func (a *Array[T]) Set(i int, v T) (T, bool) {
 	if i < 0 || i > a.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	t := a.s[i]
 	a.s[i] = v
@@ -75,47 +144,95 @@ func (a *Array) Set(i int, v V) (V, bool) {
 //
 // This operation has an amortized time
 // complexity of O(n-i).
-func (a *Array) Add(i int, v V) bool {
+func (a *Array[T]) Add(i int, v T) bool {
 	if i < 0 || i > a.n {
 		return false
 	}
 	if a.n+1 > len(a.s) {
 		a.resize()
 	}
-	copy(a.s[i:], a.s[i+1:])
+	copy(a.s[i+1:], a.s[i:a.n])
 	a.s[i] = v
 	a.n++
 	return true
 }
 
-func (a *Array) addAll(o Array) {
-	n := o.n + a.n
-	if n > len(a.s) {
-		s := make([]V, n)
-		copy(s, a.s)
+// AddAll adds the given elements to the array
+// at the given index and reports whether it was
+// successful or not. The array is resized at
+// most once.
+//
+// This operation has an amortized time
+// complexity of O(n-i+k), where k is the
+// number of added elements.
+func (a *Array[T]) AddAll(i int, vs ...T) bool {
+	if i < 0 || i > a.n {
+		return false
+	}
+	k := len(vs)
+	if a.n+k > len(a.s) {
+		s := make([]T, a.g.size(a.n+k))
+		copy(s, a.s[:i])
+		copy(s[i+k:], a.s[i:a.n])
 		a.s = s
+	} else {
+		copy(a.s[i+k:], a.s[i:a.n])
 	}
-	copy(a.s[a.n:], o.s)
-	a.n = n
+	copy(a.s[i:], vs)
+	a.n += k
+	return true
 }
 
-func (a *Array) reverse() {
-	for i, j := 0, a.n-1; i < j; i, j = i+1, j-1 {
-		a.s[i], a.s[j] = a.s[j], a.s[i]
+// RemoveRange removes the elements of the
+// array at the indices [f, t) and reports
+// whether the operation was successful or
+// not. The array is resized at most once.
+//
+// This operation has an amortized time
+// complexity of O(n-f).
+func (a *Array[T]) RemoveRange(f, t int) bool {
+	if f < 0 || t > a.n || f > t {
+		return false
 	}
+	copy(a.s[f:], a.s[t:a.n])
+	clear(a.s[a.n-(t-f) : a.n])
+	a.n -= t - f
+	if a.g.shrink(len(a.s), a.n) {
+		a.resize()
+	}
+	return true
 }
 
-func (a *Array) sub(f, t int) Array {
-	var o Array
-	/*
-		if f < 0 || t > a.n || f > t {
-			panic()
-		}
-	*/
-	o.n = t - f
-	o.s = make([]V, o.n)
-	copy(o.s, a.s[f:t])
-	return o
+// Slice returns a copy of the elements
+// of the array at the indices [f, t).
+//
+// This operation has a time complexity of O(t-f).
+func (a *Array[T]) Slice(f, t int) ([]T, bool) {
+	if f < 0 || t > a.n || f > t {
+		return nil, false
+	}
+	return slices.Clone(a.s[f:t:t]), true
+}
+
+// Reverse reverses the order of
+// the elements of the array.
+//
+// This operation has a time complexity of O(n).
+func (a *Array[T]) Reverse() { slices.Reverse(a.s[:a.n]) }
+
+// Clear removes all elements of the array.
+// The backing slice is kept if shrinking is
+// disabled, otherwise it is released.
+//
+// This operation has a time complexity of O(n)
+// if shrinking is disabled, otherwise of O(1).
+func (a *Array[T]) Clear() {
+	if a.g.NoShrink {
+		clear(a.s[:a.n])
+		a.n = 0
+		return
+	}
+	*a = Array[T]{g: a.g}
 }
 
 // Remove removes the element of the array
@@ -125,45 +242,93 @@ func (a *Array) sub(f, t int) Array {
 //
 // This operation has an amortized time
 // complexity of O(n-i).
-func (a *Array) Remove(i int) (V, bool) {
+func (a *Array[T]) Remove(i int) (T, bool) {
 	if i < 0 || i > a.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	v := a.s[i]
-	copy(a.s[i:], a.s[i+1:])
+	copy(a.s[i:], a.s[i+1:a.n])
 	a.n--
-	if len(a.s) >= 3*a.n {
+	if a.g.shrink(len(a.s), a.n) {
 		a.resize()
 	}
 	return v, true
 }
 
-func (a *Array) resize() {
-	s := make([]V, max(a.n*2, 1))
-	copy(s, a.s)
+// Cap returns the capacity of the array.
+func (a *Array[T]) Cap() int { return len(a.s) }
+
+// SetGrowth sets the resize policy of the
+// array and reserves its minimum capacity.
+//
+// This operation has a time complexity of O(n).
+func (a *Array[T]) SetGrowth(g Growth) {
+	a.g = g
+	a.Reserve(g.Min)
+}
+
+// Reserve resizes the array such that it
+// holds at least n elements without being
+// resized again.
+//
+// This operation has a time complexity of O(n).
+func (a *Array[T]) Reserve(n int) {
+	if n > len(a.s) {
+		a.resizeTo(n)
+	}
+}
+
+// ShrinkToFit resizes the array to its number
+// of elements or the minimum capacity of its
+// resize policy, whichever is larger.
+//
+// This operation has a time complexity of O(n).
+func (a *Array[T]) ShrinkToFit() {
+	if m := max(a.n, a.g.Min); m < len(a.s) {
+		a.resizeTo(m)
+	}
+}
+
+func (a *Array[T]) resize() { a.resizeTo(a.g.size(a.n)) }
+
+// resizeTo resizes the backing slice to length m.
+func (a *Array[T]) resizeTo(m int) {
+	s := make([]T, m)
+	copy(s, a.s[:a.n])
 	a.s = s
 }
 
-// --- Queue -------
+// --- ArrayQueue -------
 
-// Queue implements a queue
+// ArrayQueue implements a queue
 // on top of a slice.
-type Queue struct {
-	s []V // backing slice
-	r int // read offset
-	n int // number of elements
+type ArrayQueue[T any] struct {
+	s []T    // backing slice
+	r int    // read offset
+	n int    // number of elements
+	g Growth // resize policy
 }
 
 // Len returns the number
 // of elements in the queue.
-func (q *Queue) Len() int { return q.n }
+func (q *ArrayQueue[T]) Len() int { return q.n }
+
+// All returns an iterator over the indices and
+// elements of the queue, from the
+// head to the tail.
+func (q *ArrayQueue[T]) All() iter.Seq2[int, T] { return q.seq(0, q.n, 1) }
+
+// Backward returns an iterator over the indices and
+// elements of the queue, from the
+// tail to the head.
+func (q *ArrayQueue[T]) Backward() iter.Seq2[int, T] { return q.seq(q.n-1, -1, -1) }
 
 // Enqueue adds an element
 // to the head of the queue.
 //
 // This operation has an amortized time
 // complexity of O(1).
-func (q *Queue) Enqueue(v V) {
+func (q *ArrayQueue[T]) Enqueue(v T) {
 	if q.n+1 > len(q.s) {
 		q.resize()
 	}
@@ -176,21 +341,68 @@ func (q *Queue) Enqueue(v V) {
 //
 // This operation has an amortized time
 // complexity of O(1).
-func (q *Queue) Dequeue() (V, bool) {
+func (q *ArrayQueue[T]) Dequeue() (T, bool) {
 	if q.n == 0 {
-		return nil, false
+		return *new(T), false
 	}
 	v := q.s[q.r]
 	q.r = (q.r + 1) % len(q.s)
 	q.n--
-	if len(q.s) >= 3*q.n {
+	if q.g.shrink(len(q.s), q.n) {
 		q.resize()
 	}
 	return v, true
 }
 
-func (q *Queue) resize() {
-	s := make([]V, max(q.n*2, 1))
+func (q *ArrayQueue[T]) seq(f, t, d int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := f; i != t && i < q.n; i += d {
+			if !yield(i, q.s[(q.r+i)%len(q.s)]) {
+				return
+			}
+		}
+	}
+}
+
+// Cap returns the capacity of the queue.
+func (q *ArrayQueue[T]) Cap() int { return len(q.s) }
+
+// SetGrowth sets the resize policy of the
+// queue and reserves its minimum capacity.
+//
+// This operation has a time complexity of O(n).
+func (q *ArrayQueue[T]) SetGrowth(g Growth) {
+	q.g = g
+	q.Reserve(g.Min)
+}
+
+// Reserve resizes the queue such that it
+// holds at least n elements without being
+// resized again.
+//
+// This operation has a time complexity of O(n).
+func (q *ArrayQueue[T]) Reserve(n int) {
+	if n > len(q.s) {
+		q.resizeTo(n)
+	}
+}
+
+// ShrinkToFit resizes the queue to its number
+// of elements or the minimum capacity of its
+// resize policy, whichever is larger.
+//
+// This operation has a time complexity of O(n).
+func (q *ArrayQueue[T]) ShrinkToFit() {
+	if m := max(q.n, q.g.Min); m < len(q.s) {
+		q.resizeTo(m)
+	}
+}
+
+func (q *ArrayQueue[T]) resize() { q.resizeTo(q.g.size(q.n)) }
+
+// resizeTo resizes the backing slice to length m.
+func (q *ArrayQueue[T]) resizeTo(m int) {
+	s := make([]T, m)
 	for i := 0; i < q.n; i++ {
 		s[i] = q.s[(q.r+i)%len(q.s)]
 	}
@@ -198,28 +410,243 @@ func (q *Queue) resize() {
 	q.r = 0
 }
 
+// --- RingBuffer -------
+
+// Overflow is the policy of a RingBuffer
+// for adding an element while it is full.
+type Overflow int
+
+const (
+	// OverflowReject rejects the element.
+	OverflowReject Overflow = iota
+	// OverflowBlock waits until an
+	// element is removed.
+	OverflowBlock
+	// OverflowOverwrite replaces
+	// the oldest element.
+	OverflowOverwrite
+)
+
+// ErrFull is returned by PushContext if a
+// full RingBuffer rejects the element.
+var ErrFull = errors.New("ds: ring buffer full")
+
+// RingBuffer implements a queue with a fixed
+// capacity on top of a slice, which is never
+// resized. It is safe for concurrent use and
+// Push and Pop do not allocate, unless Push
+// blocks. The zero value is an empty ring
+// buffer with a capacity of 16, which rejects
+// elements while it is full.
+type RingBuffer[T any] struct {
+	mu     sync.Mutex
+	s      []T           // backing slice
+	r      int           // read offset
+	n      int           // number of elements
+	p      Overflow      // overflow policy
+	closed bool          // whether Close was called
+	ch     chan struct{} // closed when an element is removed
+}
+
+// NewRingBuffer returns an empty ring buffer with
+// the given capacity, which is at least 1, and
+// the given overflow policy.
+func NewRingBuffer[T any](c int, p Overflow) *RingBuffer[T] {
+	return &RingBuffer[T]{s: make([]T, max(c, 1)), p: p}
+}
+
+// init lazily initializes the backing slice.
+func (b *RingBuffer[T]) init() {
+	if b.s == nil {
+		b.s = make([]T, 16)
+	}
+}
+
+// Len returns the number of
+// elements in the ring buffer.
+func (b *RingBuffer[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.n
+}
+
+// Cap returns the capacity of the ring buffer.
+func (b *RingBuffer[T]) Cap() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.init()
+	return len(b.s)
+}
+
+// Push adds an element to the tail of the
+// ring buffer and reports whether it was
+// successful. If the ring buffer is full,
+// the overflow policy decides whether the
+// element is rejected, the call blocks or
+// the oldest element is overwritten. Push
+// fails once the ring buffer is closed.
+//
+// This operation has a time complexity of O(1).
+func (b *RingBuffer[T]) Push(v T) bool {
+	return b.PushContext(context.Background(), v) == nil
+}
+
+// PushContext is like Push, but returns an error
+// if it fails: ErrFull if the element is rejected,
+// ErrClosed if the ring buffer is closed, or the
+// error of the context if it is done while the
+// call blocks.
+//
+// This operation has a time complexity of O(1).
+func (b *RingBuffer[T]) PushContext(ctx context.Context, v T) error {
+	for {
+		b.mu.Lock()
+		b.init()
+		if b.closed {
+			b.mu.Unlock()
+			return ErrClosed
+		}
+		if b.n < len(b.s) {
+			b.s[(b.r+b.n)%len(b.s)] = v
+			b.n++
+			b.mu.Unlock()
+			return nil
+		}
+		switch b.p {
+		case OverflowOverwrite:
+			b.s[b.r] = v
+			b.r = (b.r + 1) % len(b.s)
+			b.mu.Unlock()
+			return nil
+		case OverflowReject:
+			b.mu.Unlock()
+			return ErrFull
+		}
+		if b.ch == nil {
+			b.ch = make(chan struct{})
+		}
+		ch := b.ch
+		b.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close closes the ring buffer and wakes all
+// blocked pushes. Subsequent pushes fail, while
+// Pop returns the remaining elements. Closing a
+// closed ring buffer has no effect.
+func (b *RingBuffer[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.notify()
+}
+
+// notify wakes all blocked pushes.
+// It must be called with the lock held.
+func (b *RingBuffer[T]) notify() {
+	if b.ch != nil {
+		close(b.ch)
+		b.ch = nil
+	}
+}
+
+// Pop removes and returns the element
+// at the head of the ring buffer.
+//
+// This operation has a time complexity of O(1).
+func (b *RingBuffer[T]) Pop() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.n == 0 {
+		return *new(T), false
+	}
+	v := b.s[b.r]
+	b.s[b.r] = *new(T)
+	b.r = (b.r + 1) % len(b.s)
+	b.n--
+	b.notify()
+	return v, true
+}
+
+// Peek returns the element at the head
+// of the ring buffer without removing it.
+//
+// This operation has a time complexity of O(1).
+func (b *RingBuffer[T]) Peek() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.n == 0 {
+		return *new(T), false
+	}
+	return b.s[b.r], true
+}
+
+// Get returns the element at the given index,
+// counted from the head of the ring buffer.
+//
+// This operation has a time complexity of O(1).
+func (b *RingBuffer[T]) Get(i int) (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i < 0 || i >= b.n {
+		return *new(T), false
+	}
+	return b.s[(b.r+i)%len(b.s)], true
+}
+
+// All returns an iterator over the indices and
+// elements of the ring buffer, from the head
+// to the tail. The ring buffer is locked during
+// the iteration and must not be used by the
+// loop body.
+func (b *RingBuffer[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i := 0; i < b.n; i++ {
+			if !yield(i, b.s[(b.r+i)%len(b.s)]) {
+				return
+			}
+		}
+	}
+}
+
 // --- Dequeue -------
 
 // Dequeue is a queue which allows
 // for efficient addition and removal
 // at both ends of the queue.
-type Dequeue struct {
-	s []V // backing slice
-	r int // read offset
-	n int // number of elements
+type Dequeue[T any] struct {
+	s []T    // backing slice
+	r int    // read offset
+	n int    // number of elements
+	g Growth // resize policy
 }
 
 // Len returns the number
 // of elements in the dequeue.
-func (d *Dequeue) Len() int { return d.n }
+func (d *Dequeue[T]) Len() int { return d.n }
+
+// All returns an iterator over the indices and
+// elements of the dequeue, in order.
+func (d *Dequeue[T]) All() iter.Seq2[int, T] { return forward[T](d) }
+
+// Backward returns an iterator over the indices and
+// elements of the dequeue, in reverse order.
+func (d *Dequeue[T]) Backward() iter.Seq2[int, T] { return backward[T](d) }
 
 // Get returns the element at the
 // given index.
 //
 // This operation has a time complexity of O(1).
-func (d *Dequeue) Get(i int) (V, bool) {
+func (d *Dequeue[T]) Get(i int) (T, bool) {
 	if i < 0 || i > d.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	return d.s[(d.r+i)%len(d.s)], true
 }
@@ -228,9 +655,9 @@ func (d *Dequeue) Get(i int) (V, bool) {
 // index and returns the old one.
 //
 // This operation has a time complexity of O(1).
-func (d *Dequeue) Set(i int, v V) (V, bool) {
+func (d *Dequeue[T]) Set(i int, v T) (T, bool) {
 	if i < 0 || i > d.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	t := d.s[(d.r+i)%len(d.s)]
 	d.s[(d.r+i)%len(d.s)] = v
@@ -244,7 +671,7 @@ func (d *Dequeue) Set(i int, v V) (V, bool) {
 //
 // This operation has an amortized time
 // complexity of O(min{i, n-i}).
-func (d *Dequeue) Add(i int, v V) bool {
+func (d *Dequeue[T]) Add(i int, v T) bool {
 	if i < 0 || i > d.n {
 		return false
 	}
@@ -253,19 +680,11 @@ func (d *Dequeue) Add(i int, v V) bool {
 	}
 	if i < d.n/2 {
 		// shift left one position
-		if d.r == 0 {
-			d.r = len(d.s) - 1
-		} else {
-			d.r--
-		}
-		for j := 0; j <= i-1; j++ {
-			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j+1)%len(d.s)]
-		}
+		d.r = (d.r + len(d.s) - 1) % len(d.s)
+		d.move(0, 1, i)
 	} else {
 		// shift right one position
-		for j := d.n; j > i; j-- {
-			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j-1)%len(d.s)]
-		}
+		d.move(i+1, i, d.n-i)
 	}
 	d.s[(d.r+i)%len(d.s)] = v
 	d.n++
@@ -279,35 +698,237 @@ func (d *Dequeue) Add(i int, v V) bool {
 //
 // This operation has an amortized time
 // complexity of O(min{i, n-i}).
-func (d *Dequeue) Remove(i int) (V, bool) {
+func (d *Dequeue[T]) Remove(i int) (T, bool) {
 	if i < 0 || i > d.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	t := d.s[(d.r+i)%len(d.s)]
 	if i < d.n/2 {
 		// shift right one position
-		for j := i; j > 0; j-- {
-			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j-1)%len(d.s)]
-		}
+		d.move(1, 0, i)
+		d.s[d.r] = *new(T)
 		d.r = (d.r + 1) % len(d.s)
 	} else {
 		// shift left one position
-		for j := i; j < d.n-1; j++ {
-			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j+1)%len(d.s)]
-		}
+		d.move(i, i+1, d.n-i-1)
+		d.s[(d.r+d.n-1)%len(d.s)] = *new(T)
 	}
 	d.n--
-	if 3*d.n < len(d.s) {
+	if d.g.shrink(len(d.s), d.n) {
 		d.resize()
 	}
 	return t, true
 }
 
-func (d *Dequeue) resize() {
-	s := make([]V, max(d.n*2, 1))
-	for i := 0; i < d.n; i++ {
-		s[i] = d.s[(d.r+i)%len(d.s)]
+// move moves the k elements at the indices [f, f+k)
+// to [t, t+k), which may overlap, by copying the
+// contiguous segments of the backing slice.
+func (d *Dequeue[T]) move(t, f, k int) {
+	m := len(d.s)
+	if t < f {
+		for k > 0 {
+			x, y := (d.r+f)%m, (d.r+t)%m
+			c := copy(d.s[y:min(y+k, m)], d.s[x:min(x+k, m)])
+			f, t, k = f+c, t+c, k-c
+		}
+		return
+	}
+	// copy backwards, starting with the last segment
+	for k > 0 {
+		x, y := (d.r+f+k-1)%m+1, (d.r+t+k-1)%m+1
+		c := min(k, x, y)
+		copy(d.s[y-c:y], d.s[x-c:x])
+		k -= c
+	}
+}
+
+// AddAll adds the given elements to the
+// dequeue at the given index and reports
+// whether it was successful or not. The
+// dequeue is resized at most once.
+//
+// This operation has an amortized time
+// complexity of O(min{i, n-i}+k), where
+// k is the number of added elements.
+func (d *Dequeue[T]) AddAll(i int, vs ...T) bool {
+	if i < 0 || i > d.n {
+		return false
+	}
+	k := len(vs)
+	if k == 0 {
+		return true
+	}
+	if d.n+k > len(d.s) {
+		d.resizeTo(d.g.size(d.n + k))
+	}
+	if i < d.n/2 {
+		// shift left k positions
+		d.r = (d.r + len(d.s) - k) % len(d.s)
+		d.move(0, k, i)
+	} else {
+		// shift right k positions
+		d.move(i+k, i, d.n-i)
+	}
+	x := (d.r + i) % len(d.s)
+	c := copy(d.s[x:], vs)
+	copy(d.s, vs[c:])
+	d.n += k
+	return true
+}
+
+// RemoveRange removes the elements of the
+// dequeue at the indices [f, t) and reports
+// whether the operation was successful or
+// not. The dequeue is resized at most once.
+//
+// This operation has an amortized time
+// complexity of O(min{f, n-t}+t-f).
+func (d *Dequeue[T]) RemoveRange(f, t int) bool {
+	if f < 0 || t > d.n || f > t {
+		return false
+	}
+	k := t - f
+	if f < d.n-t {
+		// shift right k positions
+		d.move(k, 0, f)
+		d.zero(0, k)
+		d.r = (d.r + k) % max(len(d.s), 1)
+	} else {
+		// shift left k positions
+		d.move(f, t, d.n-t)
+		d.zero(d.n-k, k)
+	}
+	d.n -= k
+	if d.g.shrink(len(d.s), d.n) {
+		d.resize()
+	}
+	return true
+}
+
+// zero clears the k elements at the indices [f, f+k).
+func (d *Dequeue[T]) zero(f, k int) {
+	if k == 0 {
+		return
+	}
+	x := (d.r + f) % len(d.s)
+	c := min(k, len(d.s)-x)
+	clear(d.s[x : x+c])
+	clear(d.s[:k-c])
+}
+
+// Slice returns a copy of the elements
+// of the dequeue at the indices [f, t).
+//
+// This operation has a time complexity of O(t-f).
+func (d *Dequeue[T]) Slice(f, t int) ([]T, bool) {
+	if f < 0 || t > d.n || f > t {
+		return nil, false
+	}
+	s := make([]T, t-f)
+	if len(s) > 0 {
+		x := (d.r + f) % len(d.s)
+		c := copy(s, d.s[x:])
+		copy(s[c:], d.s)
+	}
+	return s, true
+}
+
+// Reverse reverses the order of
+// the elements of the dequeue.
+//
+// This operation has a time complexity of O(n).
+func (d *Dequeue[T]) Reverse() {
+	m := len(d.s)
+	for i, j := 0, d.n-1; i < j; i, j = i+1, j-1 {
+		x, y := (d.r+i)%m, (d.r+j)%m
+		d.s[x], d.s[y] = d.s[y], d.s[x]
+	}
+}
+
+// Clear removes all elements of the dequeue.
+// The backing slice is kept if shrinking is
+// disabled, otherwise it is released.
+//
+// This operation has a time complexity of O(n)
+// if shrinking is disabled, otherwise of O(1).
+func (d *Dequeue[T]) Clear() {
+	if d.g.NoShrink {
+		clear(d.s)
+		d.r, d.n = 0, 0
+		return
+	}
+	*d = Dequeue[T]{g: d.g}
+}
+
+// AddFirst adds an element to the
+// front of the dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *Dequeue[T]) AddFirst(v T) { d.Add(0, v) }
+
+// AddLast adds an element to the
+// back of the dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *Dequeue[T]) AddLast(v T) { d.Add(d.n, v) }
+
+// RemoveFirst removes and returns the
+// element at the front of the dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *Dequeue[T]) RemoveFirst() (T, bool) { return d.Remove(0) }
+
+// RemoveLast removes and returns the
+// element at the back of the dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *Dequeue[T]) RemoveLast() (T, bool) { return d.Remove(d.n - 1) }
+
+// Cap returns the capacity of the dequeue.
+func (d *Dequeue[T]) Cap() int { return len(d.s) }
+
+// SetGrowth sets the resize policy of the
+// dequeue and reserves its minimum capacity.
+//
+// This operation has a time complexity of O(n).
+func (d *Dequeue[T]) SetGrowth(g Growth) {
+	d.g = g
+	d.Reserve(g.Min)
+}
+
+// Reserve resizes the dequeue such that it
+// holds at least n elements without being
+// resized again.
+//
+// This operation has a time complexity of O(n).
+func (d *Dequeue[T]) Reserve(n int) {
+	if n > len(d.s) {
+		d.resizeTo(n)
 	}
+}
+
+// ShrinkToFit resizes the dequeue to its number
+// of elements or the minimum capacity of its
+// resize policy, whichever is larger.
+//
+// This operation has a time complexity of O(n).
+func (d *Dequeue[T]) ShrinkToFit() {
+	if m := max(d.n, d.g.Min); m < len(d.s) {
+		d.resizeTo(m)
+	}
+}
+
+func (d *Dequeue[T]) resize() { d.resizeTo(d.g.size(d.n)) }
+
+// resizeTo resizes the backing slice to length m.
+func (d *Dequeue[T]) resizeTo(m int) {
+	s := make([]T, m)
+	c := copy(s[:d.n], d.s[d.r:])
+	copy(s[c:d.n], d.s)
 	d.s = s
 	d.r = 0
 }
@@ -317,15 +938,15 @@ func (d *Dequeue) resize() {
 // DualDequeue implements
 // a dequeue by combining
 // two dynamic arrays.
-type DualDequeue struct {
-	f, b Array // backing arrays
+type DualDequeue[T any] struct {
+	f, b Array[T] // backing arrays
 }
 
 // Get returns the element at the
 // given index.
 //
 // This operation has a time complexity of O(1).
-func (d *DualDequeue) Get(i int) (V, bool) {
+func (d *DualDequeue[T]) Get(i int) (T, bool) {
 	l := d.f.Len()
 	if i < l {
 		return d.f.Get(l - i - 1)
@@ -337,7 +958,7 @@ func (d *DualDequeue) Get(i int) (V, bool) {
 // index and returns the old one.
 //
 // This operation has a time complexity of O(1).
-func (d *DualDequeue) Set(i int, v V) (V, bool) {
+func (d *DualDequeue[T]) Set(i int, v T) (T, bool) {
 	l := d.f.Len()
 	if i < l {
 		return d.f.Set(l-i-1, v)
@@ -352,7 +973,10 @@ func (d *DualDequeue) Set(i int, v V) (V, bool) {
 //
 // This operation has an amortized time
 // complexity of O(min{i, n-i}).
-func (d *DualDequeue) Add(i int, v V) bool {
+func (d *DualDequeue[T]) Add(i int, v T) bool {
+	if i < 0 || i > d.Len() {
+		return false
+	}
 	if l := d.f.Len(); i < l {
 		d.f.Add(l-i, v)
 	} else {
@@ -369,47 +993,161 @@ func (d *DualDequeue) Add(i int, v V) bool {
 //
 // This operation has an amortized time
 // complexity of O(min{i, n-i}).
-func (d *DualDequeue) Remove(i int) (V, bool) {
-	var t V
+func (d *DualDequeue[T]) Remove(i int) (T, bool) {
+	var t T
 	var ok bool
 	if l := d.f.Len(); i < l {
 		t, ok = d.f.Remove(l - i - 1)
 		if !ok {
-			return nil, false
+			return *new(T), false
 		}
 	} else {
 		t, ok = d.b.Remove(i - l)
 		if !ok {
-			return nil, false
+			return *new(T), false
 		}
 	}
 	d.balance()
 	return t, true
 }
 
-func (d *DualDequeue) balance() {
+func (d *DualDequeue[T]) balance() {
 	if 3*d.f.Len() < d.b.Len() {
-		var f, b Array
+		// move the front of b to the front of f
 		s := d.Len()/2 - d.f.Len()
-		f.addAll(d.b.sub(0, s))
-		f.reverse()
-		f.addAll(d.f)
-		b.addAll(d.b.sub(s, d.b.Len()))
-		d.f, d.b = f, b
+		m, _ := d.b.Slice(0, s)
+		slices.Reverse(m)
+		d.f.AddAll(0, m...)
+		d.b.RemoveRange(0, s)
 	} else if 3*d.b.Len() < d.f.Len() {
-		var f, b Array
+		// move the front of f to the front of b
 		s := d.f.Len() - d.Len()/2
-		f.addAll(d.f.sub(s, d.f.Len()))
-		b.addAll(d.f.sub(0, s))
-		b.reverse()
-		b.addAll(d.b)
-		d.f, d.b = f, b
+		m, _ := d.f.Slice(0, s)
+		slices.Reverse(m)
+		d.b.AddAll(0, m...)
+		d.f.RemoveRange(0, s)
 	}
 }
 
 // Len returns the number of
 // elements in the dual dequeue.
-func (d *DualDequeue) Len() int { return d.f.Len() + d.b.Len() }
+func (d *DualDequeue[T]) Len() int { return d.f.Len() + d.b.Len() }
+
+// All returns an iterator over the indices and
+// elements of the dual dequeue, in order.
+func (d *DualDequeue[T]) All() iter.Seq2[int, T] { return forward[T](d) }
+
+// Backward returns an iterator over the indices and
+// elements of the dual dequeue, in reverse order.
+func (d *DualDequeue[T]) Backward() iter.Seq2[int, T] { return backward[T](d) }
+
+// AddAll adds the given elements to the dual
+// dequeue at the given index and reports whether
+// it was successful or not. The dual dequeue is
+// resized and rebalanced at most once.
+//
+// This operation has an amortized time
+// complexity of O(min{i, n-i}+k), where
+// k is the number of added elements.
+func (d *DualDequeue[T]) AddAll(i int, vs ...T) bool {
+	if i < 0 || i > d.Len() {
+		return false
+	}
+	if l := d.f.Len(); i < l {
+		m := slices.Clone(vs)
+		slices.Reverse(m)
+		d.f.AddAll(l-i, m...)
+	} else {
+		d.b.AddAll(i-l, vs...)
+	}
+	d.balance()
+	return true
+}
+
+// RemoveRange removes the elements of the dual
+// dequeue at the indices [f, t) and reports
+// whether the operation was successful or not.
+// The dual dequeue is resized and rebalanced
+// at most once.
+//
+// This operation has an amortized time
+// complexity of O(min{f, n-t}+t-f).
+func (d *DualDequeue[T]) RemoveRange(f, t int) bool {
+	if f < 0 || t > d.Len() || f > t {
+		return false
+	}
+	l := d.f.Len()
+	if f < l {
+		d.f.RemoveRange(l-min(t, l), l-f)
+	}
+	if t > l {
+		d.b.RemoveRange(max(f, l)-l, t-l)
+	}
+	d.balance()
+	return true
+}
+
+// Slice returns a copy of the elements of the
+// dual dequeue at the indices [f, t).
+//
+// This operation has a time complexity of O(t-f).
+func (d *DualDequeue[T]) Slice(f, t int) ([]T, bool) {
+	if f < 0 || t > d.Len() || f > t {
+		return nil, false
+	}
+	s := make([]T, 0, t-f)
+	l := d.f.Len()
+	if f < l {
+		m, _ := d.f.Slice(l-min(t, l), l-f)
+		slices.Reverse(m)
+		s = append(s, m...)
+	}
+	if t > l {
+		m, _ := d.b.Slice(max(f, l)-l, t-l)
+		s = append(s, m...)
+	}
+	return s, true
+}
+
+// Reverse reverses the order of the
+// elements of the dual dequeue.
+//
+// This operation has a time complexity of O(1).
+func (d *DualDequeue[T]) Reverse() { d.f, d.b = d.b, d.f }
+
+// Clear removes all elements
+// of the dual dequeue.
+//
+// This operation has a time complexity of O(1).
+func (d *DualDequeue[T]) Clear() { *d = DualDequeue[T]{} }
+
+// AddFirst adds an element to the
+// front of the dual dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *DualDequeue[T]) AddFirst(v T) { d.Add(0, v) }
+
+// AddLast adds an element to the
+// back of the dual dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *DualDequeue[T]) AddLast(v T) { d.Add(d.Len(), v) }
+
+// RemoveFirst removes and returns the
+// element at the front of the dual dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *DualDequeue[T]) RemoveFirst() (T, bool) { return d.Remove(0) }
+
+// RemoveLast removes and returns the
+// element at the back of the dual dequeue.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d *DualDequeue[T]) RemoveLast() (T, bool) { return d.Remove(d.Len() - 1) }
 
 // --- RootishStack -------
 
@@ -417,37 +1155,35 @@ func (d *DualDequeue) Len() int { return d.f.Len() + d.b.Len() }
 // arrays to store elements and
 // wastes at most O(sqrt(n)) space
 // when storing n items.
-type RootishStack struct {
-	b Dequeue // backing blocks
-	n int     // number of elements
+type RootishStack[T any] struct {
+	b Dequeue[[]T] // backing blocks
+	n int          // number of elements
 }
 
 // Get returns the element at the
 // given index.
 //
 // This operation has a time complexity of O(1).
-func (r *RootishStack) Get(i int) (V, bool) {
+func (r *RootishStack[T]) Get(i int) (T, bool) {
 	if i < 0 || i > r.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	b := i2b(i)
-	a, _ := r.b.Get(b)
-	return a.([]V)[i-b*(b+1)/2], true
+	return r.block(b)[i-b*(b+1)/2], true
 }
 
 // Set sets the element at the given
 // index and returns the old one.
 //
 // This operation has a time complexity of O(1).
-func (r *RootishStack) Set(i int, v V) (V, bool) {
+func (r *RootishStack[T]) Set(i int, v T) (T, bool) {
 	if i < 0 || i > r.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	b := i2b(i)
-	j := i - b*(b+1)/2
-	a, _ := r.b.Get(b)
-	t := a.([]V)[j]
-	a.([]V)[j] = v
+	a, j := r.block(b), i-b*(b+1)/2
+	t := a[j]
+	a[j] = v
 	return t, true
 }
 
@@ -458,19 +1194,26 @@ func (r *RootishStack) Set(i int, v V) (V, bool) {
 //
 // This operation has an amortized time
 // complexity of O(n-i).
-func (r *RootishStack) Add(i int, v V) bool {
+func (r *RootishStack[T]) Add(i int, v T) bool {
 	if i < 0 || i > r.n {
 		return false
 	}
 	if l := r.b.Len(); l*(l+1)/2 < r.n+1 {
-		r.b.Add(r.b.Len(), V(make([]V, r.b.Len()+1)))
+		r.b.Add(r.b.Len(), make([]T, r.b.Len()+1))
 	}
 	r.n++
-	for j := r.n - 1; j > i; j-- {
-		p, _ := r.Get(j - 1)
-		r.Set(j, p)
+	// shift right one position, block by block from the back
+	b := i2b(i)
+	for c := i2b(r.n - 1); c > b; c-- {
+		a, p := r.block(c), r.block(c-1)
+		e := min(len(a), r.n-c*(c+1)/2)
+		copy(a[1:e], a[:e-1])
+		a[0] = p[len(p)-1]
 	}
-	r.Set(i, v)
+	a, j := r.block(b), i-b*(b+1)/2
+	e := min(len(a), r.n-b*(b+1)/2)
+	copy(a[j+1:e], a[j:e-1])
+	a[j] = v
 	return true
 }
 
@@ -481,25 +1224,156 @@ func (r *RootishStack) Add(i int, v V) bool {
 //
 // This operation has an amortized time
 // complexity of O(n-i).
-func (r *RootishStack) Remove(i int) (V, bool) {
+func (r *RootishStack[T]) Remove(i int) (T, bool) {
 	if i < 0 || i > r.n-1 {
-		return nil, false
+		return *new(T), false
 	}
-	t, _ := r.Get(i)
-	for j := i; j < r.n-1; j++ {
-		p, _ := r.Get(j + 1)
-		r.Set(j, p)
+	b, l := i2b(i), i2b(r.n-1)
+	a, j := r.block(b), i-b*(b+1)/2
+	t := a[j]
+	// shift left one position, block by block from the front
+	for c := b; c <= l; c++ {
+		a = r.block(c)
+		e := min(len(a), r.n-c*(c+1)/2)
+		copy(a[j:e-1], a[j+1:e])
+		if c < l {
+			a[e-1] = r.block(c + 1)[0]
+		} else {
+			a[e-1] = *new(T)
+		}
+		j = 0
 	}
 	r.n--
-	for l := r.b.Len(); l > 0 && (l-2)*(l-1)/2 >= r.n; l-- {
-		r.b.Remove(r.b.Len() - 1)
-	}
+	r.shrink()
 	return t, true
 }
 
+// AddAll adds the given elements to the stack
+// at the given index and reports whether it was
+// successful or not. The stack is resized at
+// most once.
+//
+// This operation has an amortized time
+// complexity of O(n-i+k), where k is the
+// number of added elements.
+func (r *RootishStack[T]) AddAll(i int, vs ...T) bool {
+	if i < 0 || i > r.n {
+		return false
+	}
+	k := len(vs)
+	s := make([]T, r.n-i)
+	r.read(i, s)
+	var bs [][]T
+	for b := r.b.Len(); b*(b+1)/2 < r.n+k; b++ {
+		bs = append(bs, make([]T, b+1))
+	}
+	r.b.AddAll(r.b.Len(), bs...)
+	r.n += k
+	r.write(i, vs)
+	r.write(i+k, s)
+	return true
+}
+
+// RemoveRange removes the elements of the
+// stack at the indices [f, t) and reports
+// whether the operation was successful or
+// not. The stack is resized at most once.
+//
+// This operation has an amortized time
+// complexity of O(n-f).
+func (r *RootishStack[T]) RemoveRange(f, t int) bool {
+	if f < 0 || t > r.n || f > t {
+		return false
+	}
+	s := make([]T, r.n-t)
+	r.read(t, s)
+	r.write(f, s)
+	// clear the vacated positions
+	r.write(r.n-(t-f), make([]T, t-f))
+	r.n -= t - f
+	r.shrink()
+	return true
+}
+
+// Slice returns a copy of the elements
+// of the stack at the indices [f, t).
+//
+// This operation has a time complexity of O(t-f).
+func (r *RootishStack[T]) Slice(f, t int) ([]T, bool) {
+	if f < 0 || t > r.n || f > t {
+		return nil, false
+	}
+	s := make([]T, t-f)
+	r.read(f, s)
+	return s, true
+}
+
+// Reverse reverses the order of
+// the elements of the stack.
+//
+// This operation has a time complexity of O(n).
+func (r *RootishStack[T]) Reverse() {
+	for i, j := 0, r.n-1; i < j; i, j = i+1, j-1 {
+		b, c := i2b(i), i2b(j)
+		x, y := r.block(b), r.block(c)
+		k, l := i-b*(b+1)/2, j-c*(c+1)/2
+		x[k], y[l] = y[l], x[k]
+	}
+}
+
+// Clear removes all elements
+// of the stack.
+//
+// This operation has a time complexity of O(1).
+func (r *RootishStack[T]) Clear() { *r = RootishStack[T]{} }
+
+// read copies the elements at the
+// indices [i, i+len(s)) into s.
+func (r *RootishStack[T]) read(i int, s []T) {
+	for len(s) > 0 {
+		b := i2b(i)
+		c := copy(s, r.block(b)[i-b*(b+1)/2:])
+		s, i = s[c:], i+c
+	}
+}
+
+// write copies s to the elements
+// at the indices [i, i+len(s)).
+func (r *RootishStack[T]) write(i int, s []T) {
+	for len(s) > 0 {
+		b := i2b(i)
+		c := copy(r.block(b)[i-b*(b+1)/2:], s)
+		s, i = s[c:], i+c
+	}
+}
+
+// shrink removes the blocks which
+// are no longer needed at once.
+func (r *RootishStack[T]) shrink() {
+	l := r.b.Len()
+	for l > 0 && (l-2)*(l-1)/2 >= r.n {
+		l--
+	}
+	r.b.RemoveRange(l, r.b.Len())
+}
+
 // Len returns the number
 // of elements in the stack.
-func (r *RootishStack) Len() int { return r.n }
+func (r *RootishStack[T]) Len() int { return r.n }
+
+// All returns an iterator over the indices and
+// elements of the stack, in order.
+func (r *RootishStack[T]) All() iter.Seq2[int, T] { return forward[T](r) }
+
+// Backward returns an iterator over the indices and
+// elements of the stack, in reverse order.
+func (r *RootishStack[T]) Backward() iter.Seq2[int, T] { return backward[T](r) }
+
+// block returns the block with the given index.
+func (r *RootishStack[T]) block(b int) []T {
+	a, _ := r.b.Get(b)
+	return a
+}
 
 func i2b(i int) int {
 	return int(math.Ceil((-3 + math.Sqrt(9+8*float64(i))) / 2.0))
@@ -513,3 +1387,33 @@ func max(a, b int) int {
 	}
 	return a
 }
+
+// forward returns an iterator over the indices
+// and elements of l, in order. It stops if Get
+// fails, e.g. because l shrank. Get must have
+// a time complexity of O(1).
+func forward[T any](l List[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < l.Len(); i++ {
+			v, ok := l.Get(i)
+			if !ok || !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// backward returns an iterator over the indices
+// and elements of l, in reverse order. It stops
+// if Get fails, e.g. because l shrank. Get must
+// have a time complexity of O(1).
+func backward[T any](l List[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := l.Len() - 1; i >= 0; i-- {
+			v, ok := l.Get(i)
+			if !ok || !yield(i, v) {
+				return
+			}
+		}
+	}
+}
@@ -4,11 +4,18 @@
 
 package ds
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestArray(t *testing.T) {
 	const midcap, maxcap, n = 84, 128, 65
-	var a Array
+	var a Array[int]
 
 	if _, ok := a.Get(0); ok {
 		t.Errorf("no element at index 0")
@@ -49,10 +56,10 @@ func TestArray(t *testing.T) {
 			t.Errorf("not found: %d", i)
 			continue
 		}
-		a.Set(i, v.(int)*v.(int))
+		a.Set(i, v*v)
 	}
 
-	a.reverse()
+	a.Reverse()
 	for i := 0; i < n; i++ {
 		v, ok := a.Get(i)
 		if !ok {
@@ -63,10 +70,10 @@ func TestArray(t *testing.T) {
 			t.Errorf("want %d, got %v", e, v)
 		}
 	}
-	a.reverse()
+	a.Reverse()
 
-	var o Array
-	o.addAll(a)
+	var o Array[int]
+	o.AddAll(0, a.s[:a.n]...)
 	for i := 0; i < n; i++ {
 		v, ok := o.Get(i)
 		if !ok {
@@ -81,7 +88,7 @@ func TestArray(t *testing.T) {
 		t.Errorf("want %d, got %d", a.Len(), o.Len())
 	}
 
-	o = o.sub(0, o.Len()/2)
+	o.RemoveRange(o.Len()/2, o.Len())
 	if o.Len() != n/2 {
 		t.Errorf("want %d, got %d", n/2, o.Len())
 	}
@@ -115,9 +122,44 @@ func TestArray(t *testing.T) {
 	}
 }
 
-func TestStack(t *testing.T) {
+func TestArrayAdd(t *testing.T) {
+	const n = 65
+	var a Array[int]
+	var e []int
+
+	for i := 0; i < n; i++ {
+		j := a.Len() / 2
+		if ok := a.Add(j, i); !ok {
+			t.Errorf("cannot add: %d", i)
+		}
+		e = append(e[:j], append([]int{i}, e[j:]...)...)
+	}
+	for i, x := range e {
+		if v, _ := a.Get(i); v != x {
+			t.Errorf("want %d, got %d", x, v)
+		}
+	}
+}
+
+func TestArrayUntyped(t *testing.T) {
+	var a Array[V]
+
+	for i, v := range []V{1, "a", nil} {
+		if ok := a.Add(i, v); !ok {
+			t.Errorf("cannot add: %v", v)
+		}
+	}
+	if v, ok := a.Get(1); !ok || v != "a" {
+		t.Errorf("want %q, got %v", "a", v)
+	}
+	if v, ok := a.Get(2); !ok || v != nil {
+		t.Errorf("want nil, got %v", v)
+	}
+}
+
+func TestArrayStack(t *testing.T) {
 	const mincap, maxcap, n = 1, 128, 65
-	var s Stack
+	var s ArrayStack[int]
 
 	for i := 0; i < n; i++ {
 		s.Push(i)
@@ -148,9 +190,9 @@ func TestStack(t *testing.T) {
 	}
 }
 
-func TestQueue(t *testing.T) {
+func TestArrayQueue(t *testing.T) {
 	const mincap, maxcap, n = 1, 128, 65
-	var q Queue
+	var q ArrayQueue[int]
 
 	for i := 0; i < n; i++ {
 		q.Enqueue(i)
@@ -184,9 +226,135 @@ func TestQueue(t *testing.T) {
 	}
 }
 
+func TestRingBuffer(t *testing.T) {
+	var z RingBuffer[int]
+	if z.Cap() != 16 {
+		t.Errorf("want %d, got %d", 16, z.Cap())
+	}
+
+	b := NewRingBuffer[int](3, OverflowReject)
+	if _, ok := b.Pop(); ok {
+		t.Errorf("no element in ring buffer expected")
+	}
+	for i := 0; i < 3; i++ {
+		if ok := b.Push(i); !ok {
+			t.Errorf("cannot push: %d", i)
+		}
+	}
+	if ok := b.Push(3); ok {
+		t.Errorf("push into a full ring buffer: want false, got true")
+	}
+	if v, ok := b.Peek(); !ok || v != 0 {
+		t.Errorf("want %d, got %d", 0, v)
+	}
+	// wrap around
+	for i := 3; i < 10; i++ {
+		if v, ok := b.Pop(); !ok || v != i-3 {
+			t.Errorf("want %d, got %d", i-3, v)
+		}
+		if ok := b.Push(i); !ok {
+			t.Errorf("cannot push: %d", i)
+		}
+	}
+	for i, v := range b.All() {
+		if v != 7+i {
+			t.Errorf("index %d: want %d, got %d", i, 7+i, v)
+		}
+	}
+	if v, ok := b.Get(2); !ok || v != 9 {
+		t.Errorf("want %d, got %d", 9, v)
+	}
+	if _, ok := b.Get(3); ok {
+		t.Errorf("no element at index %d expected", 3)
+	}
+
+	b = NewRingBuffer[int](3, OverflowOverwrite)
+	for i := 0; i < 10; i++ {
+		if ok := b.Push(i); !ok {
+			t.Errorf("cannot push: %d", i)
+		}
+	}
+	if b.Len() != 3 {
+		t.Errorf("want %d, got %d", 3, b.Len())
+	}
+	for i := 7; i < 10; i++ {
+		if v, ok := b.Pop(); !ok || v != i {
+			t.Errorf("want %d, got %d", i, v)
+		}
+	}
+
+	b = NewRingBuffer[int](1, OverflowBlock)
+	b.Push(0)
+	done := make(chan bool)
+	go func() { done <- b.Push(1) }()
+	if v, ok := b.Pop(); !ok || v != 0 {
+		t.Errorf("want %d, got %d", 0, v)
+	}
+	if ok := <-done; !ok {
+		t.Errorf("blocked push: want true, got false")
+	}
+	if v, ok := b.Pop(); !ok || v != 1 {
+		t.Errorf("want %d, got %d", 1, v)
+	}
+
+	// the context and Close wake a blocked push
+	b.Push(2)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.PushContext(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+	go func() { done <- b.Push(3) }()
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+	if ok := <-done; ok {
+		t.Errorf("push after close: want false, got true")
+	}
+	if err := b.PushContext(context.Background(), 3); !errors.Is(err, ErrClosed) {
+		t.Errorf("want %v, got %v", ErrClosed, err)
+	}
+	if v, ok := b.Pop(); !ok || v != 2 {
+		t.Errorf("want %d, got %d", 2, v)
+	}
+	b.Close()
+
+	b = NewRingBuffer[int](1, OverflowReject)
+	b.Push(0)
+	if err := b.PushContext(context.Background(), 1); !errors.Is(err, ErrFull) {
+		t.Errorf("want %v, got %v", ErrFull, err)
+	}
+}
+
+func TestRingBufferAllocs(t *testing.T) {
+	for _, p := range []Overflow{OverflowReject, OverflowBlock, OverflowOverwrite} {
+		b := NewRingBuffer[int](8, p)
+		n := testing.AllocsPerRun(100, func() {
+			for i := 0; i < 8; i++ {
+				b.Push(i)
+			}
+			for i := 0; i < 8; i++ {
+				b.Pop()
+			}
+		})
+		if n != 0 {
+			t.Errorf("policy %d: want %d allocations, got %.0f", p, 0, n)
+		}
+	}
+}
+
+func BenchmarkRingBuffer(b *testing.B) {
+	r := NewRingBuffer[int](1024, OverflowOverwrite)
+	for i := 0; i < b.N; i++ {
+		r.Push(i)
+		if i%2 == 0 {
+			r.Pop()
+		}
+	}
+}
+
 func TestDequeue(t *testing.T) {
 	const midcap, maxcap, n = 84, 128, 65
-	var d Dequeue
+	var d Dequeue[int]
 
 	if _, ok := d.Get(0); ok {
 		t.Errorf("no element at index 0")
@@ -227,7 +395,7 @@ func TestDequeue(t *testing.T) {
 			t.Errorf("not found: %d", i)
 			continue
 		}
-		d.Set(i, v.(int)*v.(int))
+		d.Set(i, v*v)
 	}
 
 	for i := n - 1; i >= 0; i -= 2 {
@@ -263,7 +431,7 @@ func TestDequeue(t *testing.T) {
 
 func TestRootishStack(t *testing.T) {
 	const n = 65
-	var r RootishStack
+	var r RootishStack[int]
 
 	if _, ok := r.Get(0); ok {
 		t.Errorf("no element at index 0")
@@ -301,7 +469,7 @@ func TestRootishStack(t *testing.T) {
 			t.Errorf("not found: %d", i)
 			continue
 		}
-		r.Set(i, v.(int)*v.(int))
+		r.Set(i, v*v)
 	}
 
 	for i := n - 1; i >= 0; i -= 2 {
@@ -331,7 +499,14 @@ func TestRootishStack(t *testing.T) {
 
 func TestDualDequeue(t *testing.T) {
 	const n = 65
-	var d DualDequeue
+	var d DualDequeue[int]
+
+	if ok := d.Add(-1, 1); ok {
+		t.Errorf("cannot add at index -1")
+	}
+	if ok := d.Add(1, 1); ok {
+		t.Errorf("cannot add at index 1")
+	}
 
 	if _, ok := d.Get(0); ok {
 		t.Errorf("no element at index 0")
@@ -372,7 +547,7 @@ func TestDualDequeue(t *testing.T) {
 			t.Errorf("not found: %d", i)
 			continue
 		}
-		d.Set(i, v.(int)*v.(int))
+		d.Set(i, v*v)
 	}
 
 	for i := n - 1; i >= 0; i -= 2 {
@@ -399,3 +574,147 @@ func TestDualDequeue(t *testing.T) {
 		t.Errorf("want %d, got %d", n, d.Len())
 	}
 }
+
+func TestGrowth(t *testing.T) {
+	type array interface {
+		Cap() int
+		SetGrowth(g Growth)
+		Reserve(n int)
+		ShrinkToFit()
+		Len() int
+		Add(v int)
+		Remove() (int, bool)
+	}
+	arrays := map[string]func() array{
+		"Array":      func() array { return &growArray{new(Array[int])} },
+		"ArrayQueue": func() array { return &growQueue{new(ArrayQueue[int])} },
+		"Dequeue":    func() array { return &growDequeue{new(Dequeue[int])} },
+	}
+	for name, newArray := range arrays {
+		// pre-sized and never resized
+		a := newArray()
+		a.SetGrowth(Growth{Min: 100, NoShrink: true})
+		if a.Cap() != 100 {
+			t.Errorf("%s: want %d, got %d", name, 100, a.Cap())
+		}
+		for i := 0; i < 100; i++ {
+			a.Add(i)
+		}
+		for a.Len() > 0 {
+			a.Remove()
+		}
+		if a.Cap() != 100 {
+			t.Errorf("%s: want %d, got %d", name, 100, a.Cap())
+		}
+		a.Add(0)
+		a.ShrinkToFit()
+		if a.Cap() != 100 {
+			t.Errorf("%s: want %d, got %d", name, 100, a.Cap())
+		}
+
+		// growth factor
+		a = newArray()
+		a.SetGrowth(Growth{Factor: 1.5})
+		caps := []int{a.Cap()}
+		for i := 0; i < 20; i++ {
+			a.Add(i)
+			if c := a.Cap(); c != caps[len(caps)-1] {
+				caps = append(caps, c)
+			}
+		}
+		if want := []int{0, 1, 2, 3, 4, 6, 9, 13, 19, 28}; !slices.Equal(caps, want) {
+			t.Errorf("%s: want %v, got %v", name, want, caps)
+		}
+		// shrinks when at most 1/2.5 of the slots are used
+		for a.Len() > 12 {
+			a.Remove()
+		}
+		if a.Cap() != 28 {
+			t.Errorf("%s: want %d, got %d", name, 28, a.Cap())
+		}
+		a.Remove()
+		if a.Cap() != 16 {
+			t.Errorf("%s: want %d, got %d", name, 16, a.Cap())
+		}
+
+		a.Reserve(1000)
+		if a.Cap() != 1000 {
+			t.Errorf("%s: want %d, got %d", name, 1000, a.Cap())
+		}
+		a.ShrinkToFit()
+		if a.Cap() != a.Len() {
+			t.Errorf("%s: want %d, got %d", name, a.Len(), a.Cap())
+		}
+		for i := 9; i < 20; i++ {
+			if v, ok := a.Remove(); !ok || v != i {
+				t.Errorf("%s: want %d, got %d", name, i, v)
+			}
+		}
+	}
+}
+
+func TestGrowthClear(t *testing.T) {
+	var a Array[int]
+	a.SetGrowth(Growth{NoShrink: true})
+	a.AddAll(0, 1, 2, 3)
+	a.Clear()
+	if a.Len() != 0 || a.Cap() != 6 {
+		t.Errorf("want %d and %d, got %d and %d", 0, 6, a.Len(), a.Cap())
+	}
+	var d Dequeue[int]
+	d.AddAll(0, 1, 2, 3)
+	d.Clear()
+	if d.Cap() != 0 {
+		t.Errorf("want %d, got %d", 0, d.Cap())
+	}
+}
+
+type growArray struct{ *Array[int] }
+
+func (a *growArray) Add(v int)           { a.Array.Add(a.Len(), v) }
+func (a *growArray) Remove() (int, bool) { return a.Array.Remove(0) }
+
+type growQueue struct{ *ArrayQueue[int] }
+
+func (q *growQueue) Add(v int)           { q.Enqueue(v) }
+func (q *growQueue) Remove() (int, bool) { return q.Dequeue() }
+
+type growDequeue struct{ *Dequeue[int] }
+
+func (d *growDequeue) Add(v int)           { d.AddLast(v) }
+func (d *growDequeue) Remove() (int, bool) { return d.RemoveFirst() }
+
+func TestShift(t *testing.T) {
+	lists := map[string]List[int]{
+		"Dequeue":      new(Dequeue[int]),
+		"RootishStack": new(RootishStack[int]),
+	}
+	for name, l := range lists {
+		r := rand.New(rand.NewSource(1))
+		var e []int
+		for k := 0; k < 5000; k++ {
+			// grow, shrink and grow again to wrap around
+			if (k/1000)%2 == 0 && r.Intn(4) > 0 || (k/1000)%2 == 1 && r.Intn(4) == 0 {
+				i := r.Intn(len(e) + 1)
+				if ok := l.Add(i, k); !ok {
+					t.Fatalf("%s: cannot add at %d", name, i)
+				}
+				e = slices.Insert(e, i, k)
+			} else if len(e) > 0 {
+				i := r.Intn(len(e))
+				if v, ok := l.Remove(i); !ok || v != e[i] {
+					t.Fatalf("%s: remove %d: want %d, got %d", name, i, e[i], v)
+				}
+				e = slices.Delete(e, i, i+1)
+			}
+			if l.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), l.Len())
+			}
+		}
+		for i, x := range e {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Fatalf("%s: index %d: want %d, got %d", name, i, x, v)
+			}
+		}
+	}
+}
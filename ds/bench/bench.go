@@ -0,0 +1,166 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command bench runs the list benchmarks of package ds
+// and prints a table comparing the measured times with
+// the documented time complexities.
+//
+// For each operation, access pattern and list, the table
+// shows the time per operation for each size, the
+// documented bound, the growth expected from the bound for
+// the access pattern and the observed growth: the exponent
+// e such that the time grows like n^e between the smallest
+// and the largest size. Deviations from the expected
+// growth are marked by an exclamation mark. Amortized
+// bounds need a benchtime of many iterations.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	ops = []string{
+		"Get", "Set", "Add", "Remove",
+		"AddFirst", "AddLast", "RemoveFirst", "RemoveLast",
+		"EnqueueDequeue", "AddAll", "RemoveRange",
+	}
+	patterns = []string{"front", "middle", "back", "random"}
+	lists    = []string{"Array", "Dequeue", "DualDequeue", "RootishStack", "DList", "ArrayQueue", "SList"}
+)
+
+// bounds are the documented time complexities,
+// where k is the number of elements added or
+// removed by a bulk operation.
+var bounds = map[string]map[string]string{
+	"Array": {
+		"Get": "1", "Set": "1", "Add": "n-i", "Remove": "n-i",
+		"AddAll": "n-i+k", "RemoveRange": "n-f",
+	},
+	"Dequeue": {
+		"Get": "1", "Set": "1", "Add": "min{i, n-i}", "Remove": "min{i, n-i}",
+		"AddFirst": "1", "AddLast": "1", "RemoveFirst": "1", "RemoveLast": "1",
+		"AddAll": "min{i, n-i}+k", "RemoveRange": "min{f, n-t}+t-f",
+	},
+	"DualDequeue": {
+		"Get": "1", "Set": "1", "Add": "min{i, n-i}", "Remove": "min{i, n-i}",
+		"AddFirst": "1", "AddLast": "1", "RemoveFirst": "1", "RemoveLast": "1",
+		"AddAll": "min{i, n-i}+k", "RemoveRange": "min{f, n-t}+t-f",
+	},
+	"RootishStack": {
+		"Get": "1", "Set": "1", "Add": "n-i", "Remove": "n-i",
+		"AddAll": "n-i+k", "RemoveRange": "n-f",
+	},
+	"DList": {
+		"Get": "min{i, n-i}", "Set": "min{i, n-i}", "Add": "min{i, n-i}", "Remove": "min{i, n-i}",
+		"AddFirst": "1", "AddLast": "1", "RemoveFirst": "1", "RemoveLast": "1",
+		"AddAll": "min{i, n-i}+k", "RemoveRange": "min{f, n-f}+t-f",
+	},
+	"ArrayQueue": {"EnqueueDequeue": "1"},
+	"SList":      {"EnqueueDequeue": "1"},
+}
+
+// linear reports whether the bound is linear
+// in n for the given access pattern.
+func linear(bound, pattern string) bool {
+	switch bound {
+	case "n-i", "n-i+k", "n-f":
+		return pattern != "back"
+	case "min{i, n-i}", "min{i, n-i}+k", "min{f, n-t}+t-f", "min{f, n-f}+t-f":
+		return pattern == "middle" || pattern == "random"
+	}
+	return false
+}
+
+// key identifies a benchmark.
+type key struct{ op, list, pattern string }
+
+var line = regexp.MustCompile(`^Benchmark(\w+)/(\w+)/(\w+)/n=(\d+)(?:-\d+)?\s+\d+\s+([\d.]+) ns/op`)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bench: ")
+
+	var (
+		benchtime = flag.String("benchtime", "100ms", "run time of each benchmark")
+		pkg       = flag.String("pkg", "github.com/davidrjenni/lib/ds", "package to benchmark")
+	)
+	flag.Parse()
+
+	cmd := exec.Command("go", "test", "-run", "^$", "-bench", "^Benchmark("+strings.Join(ops, "|")+")$", "-benchtime", *benchtime, *pkg)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		log.Fatal("run benchmarks: ", err)
+	}
+
+	res := make(map[key]map[int]float64)
+	var sizes []int
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		m := line.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[4])
+		t, _ := strconv.ParseFloat(m[5], 64)
+		k := key{m[1], m[2], m[3]}
+		if res[k] == nil {
+			res[k] = make(map[int]float64)
+		}
+		res[k][n] = t
+		if !slices.Contains(sizes, n) {
+			sizes = append(sizes, n)
+		}
+	}
+	if len(sizes) < 2 {
+		log.Fatal("not enough benchmark results")
+	}
+	slices.Sort(sizes)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "operation\tpattern\tlist\t")
+	for _, n := range sizes {
+		fmt.Fprintf(w, "n=%d\t", n)
+	}
+	fmt.Fprint(w, "bound\texpected\tobserved\t\n")
+	for _, op := range ops {
+		for _, p := range patterns {
+			for _, l := range lists {
+				r, ok := res[key{op, l, p}]
+				if !ok {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t", op, p, l)
+				for _, n := range sizes {
+					fmt.Fprintf(w, "%.1f ns\t", r[n])
+				}
+				lo, hi := sizes[0], sizes[len(sizes)-1]
+				e := math.Log(r[hi]/r[lo]) / math.Log(float64(hi)/float64(lo))
+				b := bounds[l][op]
+				want, mark := "1", ""
+				if linear(b, p) {
+					want = "n"
+				}
+				if (want == "n") != (e > 0.5) {
+					mark = " !"
+				}
+				fmt.Fprintf(w, "O(%s)\t%s\tn^%.2f%s\t\n", b, want, e, mark)
+			}
+		}
+	}
+	w.Flush()
+}
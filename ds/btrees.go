@@ -0,0 +1,751 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+)
+
+// --- Block stores -------
+
+// BlockStore stores fixed-size blocks
+// of bytes, addressed by their index.
+type BlockStore interface {
+	// BlockSize returns the size
+	// of a block in bytes.
+	BlockSize() int
+	// Len returns the number of blocks.
+	Len() int
+	// ReadBlock reads the block i into b.
+	ReadBlock(i int, b []byte) error
+	// WriteBlock writes b to the block i,
+	// growing the store if i >= Len().
+	WriteBlock(i int, b []byte) error
+}
+
+// MemBlockStore implements a block store in memory.
+type MemBlockStore struct {
+	b    [][]byte // blocks
+	size int      // block size
+}
+
+// NewMemBlockStore returns an empty block
+// store with the given block size.
+func NewMemBlockStore(size int) *MemBlockStore {
+	return &MemBlockStore{size: size}
+}
+
+// BlockSize returns the size of a block in bytes.
+func (s *MemBlockStore) BlockSize() int { return s.size }
+
+// Len returns the number of blocks.
+func (s *MemBlockStore) Len() int { return len(s.b) }
+
+// ReadBlock reads the block i into b.
+func (s *MemBlockStore) ReadBlock(i int, b []byte) error {
+	if i < 0 || i >= len(s.b) {
+		return fmt.Errorf("ds: block %d out of range [0, %d)", i, len(s.b))
+	}
+	copy(b, s.b[i])
+	return nil
+}
+
+// WriteBlock writes b to the block i,
+// growing the store if i >= Len().
+func (s *MemBlockStore) WriteBlock(i int, b []byte) error {
+	if i < 0 {
+		return fmt.Errorf("ds: negative block %d", i)
+	}
+	for len(s.b) <= i {
+		s.b = append(s.b, make([]byte, s.size))
+	}
+	copy(s.b[i], b)
+	return nil
+}
+
+// FileBlockStore implements a block store in a file.
+type FileBlockStore struct {
+	f    *os.File // backing file
+	size int      // block size
+	n    int      // number of blocks
+}
+
+// OpenFileBlockStore opens the named file as block store
+// with the given block size, creating it if necessary.
+func OpenFileBlockStore(name string, size int) (*FileBlockStore, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("ds: invalid block size %d", size)
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size()%int64(size) != 0 {
+		f.Close()
+		return nil, fmt.Errorf("ds: size of %s is not a multiple of the block size %d", name, size)
+	}
+	return &FileBlockStore{f: f, size: size, n: int(fi.Size() / int64(size))}, nil
+}
+
+// BlockSize returns the size of a block in bytes.
+func (s *FileBlockStore) BlockSize() int { return s.size }
+
+// Len returns the number of blocks.
+func (s *FileBlockStore) Len() int { return s.n }
+
+// ReadBlock reads the block i into b.
+func (s *FileBlockStore) ReadBlock(i int, b []byte) error {
+	if i < 0 || i >= s.n {
+		return fmt.Errorf("ds: block %d out of range [0, %d)", i, s.n)
+	}
+	_, err := s.f.ReadAt(b[:s.size], int64(i)*int64(s.size))
+	return err
+}
+
+// WriteBlock writes b to the block i,
+// growing the store if i >= Len().
+func (s *FileBlockStore) WriteBlock(i int, b []byte) error {
+	if i < 0 {
+		return fmt.Errorf("ds: negative block %d", i)
+	}
+	if _, err := s.f.WriteAt(b[:s.size], int64(i)*int64(s.size)); err != nil {
+		return err
+	}
+	s.n = max(s.n, i+1)
+	return nil
+}
+
+// Sync commits the blocks to stable storage.
+func (s *FileBlockStore) Sync() error { return s.f.Sync() }
+
+// Close closes the backing file.
+func (s *FileBlockStore) Close() error { return s.f.Close() }
+
+// --- Codecs -------
+
+// Codec encodes and decodes elements
+// to and from a fixed number of bytes.
+type Codec[T any] interface {
+	// Size returns the size of an
+	// encoded element in bytes.
+	Size() int
+	// Encode encodes v into b.
+	Encode(b []byte, v T)
+	// Decode decodes an element from b.
+	Decode(b []byte) T
+}
+
+// BinaryCodec encodes fixed-size values, such
+// as integers, floats and arrays or structs of
+// them, in big-endian byte order. Its size is
+// negative for other types.
+type BinaryCodec[T any] struct{}
+
+// Size returns the size of an encoded element in bytes.
+func (BinaryCodec[T]) Size() int { return binary.Size(*new(T)) }
+
+// Encode encodes v into b.
+func (BinaryCodec[T]) Encode(b []byte, v T) { binary.Encode(b, binary.BigEndian, v) }
+
+// Decode decodes an element from b.
+func (BinaryCodec[T]) Decode(b []byte) T {
+	var v T
+	binary.Decode(b, binary.BigEndian, &v)
+	return v
+}
+
+// --- BTree -------
+
+const (
+	btreeMagic  = 0x44534254 // "DSBT", identifies the first block
+	btreeMeta   = 40         // size of the first block's contents
+	btreeHeader = 8          // size of a node's header
+)
+
+// bnode represents a node in a
+// B-tree, decoded from a block.
+type bnode[T any] struct {
+	id   int   // block
+	keys []T   // keys, in ascending order
+	c    []int // blocks of the children, nil in leaves
+}
+
+// IOStats counts the blocks
+// read and written by a BTree.
+type IOStats struct {
+	Reads, Writes int
+}
+
+// BTree implements a sorted set on top of
+// a B-tree, whose nodes are stored in the
+// blocks of a block store and are read and
+// written as needed. The elements are encoded
+// by a codec and ordered by a comparison
+// function, which returns a negative number
+// if a < b, zero if a == b and a positive
+// number if a > b.
+//
+// The first block stores the root, the size
+// and the list of free blocks of the tree.
+// The first I/O error stops all further
+// operations of the tree and is reported
+// by Err. The tree might be inconsistent
+// after an error.
+type BTree[T any] struct {
+	s     BlockStore       // backing block store
+	codec Codec[T]         // encoding of the keys
+	cmp   func(a, b T) int // comparison function
+	b     int              // nodes other than the root have between b-1 and 2b-1 keys
+	r     int              // block of the root
+	n     int              // number of elements
+	m     int              // number of blocks in use or free
+	free  int              // first block of the list of free blocks, or -1
+	buf   []byte           // buffer for a block
+	stats IOStats          // number of blocks read and written
+	err   error            // first I/O error
+}
+
+// NewBTree returns a tree which stores its nodes in s.
+// If s is empty, the tree is empty, otherwise s must
+// contain a tree with the same encoding of the keys.
+// The number of keys per node is the largest which
+// fits in a block.
+func NewBTree[T any](s BlockStore, c Codec[T], cmp func(a, b T) int) (*BTree[T], error) {
+	k := c.Size()
+	if k <= 0 {
+		return nil, errors.New("ds: codec has no fixed size")
+	}
+	m := (s.BlockSize() - 2*btreeHeader) / (k + 8)
+	if m < 3 || s.BlockSize() < btreeMeta {
+		return nil, fmt.Errorf("ds: block size %d is too small for keys of size %d", s.BlockSize(), k)
+	}
+	t := &BTree[T]{
+		s:     s,
+		codec: c,
+		cmp:   cmp,
+		b:     (m + 1) / 2,
+		buf:   make([]byte, s.BlockSize()),
+	}
+	if s.Len() == 0 {
+		t.m, t.free = 1, -1
+		t.r = t.alloc()
+		t.write(&bnode[T]{id: t.r})
+		t.writeMeta()
+		if t.err != nil {
+			return nil, t.err
+		}
+		return t, nil
+	}
+	if !t.readBlock(0) {
+		return nil, t.err
+	}
+	be := binary.BigEndian
+	if be.Uint32(t.buf) != btreeMagic || int(be.Uint32(t.buf[4:])) != k {
+		return nil, errors.New("ds: block store does not contain a tree with keys of this size")
+	}
+	t.r = int(be.Uint64(t.buf[8:]))
+	t.n = int(be.Uint64(t.buf[16:]))
+	t.free = int(int64(be.Uint64(t.buf[24:])))
+	t.m = int(be.Uint64(t.buf[32:]))
+	return t, nil
+}
+
+// Len returns the number of elements in the tree.
+func (t *BTree[T]) Len() int { return t.n }
+
+// Stats returns the number of blocks
+// read and written by the tree.
+func (t *BTree[T]) Stats() IOStats { return t.stats }
+
+// Err returns the first I/O error
+// of the tree, if there was one.
+func (t *BTree[T]) Err() error { return t.err }
+
+func (t *BTree[T]) readBlock(i int) bool {
+	if t.err != nil {
+		return false
+	}
+	t.stats.Reads++
+	t.err = t.s.ReadBlock(i, t.buf)
+	return t.err == nil
+}
+
+func (t *BTree[T]) writeBlock(i int) {
+	if t.err != nil {
+		return
+	}
+	t.stats.Writes++
+	t.err = t.s.WriteBlock(i, t.buf)
+}
+
+// writeMeta writes the root, the size
+// and the free blocks to the first block.
+func (t *BTree[T]) writeMeta() {
+	clear(t.buf)
+	be := binary.BigEndian
+	be.PutUint32(t.buf, btreeMagic)
+	be.PutUint32(t.buf[4:], uint32(t.codec.Size()))
+	be.PutUint64(t.buf[8:], uint64(t.r))
+	be.PutUint64(t.buf[16:], uint64(t.n))
+	be.PutUint64(t.buf[24:], uint64(int64(t.free)))
+	be.PutUint64(t.buf[32:], uint64(t.m))
+	t.writeBlock(0)
+}
+
+// read reads the node in block i,
+// or returns nil on an error.
+func (t *BTree[T]) read(i int) *bnode[T] {
+	if !t.readBlock(i) {
+		return nil
+	}
+	be := binary.BigEndian
+	n, k := int(be.Uint32(t.buf)), t.codec.Size()
+	if n > 2*t.b-1 {
+		t.err = fmt.Errorf("ds: block %d is corrupt", i)
+		return nil
+	}
+	u := &bnode[T]{id: i, keys: make([]T, n, 2*t.b)}
+	for j := range u.keys {
+		u.keys[j] = t.codec.Decode(t.buf[btreeHeader+j*k:])
+	}
+	if t.buf[4] == 0 {
+		off := btreeHeader + (2*t.b-1)*k
+		u.c = make([]int, n+1, 2*t.b+1)
+		for j := range u.c {
+			u.c[j] = int(be.Uint64(t.buf[off+8*j:]))
+		}
+	}
+	return u
+}
+
+// write writes the node u to its block.
+func (t *BTree[T]) write(u *bnode[T]) {
+	clear(t.buf)
+	be := binary.BigEndian
+	k := t.codec.Size()
+	be.PutUint32(t.buf, uint32(len(u.keys)))
+	for j, v := range u.keys {
+		t.codec.Encode(t.buf[btreeHeader+j*k:], v)
+	}
+	if u.c == nil {
+		t.buf[4] = 1
+	} else {
+		off := btreeHeader + (2*t.b-1)*k
+		for j, c := range u.c {
+			be.PutUint64(t.buf[off+8*j:], uint64(c))
+		}
+	}
+	t.writeBlock(u.id)
+}
+
+// alloc returns an unused block.
+func (t *BTree[T]) alloc() int {
+	if t.free < 0 {
+		t.m++
+		return t.m - 1
+	}
+	i := t.free
+	if t.readBlock(i) {
+		t.free = int(int64(binary.BigEndian.Uint64(t.buf)))
+	}
+	return i
+}
+
+// release adds the block i to
+// the list of free blocks.
+func (t *BTree[T]) release(i int) {
+	clear(t.buf)
+	binary.BigEndian.PutUint64(t.buf, uint64(int64(t.free)))
+	t.writeBlock(i)
+	t.free = i
+}
+
+// search returns the index of the first key of u
+// which is greater than or equal to v and reports
+// whether it is equal to v.
+func (t *BTree[T]) search(u *bnode[T], v T) (int, bool) {
+	return slices.BinarySearchFunc(u.keys, v, t.cmp)
+}
+
+// Find returns the smallest element
+// which is greater than or equal to
+// v and reports whether it exists.
+//
+// This operation reads O(log_b n) blocks.
+func (t *BTree[T]) Find(v T) (T, bool) {
+	var z T
+	found := false
+	for i := t.r; t.n > 0; {
+		u := t.read(i)
+		if u == nil {
+			return *new(T), false
+		}
+		j, ok := t.search(u, v)
+		if ok {
+			return u.keys[j], true
+		}
+		if j < len(u.keys) {
+			z, found = u.keys[j], true
+		}
+		if u.c == nil {
+			break
+		}
+		i = u.c[j]
+	}
+	return z, found
+}
+
+// Min returns the smallest element of the tree.
+//
+// This operation reads O(log_b n) blocks.
+func (t *BTree[T]) Min() (T, bool) {
+	if t.n == 0 {
+		return *new(T), false
+	}
+	for i := t.r; ; {
+		u := t.read(i)
+		if u == nil {
+			return *new(T), false
+		}
+		if u.c == nil {
+			return u.keys[0], true
+		}
+		i = u.c[0]
+	}
+}
+
+// Max returns the largest element of the tree.
+//
+// This operation reads O(log_b n) blocks.
+func (t *BTree[T]) Max() (T, bool) {
+	if t.n == 0 {
+		return *new(T), false
+	}
+	for i := t.r; ; {
+		u := t.read(i)
+		if u == nil {
+			return *new(T), false
+		}
+		if u.c == nil {
+			return u.keys[len(u.keys)-1], true
+		}
+		i = u.c[len(u.c)-1]
+	}
+}
+
+// Add adds an element to the tree and
+// reports whether it was added, i.e. it
+// was not already contained in the tree.
+//
+// This operation reads and writes
+// O(log_b n) blocks.
+func (t *BTree[T]) Add(v T) bool {
+	if t.err != nil {
+		return false
+	}
+	w, sep, ok := t.add(t.r, v)
+	if !ok {
+		return false
+	}
+	if w != nil {
+		// the root was split
+		r := &bnode[T]{id: t.alloc(), keys: []T{sep}, c: []int{t.r, w.id}}
+		t.write(r)
+		t.r = r.id
+	}
+	t.n++
+	t.writeMeta()
+	return t.err == nil
+}
+
+// add adds v to the subtree rooted at block i. If the
+// root of the subtree overflows, it is split and the new
+// right node and the key separating them are returned.
+func (t *BTree[T]) add(i int, v T) (*bnode[T], T, bool) {
+	var sep T
+	u := t.read(i)
+	if u == nil {
+		return nil, sep, false
+	}
+	j, found := t.search(u, v)
+	if found {
+		return nil, sep, false
+	}
+	if u.c == nil {
+		u.keys = slices.Insert(u.keys, j, v)
+	} else {
+		w, s, ok := t.add(u.c[j], v)
+		if !ok || w == nil {
+			return nil, sep, ok
+		}
+		u.keys = slices.Insert(u.keys, j, s)
+		u.c = slices.Insert(u.c, j+1, w.id)
+	}
+	var w *bnode[T]
+	if len(u.keys) == 2*t.b {
+		w, sep = t.split(u)
+	}
+	t.write(u)
+	return w, sep, t.err == nil
+}
+
+// split moves the largest b-1 keys of u, which has
+// 2b keys, into a new node and returns it together
+// with the key separating them.
+func (t *BTree[T]) split(u *bnode[T]) (*bnode[T], T) {
+	w := &bnode[T]{id: t.alloc(), keys: slices.Clone(u.keys[t.b+1:])}
+	sep := u.keys[t.b]
+	u.keys = u.keys[:t.b]
+	if u.c != nil {
+		w.c = slices.Clone(u.c[t.b+1:])
+		u.c = u.c[:t.b+1]
+	}
+	t.write(w)
+	return w, sep
+}
+
+// Remove removes an element from the tree
+// and reports whether it was removed, i.e.
+// it was contained in the tree.
+//
+// This operation reads and writes
+// O(log_b n) blocks.
+func (t *BTree[T]) Remove(v T) bool {
+	if t.err != nil || t.n == 0 {
+		return false
+	}
+	u, ok := t.remove(t.r, v)
+	if !ok {
+		return false
+	}
+	if len(u.keys) == 0 && u.c != nil {
+		// the root has a single child
+		t.r = u.c[0]
+		t.release(u.id)
+	}
+	t.n--
+	t.writeMeta()
+	return t.err == nil
+}
+
+// remove removes v from the subtree rooted at
+// block i and returns the root of the subtree,
+// which might have less than b-1 keys.
+func (t *BTree[T]) remove(i int, v T) (*bnode[T], bool) {
+	u := t.read(i)
+	if u == nil {
+		return nil, false
+	}
+	j, found := t.search(u, v)
+	switch {
+	case found && u.c == nil:
+		u.keys = slices.Delete(u.keys, j, j+1)
+	case found:
+		// replace v by its successor
+		w, s := t.removeMin(u.c[j+1])
+		if w == nil {
+			return nil, false
+		}
+		u.keys[j] = s
+		t.rebalance(u, j+1, w)
+	case u.c == nil:
+		return nil, false
+	default:
+		w, ok := t.remove(u.c[j], v)
+		if !ok {
+			return nil, false
+		}
+		t.rebalance(u, j, w)
+	}
+	t.write(u)
+	return u, t.err == nil
+}
+
+// removeMin removes the smallest key from the
+// subtree rooted at block i and returns the root
+// of the subtree and the key.
+func (t *BTree[T]) removeMin(i int) (*bnode[T], T) {
+	var s T
+	u := t.read(i)
+	if u == nil {
+		return nil, s
+	}
+	if u.c == nil {
+		s = u.keys[0]
+		u.keys = slices.Delete(u.keys, 0, 1)
+	} else {
+		var w *bnode[T]
+		if w, s = t.removeMin(u.c[0]); w == nil {
+			return nil, s
+		}
+		t.rebalance(u, 0, w)
+	}
+	t.write(u)
+	if t.err != nil {
+		return nil, s
+	}
+	return u, s
+}
+
+// rebalance makes sure that the child w at index
+// j of u has at least b-1 keys, by borrowing a key
+// from a sibling or by merging it with a sibling.
+// The caller writes u.
+func (t *BTree[T]) rebalance(u *bnode[T], j int, w *bnode[T]) {
+	if len(w.keys) >= t.b-1 {
+		return
+	}
+	if j > 0 {
+		v := t.read(u.c[j-1])
+		if v == nil {
+			return
+		}
+		if len(v.keys) == t.b-1 {
+			t.merge(u, j-1, v, w)
+			return
+		}
+		// borrow the largest key of the left sibling
+		w.keys = slices.Insert(w.keys, 0, u.keys[j-1])
+		u.keys[j-1] = v.keys[len(v.keys)-1]
+		v.keys = v.keys[:len(v.keys)-1]
+		if w.c != nil {
+			w.c = slices.Insert(w.c, 0, v.c[len(v.c)-1])
+			v.c = v.c[:len(v.c)-1]
+		}
+		t.write(v)
+		t.write(w)
+		return
+	}
+	v := t.read(u.c[1])
+	if v == nil {
+		return
+	}
+	if len(v.keys) == t.b-1 {
+		t.merge(u, 0, w, v)
+		return
+	}
+	// borrow the smallest key of the right sibling
+	w.keys = append(w.keys, u.keys[0])
+	u.keys[0] = v.keys[0]
+	v.keys = slices.Delete(v.keys, 0, 1)
+	if w.c != nil {
+		w.c = append(w.c, v.c[0])
+		v.c = slices.Delete(v.c, 0, 1)
+	}
+	t.write(v)
+	t.write(w)
+}
+
+// merge merges the children v and w of u, which
+// are separated by the key at index j, into v and
+// releases the block of w.
+func (t *BTree[T]) merge(u *bnode[T], j int, v, w *bnode[T]) {
+	v.keys = append(append(v.keys, u.keys[j]), w.keys...)
+	if v.c != nil {
+		v.c = append(v.c, w.c...)
+	}
+	u.keys = slices.Delete(u.keys, j, j+1)
+	u.c = slices.Delete(u.c, j+1, j+2)
+	t.write(v)
+	t.release(w.id)
+}
+
+// All returns an iterator over the
+// elements of the tree, in ascending order.
+//
+// This operation reads O(n/b) blocks.
+func (t *BTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if t.n > 0 {
+			t.scan(t.r, nil, nil, yield)
+		}
+	}
+}
+
+// Range returns an iterator over the elements
+// of the tree which are greater than or equal
+// to lo and less than hi, in ascending order.
+//
+// This operation reads O(log_b n + k/b) blocks,
+// where k is the number of elements in the range.
+func (t *BTree[T]) Range(lo, hi T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if t.n > 0 {
+			t.scan(t.r, &lo, &hi, yield)
+		}
+	}
+}
+
+// scan calls yield for the keys in the subtree
+// rooted at block i which are in [lo, hi), in
+// ascending order, and reports whether to go on.
+// A nil bound does not restrict the keys.
+func (t *BTree[T]) scan(i int, lo, hi *T, yield func(T) bool) bool {
+	u := t.read(i)
+	if u == nil {
+		return false
+	}
+	j := 0
+	if lo != nil {
+		j, _ = t.search(u, *lo)
+	}
+	for ; ; j++ {
+		if u.c != nil && !t.scan(u.c[j], lo, hi, yield) {
+			return false
+		}
+		if j == len(u.keys) {
+			return true
+		}
+		if hi != nil && t.cmp(u.keys[j], *hi) >= 0 {
+			return false
+		}
+		if !yield(u.keys[j]) {
+			return false
+		}
+	}
+}
+
+// Backward returns an iterator over the
+// elements of the tree, in descending order.
+//
+// This operation reads O(n/b) blocks.
+func (t *BTree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if t.n > 0 {
+			t.scanBackward(t.r, yield)
+		}
+	}
+}
+
+// scanBackward calls yield for the keys in the
+// subtree rooted at block i, in descending order,
+// and reports whether to go on.
+func (t *BTree[T]) scanBackward(i int, yield func(T) bool) bool {
+	u := t.read(i)
+	if u == nil {
+		return false
+	}
+	for j := len(u.keys); ; j-- {
+		if u.c != nil && !t.scanBackward(u.c[j], yield) {
+			return false
+		}
+		if j == 0 {
+			return true
+		}
+		if !yield(u.keys[j-1]) {
+			return false
+		}
+	}
+}
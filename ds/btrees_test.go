@@ -0,0 +1,313 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBTree(t *testing.T) {
+	const size = 128 // 9 keys of type uint32 per node
+	f, err := OpenFileBlockStore(filepath.Join(t.TempDir(), "btree"), size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	stores := map[string]BlockStore{
+		"MemBlockStore":  NewMemBlockStore(size),
+		"FileBlockStore": f,
+	}
+	for name, s := range stores {
+		b, err := NewBTree(s, BinaryCodec[uint32]{}, cmp.Compare[uint32])
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if b.b != 5 {
+			t.Errorf("%s: want b = %d, got %d", name, 5, b.b)
+		}
+		if _, ok := b.Find(0); ok {
+			t.Errorf("%s: no element in tree expected", name)
+		}
+		if _, ok := b.Min(); ok {
+			t.Errorf("%s: no element in tree expected", name)
+		}
+		if _, ok := b.Max(); ok {
+			t.Errorf("%s: no element in tree expected", name)
+		}
+		if ok := b.Remove(0); ok {
+			t.Errorf("%s: no element to remove expected", name)
+		}
+
+		const n = 4000
+		r := rand.New(rand.NewSource(1))
+		var e []uint32
+		for k := 0; k < n; k++ {
+			v := uint32(r.Intn(n))
+			i, found := slices.BinarySearch(e, v)
+			if (k < n/2 && r.Intn(4) > 0) || (k >= n/2 && r.Intn(2) == 0) {
+				if ok := b.Add(v); ok == found {
+					t.Fatalf("%s: add %d: want %t, got %t", name, v, !found, ok)
+				}
+				if !found {
+					e = slices.Insert(e, i, v)
+				}
+			} else {
+				if ok := b.Remove(v); ok != found {
+					t.Fatalf("%s: remove %d: want %t, got %t", name, v, found, ok)
+				}
+				if found {
+					e = slices.Delete(e, i, i+1)
+				}
+			}
+			if b.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), b.Len())
+			}
+			if k%100 == 0 {
+				checkBTree(t, b)
+			}
+		}
+		checkBTree(t, b)
+
+		for v := uint32(0); v <= n; v++ {
+			i, _ := slices.BinarySearch(e, v)
+			f, ok := b.Find(v)
+			if i == len(e) {
+				if ok {
+					t.Errorf("%s: find %d: no element expected, got %d", name, v, f)
+				}
+			} else if !ok || f != e[i] {
+				t.Errorf("%s: find %d: want %d, got %d", name, v, e[i], f)
+			}
+		}
+		if v, _ := b.Min(); v != e[0] {
+			t.Errorf("%s: want %d, got %d", name, e[0], v)
+		}
+		if v, _ := b.Max(); v != e[len(e)-1] {
+			t.Errorf("%s: want %d, got %d", name, e[len(e)-1], v)
+		}
+		if a := slices.Collect(b.All()); !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		a := slices.Collect(b.Backward())
+		slices.Reverse(a)
+		if !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		for k := 0; k < 100; k++ {
+			lo := uint32(r.Intn(n + 2))
+			hi := lo + uint32(r.Intn(n/10))
+			i, _ := slices.BinarySearch(e, lo)
+			j, _ := slices.BinarySearch(e, hi)
+			if a := slices.Collect(b.Range(lo, hi)); !slices.Equal(a, e[i:j]) {
+				t.Errorf("%s: range [%d, %d): want %v, got %v", name, lo, hi, e[i:j], a)
+			}
+		}
+
+		for _, v := range e {
+			b.Remove(v)
+		}
+		checkBTree(t, b)
+		if b.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, b.Len())
+		}
+		if err := b.Err(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestBTreeReopen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "btree")
+	s, err := OpenFileBlockStore(name, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBTree(s, BinaryCodec[int64]{}, cmp.Compare[int64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e []int64
+	for i := int64(0); i < 1000; i++ {
+		b.Add(i * 3)
+		e = append(e, i*3)
+	}
+	for i := int64(0); i < 1000; i += 2 {
+		b.Remove(i * 3)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = OpenFileBlockStore(name, 256); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := NewBTree(s, BinaryCodec[int32]{}, cmp.Compare[int32]); err == nil {
+		t.Errorf("want error for keys of a different size")
+	}
+	if b, err = NewBTree(s, BinaryCodec[int64]{}, cmp.Compare[int64]); err != nil {
+		t.Fatal(err)
+	}
+	checkBTree(t, b)
+	var want []int64
+	for i := 1; i < len(e); i += 2 {
+		want = append(want, e[i])
+	}
+	if a := slices.Collect(b.All()); !slices.Equal(a, want) {
+		t.Errorf("want %v, got %v", want, a)
+	}
+
+	// freed blocks are reused
+	m := b.m
+	for i := int64(0); i < 1000; i += 2 {
+		b.Add(i * 3)
+	}
+	if b.m > m {
+		t.Errorf("want at most %d blocks, got %d", m, b.m)
+	}
+	checkBTree(t, b)
+}
+
+func TestBTreeStats(t *testing.T) {
+	b, err := NewBTree(NewMemBlockStore(4096), BinaryCodec[uint64]{}, cmp.Compare[uint64])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 20000; i++ {
+		b.Add(i)
+	}
+	h := 1
+	for u := b.read(b.r); u.c != nil; u = b.read(u.c[0]) {
+		h++
+	}
+	s := b.Stats()
+	b.Find(12345)
+	if d := b.Stats().Reads - s.Reads; d != h {
+		t.Errorf("find: want %d reads, got %d", h, d)
+	}
+	s = b.Stats()
+	n := 0
+	for range b.Range(1000, 2000) {
+		n++
+	}
+	if d := b.Stats().Reads - s.Reads; n != 1000 || d > 2*h+1000/(b.b-1) {
+		t.Errorf("range: want at most %d reads for %d elements, got %d for %d", 2*h+1000/(b.b-1), 1000, d, n)
+	}
+}
+
+func TestBTreeErrors(t *testing.T) {
+	if _, err := NewBTree(NewMemBlockStore(4096), BinaryCodec[int]{}, cmp.Compare[int]); err == nil {
+		t.Errorf("want error for a codec without fixed size")
+	}
+	if _, err := NewBTree(NewMemBlockStore(32), BinaryCodec[uint64]{}, cmp.Compare[uint64]); err == nil {
+		t.Errorf("want error for a too small block size")
+	}
+
+	s := &failingBlockStore{MemBlockStore: *NewMemBlockStore(128)}
+	b, err := NewBTree(s, BinaryCodec[uint32]{}, cmp.Compare[uint32])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint32(0); i < 100; i++ {
+		b.Add(i)
+	}
+	s.err = errors.New("disk on fire")
+	if b.Add(100) {
+		t.Errorf("add: want false, got true")
+	}
+	if !errors.Is(b.Err(), s.err) {
+		t.Errorf("want %v, got %v", s.err, b.Err())
+	}
+	s.err = nil
+	if _, ok := b.Find(1); ok {
+		t.Errorf("find: no element after an error expected")
+	}
+	if b.Remove(1) {
+		t.Errorf("remove: want false, got true")
+	}
+}
+
+// failingBlockStore fails all operations once err is set.
+type failingBlockStore struct {
+	MemBlockStore
+	err error
+}
+
+func (s *failingBlockStore) ReadBlock(i int, b []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemBlockStore.ReadBlock(i, b)
+}
+
+func (s *failingBlockStore) WriteBlock(i int, b []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemBlockStore.WriteBlock(i, b)
+}
+
+// checkBTree checks the order and number
+// of keys of the nodes, the depth of the
+// leaves and the list of free blocks.
+func checkBTree[T cmp.Ordered](t *testing.T, b *BTree[T]) {
+	t.Helper()
+	depth := -1
+	blocks := 1 // the first block
+	var walk func(i, d int, lo, hi *T) int
+	walk = func(i, d int, lo, hi *T) int {
+		blocks++
+		u := b.read(i)
+		if u == nil {
+			t.Fatalf("read %d: %v", i, b.err)
+		}
+		if i != b.r && (len(u.keys) < b.b-1 || len(u.keys) > 2*b.b-1) {
+			t.Fatalf("block %d: want between %d and %d keys, got %d", i, b.b-1, 2*b.b-1, len(u.keys))
+		}
+		for j, v := range u.keys {
+			if (lo != nil && v <= *lo) || (hi != nil && v >= *hi) || (j > 0 && v <= u.keys[j-1]) {
+				t.Fatalf("block %d: %v out of order", i, v)
+			}
+		}
+		if u.c == nil {
+			if depth >= 0 && depth != d {
+				t.Fatalf("block %d: want leaf depth %d, got %d", i, depth, d)
+			}
+			depth = d
+			return len(u.keys)
+		}
+		n := len(u.keys)
+		for j, c := range u.c {
+			l, h := lo, hi
+			if j > 0 {
+				l = &u.keys[j-1]
+			}
+			if j < len(u.keys) {
+				h = &u.keys[j]
+			}
+			n += walk(c, d+1, l, h)
+		}
+		return n
+	}
+	if n := walk(b.r, 0, nil, nil); n != b.n {
+		t.Fatalf("want %d keys, got %d", b.n, n)
+	}
+	for i := b.free; i >= 0; i = int(int64(binary.BigEndian.Uint64(b.buf))) {
+		blocks++
+		if !b.readBlock(i) {
+			t.Fatalf("read %d: %v", i, b.err)
+		}
+	}
+	if blocks != b.m {
+		t.Fatalf("want %d blocks, got %d", b.m, blocks)
+	}
+}
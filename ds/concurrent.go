@@ -0,0 +1,567 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// --- Synchronized -------
+
+// Synchronized guards a data structure by a
+// mutex and makes it safe for concurrent use.
+// SyncList, SyncStack, SyncQueue and SyncDeque
+// embed it to wrap the corresponding interfaces.
+type Synchronized[C any] struct {
+	mu sync.Mutex
+	c  C // wrapped data structure
+}
+
+// NewSynchronized returns a wrapper
+// for the given data structure, which
+// must not be used directly afterwards.
+func NewSynchronized[C any](c C) *Synchronized[C] { return &Synchronized[C]{c: c} }
+
+// Do calls f with the wrapped data
+// structure while holding the lock.
+// It allows for several operations to
+// be performed atomically.
+func (s *Synchronized[C]) Do(f func(c C)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.c)
+}
+
+// SyncList is a list which is
+// safe for concurrent use.
+type SyncList[T any] struct {
+	Synchronized[List[T]]
+}
+
+// NewSyncList returns a wrapper for the given
+// list, which must not be used directly
+// afterwards.
+func NewSyncList[T any](l List[T]) *SyncList[T] {
+	return &SyncList[T]{Synchronized[List[T]]{c: l}}
+}
+
+// Len returns the number
+// of elements in the list.
+func (s *SyncList[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Len()
+}
+
+// Get returns the element at the given
+// index and reports whether it exists.
+func (s *SyncList[T]) Get(i int) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Get(i)
+}
+
+// Set sets the element at the given index
+// and returns the old one.
+func (s *SyncList[T]) Set(i int, v T) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Set(i, v)
+}
+
+// Add adds an element at the given index
+// and reports whether it was successful.
+func (s *SyncList[T]) Add(i int, v T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Add(i, v)
+}
+
+// Remove removes the element at
+// the given index and returns it.
+func (s *SyncList[T]) Remove(i int) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Remove(i)
+}
+
+// SyncStack is a stack which is
+// safe for concurrent use.
+type SyncStack[T any] struct {
+	Synchronized[Stack[T]]
+}
+
+// NewSyncStack returns a wrapper for the
+// given stack, which must not be used
+// directly afterwards.
+func NewSyncStack[T any](st Stack[T]) *SyncStack[T] {
+	return &SyncStack[T]{Synchronized[Stack[T]]{c: st}}
+}
+
+// Len returns the number
+// of elements in the stack.
+func (s *SyncStack[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Len()
+}
+
+// Push pushes an element onto the stack.
+func (s *SyncStack[T]) Push(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Push(v)
+}
+
+// Pop removes and returns the
+// element on top of the stack.
+func (s *SyncStack[T]) Pop() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Pop()
+}
+
+// SyncQueue is a queue which is
+// safe for concurrent use.
+type SyncQueue[T any] struct {
+	Synchronized[Queue[T]]
+}
+
+// NewSyncQueue returns a wrapper for the
+// given queue, which must not be used
+// directly afterwards.
+func NewSyncQueue[T any](q Queue[T]) *SyncQueue[T] {
+	return &SyncQueue[T]{Synchronized[Queue[T]]{c: q}}
+}
+
+// Len returns the number
+// of elements in the queue.
+func (s *SyncQueue[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Len()
+}
+
+// Enqueue adds an element to
+// the tail of the queue.
+func (s *SyncQueue[T]) Enqueue(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Enqueue(v)
+}
+
+// Dequeue removes and returns the
+// element at the head of the queue.
+func (s *SyncQueue[T]) Dequeue() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Dequeue()
+}
+
+// SyncDeque is a deque which is
+// safe for concurrent use.
+type SyncDeque[T any] struct {
+	Synchronized[Deque[T]]
+}
+
+// NewSyncDeque returns a wrapper for the
+// given deque, which must not be used
+// directly afterwards.
+func NewSyncDeque[T any](d Deque[T]) *SyncDeque[T] {
+	return &SyncDeque[T]{Synchronized[Deque[T]]{c: d}}
+}
+
+// Len returns the number
+// of elements in the deque.
+func (s *SyncDeque[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Len()
+}
+
+// AddFirst adds an element to
+// the front of the deque.
+func (s *SyncDeque[T]) AddFirst(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.AddFirst(v)
+}
+
+// AddLast adds an element to
+// the back of the deque.
+func (s *SyncDeque[T]) AddLast(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.AddLast(v)
+}
+
+// RemoveFirst removes and returns the
+// element at the front of the deque.
+func (s *SyncDeque[T]) RemoveFirst() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.RemoveFirst()
+}
+
+// RemoveLast removes and returns the
+// element at the back of the deque.
+func (s *SyncDeque[T]) RemoveLast() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.RemoveLast()
+}
+
+// --- LockFreeQueue -------
+
+// anode represents a node in a singly-linked
+// list whose next pointer is accessed atomically.
+// The value is written before the node is
+// published and never modified afterwards.
+type anode[T any] struct {
+	n atomic.Pointer[anode[T]] // next pointer
+	v T                        // value
+}
+
+// LockFreeQueue is a queue which is safe for
+// concurrent use without locks, as described
+// by Michael and Scott. It is a singly-linked
+// list whose head is a dummy node, which holds
+// the most recently dequeued element.
+type LockFreeQueue[T any] struct {
+	h, t atomic.Pointer[anode[T]] // head and tail pointer
+	n    atomic.Int64             // number of elements
+}
+
+// init lazily initializes the dummy node.
+func (q *LockFreeQueue[T]) init() {
+	h := q.h.Load()
+	if h == nil {
+		q.h.CompareAndSwap(nil, new(anode[T]))
+		h = q.h.Load()
+	}
+	if q.t.Load() == nil {
+		q.t.CompareAndSwap(nil, h)
+	}
+}
+
+// Len returns the number of elements in
+// the queue. It is only a snapshot while
+// other goroutines modify the queue.
+func (q *LockFreeQueue[T]) Len() int { return max(int(q.n.Load()), 0) }
+
+// Enqueue adds an element to
+// the tail of the queue.
+//
+// This operation has a time complexity
+// of O(1) in the absence of contention.
+func (q *LockFreeQueue[T]) Enqueue(v T) {
+	q.init()
+	u := &anode[T]{v: v}
+	for {
+		t := q.t.Load()
+		if n := t.n.Load(); n != nil {
+			// help an unfinished enqueue
+			q.t.CompareAndSwap(t, n)
+			continue
+		}
+		if t.n.CompareAndSwap(nil, u) {
+			q.t.CompareAndSwap(t, u)
+			q.n.Add(1)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the
+// element at the head of the queue.
+//
+// This operation has a time complexity
+// of O(1) in the absence of contention.
+func (q *LockFreeQueue[T]) Dequeue() (T, bool) {
+	q.init()
+	for {
+		h := q.h.Load()
+		n := h.n.Load()
+		if n == nil {
+			return *new(T), false
+		}
+		if t := q.t.Load(); t == h {
+			// the tail lags behind
+			q.t.CompareAndSwap(t, n)
+		}
+		if q.h.CompareAndSwap(h, n) {
+			q.n.Add(-1)
+			return n.v, true
+		}
+	}
+}
+
+// --- LockFreeStack -------
+
+// LockFreeStack is a stack which is safe for
+// concurrent use without locks, as described
+// by Treiber. It is a singly-linked list
+// whose head is swapped atomically.
+type LockFreeStack[T any] struct {
+	h atomic.Pointer[anode[T]] // head pointer
+	n atomic.Int64             // number of elements
+}
+
+// Len returns the number of elements in
+// the stack. It is only a snapshot while
+// other goroutines modify the stack.
+func (s *LockFreeStack[T]) Len() int { return max(int(s.n.Load()), 0) }
+
+// Push pushes an element onto the stack.
+//
+// This operation has a time complexity
+// of O(1) in the absence of contention.
+func (s *LockFreeStack[T]) Push(v T) {
+	u := &anode[T]{v: v}
+	for {
+		h := s.h.Load()
+		u.n.Store(h)
+		if s.h.CompareAndSwap(h, u) {
+			s.n.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes and returns the
+// element on top of the stack.
+//
+// This operation has a time complexity
+// of O(1) in the absence of contention.
+func (s *LockFreeStack[T]) Pop() (T, bool) {
+	for {
+		h := s.h.Load()
+		if h == nil {
+			return *new(T), false
+		}
+		if s.h.CompareAndSwap(h, h.n.Load()) {
+			s.n.Add(-1)
+			return h.v, true
+		}
+	}
+}
+
+// --- BlockingQueue -------
+
+// ErrClosed is returned by the operations of
+// a BlockingQueue or RingBuffer once it is closed.
+var ErrClosed = errors.New("ds: queue closed")
+
+// BlockingQueue is a bounded queue which is
+// safe for concurrent use. It is built on an
+// ArrayQueue. Enqueue blocks while the queue
+// is full and Dequeue blocks while it is empty.
+// The zero value is an empty queue with a
+// capacity of 16.
+type BlockingQueue[T any] struct {
+	mu     sync.Mutex
+	q      ArrayQueue[T] // elements
+	c      int           // capacity
+	closed bool          // whether Close was called
+	ch     chan struct{} // closed when the queue changes
+}
+
+// NewBlockingQueue returns an empty queue with
+// the given capacity, which is at least 1.
+func NewBlockingQueue[T any](c int) *BlockingQueue[T] {
+	return &BlockingQueue[T]{c: max(c, 1)}
+}
+
+// init lazily initializes the capacity.
+func (b *BlockingQueue[T]) init() {
+	if b.c == 0 {
+		b.c = 16
+	}
+}
+
+// Len returns the number
+// of elements in the queue.
+func (b *BlockingQueue[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.q.Len()
+}
+
+// Cap returns the capacity of the queue.
+func (b *BlockingQueue[T]) Cap() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.init()
+	return b.c
+}
+
+// Enqueue adds an element to the tail of
+// the queue. It blocks while the queue is
+// full and returns the error of the context
+// if it is done first, or ErrClosed if the
+// queue is closed.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (b *BlockingQueue[T]) Enqueue(ctx context.Context, v T) error {
+	for {
+		b.mu.Lock()
+		b.init()
+		if b.closed {
+			b.mu.Unlock()
+			return ErrClosed
+		}
+		if b.q.Len() < b.c {
+			b.q.Enqueue(v)
+			b.notify()
+			b.mu.Unlock()
+			return nil
+		}
+		ch := b.wait()
+		b.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Dequeue removes and returns the element
+// at the head of the queue. It blocks while
+// the queue is empty and returns the error
+// of the context if it is done first. Like
+// receiving from a closed channel, the
+// remaining elements of a closed queue are
+// returned before ErrClosed.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (b *BlockingQueue[T]) Dequeue(ctx context.Context) (T, error) {
+	for {
+		b.mu.Lock()
+		if v, ok := b.q.Dequeue(); ok {
+			b.notify()
+			b.mu.Unlock()
+			return v, nil
+		}
+		if b.closed {
+			b.mu.Unlock()
+			return *new(T), ErrClosed
+		}
+		ch := b.wait()
+		b.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return *new(T), ctx.Err()
+		}
+	}
+}
+
+// TryEnqueue adds an element to the tail
+// of the queue without blocking and reports
+// whether it was successful, i.e. the queue
+// was neither full nor closed.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (b *BlockingQueue[T]) TryEnqueue(v T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.init()
+	if b.closed || b.q.Len() >= b.c {
+		return false
+	}
+	b.q.Enqueue(v)
+	b.notify()
+	return true
+}
+
+// TryDequeue removes and returns the element
+// at the head of the queue without blocking.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (b *BlockingQueue[T]) TryDequeue() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.q.Dequeue()
+	if ok {
+		b.notify()
+	}
+	return v, ok
+}
+
+// Peek returns the element at the
+// head of the queue without removing it.
+//
+// This operation has a time complexity of O(1).
+func (b *BlockingQueue[T]) Peek() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.q.Len() == 0 {
+		return *new(T), false
+	}
+	return b.q.s[b.q.r], true
+}
+
+// Drain removes and returns up to n elements
+// from the head of the queue without blocking.
+// If n is negative, all elements are removed.
+//
+// This operation has a time complexity
+// of O(k), where k is the number of
+// removed elements.
+func (b *BlockingQueue[T]) Drain(n int) []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n < 0 || n > b.q.Len() {
+		n = b.q.Len()
+	}
+	s := make([]T, n)
+	for i := range s {
+		s[i], _ = b.q.Dequeue()
+	}
+	if n > 0 {
+		b.notify()
+	}
+	return s
+}
+
+// Close closes the queue and wakes all
+// blocked operations. Subsequent calls to
+// Enqueue fail, while Dequeue returns the
+// remaining elements. Closing a closed
+// queue has no effect.
+func (b *BlockingQueue[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		b.notify()
+	}
+}
+
+// wait returns a channel which is closed on the
+// next change. It must be called with the lock held.
+func (b *BlockingQueue[T]) wait() <-chan struct{} {
+	if b.ch == nil {
+		b.ch = make(chan struct{})
+	}
+	return b.ch
+}
+
+// notify wakes all waiting operations.
+// It must be called with the lock held.
+func (b *BlockingQueue[T]) notify() {
+	if b.ch != nil {
+		close(b.ch)
+		b.ch = nil
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	producers = 4
+	perWorker = 2000
+)
+
+func TestConcurrentQueues(t *testing.T) {
+	queues := map[string]func() Queue[int]{
+		"SyncQueue":     func() Queue[int] { return NewSyncQueue[int](new(ArrayQueue[int])) },
+		"LockFreeQueue": func() Queue[int] { return new(LockFreeQueue[int]) },
+	}
+	for name, newQueue := range queues {
+		q := newQueue()
+		if _, ok := q.Dequeue(); ok {
+			t.Errorf("%s: no element in queue expected", name)
+		}
+		for i := 0; i < 10; i++ {
+			q.Enqueue(i)
+		}
+		for i := 0; i < 10; i++ {
+			if v, ok := q.Dequeue(); !ok || v != i {
+				t.Errorf("%s: want %d, got %d", name, i, v)
+			}
+		}
+
+		// the elements of each producer are dequeued in order
+		var wg sync.WaitGroup
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					q.Enqueue(p*perWorker + i)
+				}
+			}(p)
+		}
+		res := make([][]int, producers)
+		for c := 0; c < producers; c++ {
+			wg.Add(1)
+			go func(c int) {
+				defer wg.Done()
+				for len(res[c]) < perWorker {
+					if v, ok := q.Dequeue(); ok {
+						res[c] = append(res[c], v)
+					}
+				}
+			}(c)
+		}
+		wg.Wait()
+		seen := make([]bool, producers*perWorker)
+		for _, r := range res {
+			last := make([]int, producers)
+			for p := range last {
+				last[p] = -1
+			}
+			for _, v := range r {
+				if seen[v] {
+					t.Fatalf("%s: %d dequeued twice", name, v)
+				}
+				seen[v] = true
+				if p := v / perWorker; v <= last[p] {
+					t.Fatalf("%s: %d dequeued after %d", name, v, last[p])
+				} else {
+					last[p] = v
+				}
+			}
+		}
+		if q.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, q.Len())
+		}
+	}
+}
+
+func TestConcurrentStacks(t *testing.T) {
+	stacks := map[string]func() Stack[int]{
+		"SyncStack":     func() Stack[int] { return NewSyncStack[int](new(ArrayStack[int])) },
+		"LockFreeStack": func() Stack[int] { return new(LockFreeStack[int]) },
+	}
+	for name, newStack := range stacks {
+		s := newStack()
+		if _, ok := s.Pop(); ok {
+			t.Errorf("%s: no element on stack expected", name)
+		}
+		for i := 0; i < 10; i++ {
+			s.Push(i)
+		}
+		for i := 9; i >= 0; i-- {
+			if v, ok := s.Pop(); !ok || v != i {
+				t.Errorf("%s: want %d, got %d", name, i, v)
+			}
+		}
+
+		var wg sync.WaitGroup
+		res := make([][]int, producers)
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					s.Push(p*perWorker + i)
+					if v, ok := s.Pop(); ok {
+						res[p] = append(res[p], v)
+					}
+				}
+			}(p)
+		}
+		wg.Wait()
+		seen := make([]bool, producers*perWorker)
+		n := 0
+		for _, r := range res {
+			for _, v := range r {
+				if seen[v] {
+					t.Fatalf("%s: %d popped twice", name, v)
+				}
+				seen[v] = true
+				n++
+			}
+		}
+		if n+s.Len() != producers*perWorker {
+			t.Errorf("%s: want %d, got %d", name, producers*perWorker, n+s.Len())
+		}
+	}
+}
+
+func TestSynchronized(t *testing.T) {
+	l := NewSyncList[int](new(Array[int]))
+	d := NewSyncDeque[int](new(Dequeue[int]))
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				l.Add(l.Len()/2, i)
+				d.AddFirst(i)
+				d.AddLast(i)
+				d.RemoveFirst()
+			}
+		}()
+	}
+	wg.Wait()
+	if l.Len() != producers*perWorker {
+		t.Errorf("want %d, got %d", producers*perWorker, l.Len())
+	}
+	if d.Len() != producers*perWorker {
+		t.Errorf("want %d, got %d", producers*perWorker, d.Len())
+	}
+
+	// Do performs several operations atomically
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				l.Do(func(l List[int]) {
+					v, _ := l.Remove(l.Len() - 1)
+					l.Add(0, v)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if l.Len() != producers*perWorker {
+		t.Errorf("want %d, got %d", producers*perWorker, l.Len())
+	}
+}
+
+func TestBlockingQueue(t *testing.T) {
+	ctx := context.Background()
+	var z BlockingQueue[int]
+	if z.Cap() != 16 {
+		t.Errorf("want %d, got %d", 16, z.Cap())
+	}
+
+	b := NewBlockingQueue[int](3)
+	if _, ok := b.Peek(); ok {
+		t.Errorf("no element in queue expected")
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Enqueue(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.TryEnqueue(3) {
+		t.Errorf("try enqueue into a full queue: want false, got true")
+	}
+	c, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := b.Enqueue(c, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if v, ok := b.Peek(); !ok || v != 0 {
+		t.Errorf("want %d, got %d", 0, v)
+	}
+	if s := b.Drain(2); !slices.Equal(s, []int{0, 1}) {
+		t.Errorf("want %v, got %v", []int{0, 1}, s)
+	}
+	if s := b.Drain(-1); !slices.Equal(s, []int{2}) {
+		t.Errorf("want %v, got %v", []int{2}, s)
+	}
+	if _, ok := b.TryDequeue(); ok {
+		t.Errorf("no element in queue expected")
+	}
+	c, cancel = context.WithCancel(ctx)
+	cancel()
+	if _, err := b.Dequeue(c); !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+
+	// a blocked Enqueue is woken by Dequeue
+	for i := 0; i < 3; i++ {
+		b.TryEnqueue(i)
+	}
+	done := make(chan error)
+	go func() { done <- b.Enqueue(ctx, 3) }()
+	if v, err := b.Dequeue(ctx); err != nil || v != 0 {
+		t.Errorf("want %d, got %d (%v)", 0, v, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Close wakes a blocked Enqueue, but the elements remain
+	go func() { done <- b.Enqueue(ctx, 4) }()
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+	b.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("want %v, got %v", ErrClosed, err)
+	}
+	for i := 1; i <= 3; i++ {
+		if v, err := b.Dequeue(ctx); err != nil || v != i {
+			t.Errorf("want %d, got %d (%v)", i, v, err)
+		}
+	}
+	if _, err := b.Dequeue(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("want %v, got %v", ErrClosed, err)
+	}
+	if b.TryEnqueue(5) {
+		t.Errorf("try enqueue into a closed queue: want false, got true")
+	}
+
+	// Close wakes a blocked Dequeue
+	b = NewBlockingQueue[int](1)
+	go func() {
+		_, err := b.Dequeue(ctx)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("want %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestBlockingQueueConcurrent(t *testing.T) {
+	ctx := context.Background()
+	b := NewBlockingQueue[int](8)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := b.Enqueue(ctx, p*perWorker+i); err != nil {
+					t.Error(err)
+					return
+				}
+				if b.Len() > b.Cap() {
+					t.Errorf("want at most %d, got %d", b.Cap(), b.Len())
+				}
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		b.Close()
+	}()
+	res := make(chan []int)
+	for c := 0; c < producers; c++ {
+		go func() {
+			var r []int
+			for {
+				v, err := b.Dequeue(ctx)
+				if err != nil {
+					res <- r
+					return
+				}
+				r = append(r, v)
+			}
+		}()
+	}
+	seen := make([]bool, producers*perWorker)
+	for c := 0; c < producers; c++ {
+		for _, v := range <-res {
+			if seen[v] {
+				t.Fatalf("%d dequeued twice", v)
+			}
+			seen[v] = true
+		}
+	}
+	for v, ok := range seen {
+		if !ok {
+			t.Fatalf("%d not dequeued", v)
+		}
+	}
+}
+
+func BenchmarkConcurrentQueues(b *testing.B) {
+	b.Run("Channel", func(b *testing.B) {
+		c := make(chan int, 1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c <- 1
+				<-c
+			}
+		})
+	})
+	queues := map[string]func() Queue[int]{
+		"SyncQueue":     func() Queue[int] { return NewSyncQueue[int](new(ArrayQueue[int])) },
+		"LockFreeQueue": func() Queue[int] { return new(LockFreeQueue[int]) },
+	}
+	for name, newQueue := range queues {
+		b.Run(name, func(b *testing.B) {
+			q := newQueue()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enqueue(1)
+					q.Dequeue()
+				}
+			})
+		})
+	}
+	b.Run("BlockingQueue", func(b *testing.B) {
+		ctx := context.Background()
+		q := NewBlockingQueue[int](1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Enqueue(ctx, 1)
+				q.Dequeue(ctx)
+			}
+		})
+	})
+}
+
+func BenchmarkConcurrentStacks(b *testing.B) {
+	stacks := map[string]func() Stack[int]{
+		"SyncStack":     func() Stack[int] { return NewSyncStack[int](new(ArrayStack[int])) },
+		"LockFreeStack": func() Stack[int] { return new(LockFreeStack[int]) },
+	}
+	for name, newStack := range stacks {
+		b.Run(name, func(b *testing.B) {
+			s := newStack()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.Push(1)
+					s.Pop()
+				}
+			})
+		})
+	}
+}
@@ -4,10 +4,189 @@
 
 // Package ds contains the implementations
 // of the data structures from
+//
 //	http://opendatastructures.org.
+//
+// All data structures are parameterized by
+// the type of their elements, e.g. Array[int]
+// or DList[string]. Code written against the
+// former untyped data structures migrates by
+// instantiating them with V, e.g. Array[V],
+// which behaves exactly like the former Array.
+//
 // It is not recommended to use this package.
 package ds
 
-// V represents a value
-// stored in a data structure.
+import "iter"
+
+// V represents an arbitrary value
+// stored in a data structure. It is
+// the type argument for code which
+// relies on untyped data structures.
 type V interface{}
+
+// List is a sequence of elements
+// which are accessed by their index.
+type List[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Get returns the element at the given index.
+	Get(i int) (T, bool)
+	// Set sets the element at the given
+	// index and returns the old one.
+	Set(i int, v T) (T, bool)
+	// Add adds an element at the given index
+	// and reports whether it was successful.
+	Add(i int, v T) bool
+	// Remove removes the element at the
+	// given index and returns it.
+	Remove(i int) (T, bool)
+}
+
+// Stack is a last-in-first-out sequence.
+type Stack[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Push adds an element on top.
+	Push(v T)
+	// Pop removes and returns
+	// the element on top.
+	Pop() (T, bool)
+}
+
+// Queue is a first-in-first-out sequence.
+type Queue[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Enqueue adds an element at the tail.
+	Enqueue(v T)
+	// Dequeue removes and returns
+	// the element at the head.
+	Dequeue() (T, bool)
+}
+
+// Deque is a sequence which allows for
+// addition and removal at both ends.
+type Deque[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// AddFirst adds an element at the front.
+	AddFirst(v T)
+	// AddLast adds an element at the back.
+	AddLast(v T)
+	// RemoveFirst removes and returns
+	// the element at the front.
+	RemoveFirst() (T, bool)
+	// RemoveLast removes and returns
+	// the element at the back.
+	RemoveLast() (T, bool)
+}
+
+// SSet is a sorted set.
+type SSet[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Add adds an element and reports
+	// whether it was not yet contained.
+	Add(v T) bool
+	// Remove removes an element and
+	// reports whether it was contained.
+	Remove(v T) bool
+	// Find returns the smallest element
+	// which is greater than or equal to v.
+	Find(v T) (T, bool)
+	// Min returns the smallest element.
+	Min() (T, bool)
+	// Max returns the largest element.
+	Max() (T, bool)
+	// All returns an iterator over the
+	// elements, in ascending order.
+	All() iter.Seq[T]
+	// Backward returns an iterator over
+	// the elements, in descending order.
+	Backward() iter.Seq[T]
+}
+
+// PriorityQueue is a queue which
+// removes its elements in ascending
+// order.
+type PriorityQueue[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Add adds an element.
+	Add(v T)
+	// Remove removes and returns
+	// the smallest element.
+	Remove() (T, bool)
+	// Peek returns the smallest element.
+	Peek() (T, bool)
+}
+
+// Graph is a directed graph with
+// the vertices 0, ..., Len()-1.
+type Graph interface {
+	// Len returns the number of vertices.
+	Len() int
+	// Edges returns the number of edges.
+	Edges() int
+	// AddEdge adds the edge (i, j).
+	AddEdge(i, j int) bool
+	// RemoveEdge removes the edge (i, j).
+	RemoveEdge(i, j int) bool
+	// HasEdge reports whether
+	// the edge (i, j) exists.
+	HasEdge(i, j int) bool
+	// OutEdges returns an iterator over
+	// all j such that (i, j) exists.
+	OutEdges(i int) iter.Seq[int]
+	// InEdges returns an iterator over
+	// all j such that (j, i) exists.
+	InEdges(i int) iter.Seq[int]
+}
+
+var (
+	_ List[V] = (*Array[V])(nil)
+	_ List[V] = (*Dequeue[V])(nil)
+	_ List[V] = (*DualDequeue[V])(nil)
+	_ List[V] = (*RootishStack[V])(nil)
+	_ List[V] = (*DList[V])(nil)
+	_ List[V] = (*SEList[V])(nil)
+	_ List[V] = (*SkiplistList[V])(nil)
+	_ List[V] = (*SyncList[V])(nil)
+
+	_ Stack[V] = (*ArrayStack[V])(nil)
+	_ Stack[V] = (*SList[V])(nil)
+	_ Stack[V] = (*SyncStack[V])(nil)
+	_ Stack[V] = (*LockFreeStack[V])(nil)
+
+	_ Queue[V] = (*ArrayQueue[V])(nil)
+	_ Queue[V] = (*SList[V])(nil)
+	_ Queue[V] = (*SyncQueue[V])(nil)
+	_ Queue[V] = (*LockFreeQueue[V])(nil)
+
+	_ Deque[V] = (*Dequeue[V])(nil)
+	_ Deque[V] = (*DualDequeue[V])(nil)
+	_ Deque[V] = (*DList[V])(nil)
+	_ Deque[V] = (*SEList[V])(nil)
+	_ Deque[V] = (*SyncDeque[V])(nil)
+
+	_ SSet[V]      = (*SkiplistSSet[V])(nil)
+	_ SSet[V]      = (*BinarySearchTree[V])(nil)
+	_ SSet[V]      = (*Treap[V])(nil)
+	_ SSet[V]      = (*ScapegoatTree[V])(nil)
+	_ SSet[V]      = (*RedBlackTree[V])(nil)
+	_ SSet[uint64] = (*BinaryTrie[uint64])(nil)
+	_ SSet[uint64] = (*XFastTrie[uint64])(nil)
+	_ SSet[uint64] = (*YFastTrie[uint64])(nil)
+	_ SSet[V]      = (*BTree[V])(nil)
+
+	_ PriorityQueue[V] = (*BinaryHeap[V])(nil)
+	_ PriorityQueue[V] = (*MeldableHeap[V])(nil)
+
+	_ Graph = (*AdjacencyMatrix)(nil)
+	_ Graph = (*AdjacencyLists)(nil)
+
+	_ BlockStore = (*MemBlockStore)(nil)
+	_ BlockStore = (*FileBlockStore)(nil)
+	_ Codec[V]   = BinaryCodec[V]{}
+)
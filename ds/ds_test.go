@@ -0,0 +1,423 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	const n = 65
+	lists := map[string]func() List[int]{
+		"Array":        func() List[int] { return new(Array[int]) },
+		"Dequeue":      func() List[int] { return new(Dequeue[int]) },
+		"DualDequeue":  func() List[int] { return new(DualDequeue[int]) },
+		"RootishStack": func() List[int] { return new(RootishStack[int]) },
+		"DList":        func() List[int] { return new(DList[int]) },
+		"SEList":       func() List[int] { return NewSEList[int](3) },
+		"SkiplistList": func() List[int] { return new(SkiplistList[int]) },
+	}
+	for name, newList := range lists {
+		l := newList()
+		for i := 0; i < n; i++ {
+			if ok := l.Add(i/2, i); !ok {
+				t.Errorf("%s: cannot add: %d", name, i)
+			}
+		}
+		if l.Len() != n {
+			t.Errorf("%s: want %d, got %d", name, n, l.Len())
+		}
+		var e []int
+		for i := 0; i < n; i++ {
+			e = append(e[:i/2], append([]int{i}, e[i/2:]...)...)
+		}
+		for i, x := range e {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Errorf("%s: want %d, got %d", name, x, v)
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			v, ok := l.Remove(i / 2)
+			if x := e[i/2]; !ok || v != x {
+				t.Errorf("%s: want %d, got %d", name, x, v)
+			}
+			e = append(e[:i/2], e[i/2+1:]...)
+		}
+		if l.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, l.Len())
+		}
+	}
+}
+
+// bulkList is a list with bulk operations.
+type bulkList[T any] interface {
+	List[T]
+	AddAll(i int, vs ...T) bool
+	RemoveRange(f, t int) bool
+	Slice(f, t int) ([]T, bool)
+	Reverse()
+	Clear()
+}
+
+func TestBulk(t *testing.T) {
+	lists := map[string]func() bulkList[int]{
+		"Array":        func() bulkList[int] { return new(Array[int]) },
+		"Dequeue":      func() bulkList[int] { return new(Dequeue[int]) },
+		"DualDequeue":  func() bulkList[int] { return new(DualDequeue[int]) },
+		"RootishStack": func() bulkList[int] { return new(RootishStack[int]) },
+		"DList":        func() bulkList[int] { return new(DList[int]) },
+	}
+	for name, newList := range lists {
+		l := newList()
+		if ok := l.AddAll(1, 1); ok {
+			t.Errorf("%s: add at index 1 of empty list: want false, got true", name)
+		}
+		if ok := l.RemoveRange(0, 1); ok {
+			t.Errorf("%s: remove [0, 1) of empty list: want false, got true", name)
+		}
+		if _, ok := l.Slice(1, 0); ok {
+			t.Errorf("%s: slice [1, 0): want false, got true", name)
+		}
+		r := rand.New(rand.NewSource(1))
+		var e []int
+		for k := 0; k < 2000; k++ {
+			switch i, j := r.Intn(len(e)+1), r.Intn(len(e)+1); r.Intn(5) {
+			case 0, 1:
+				vs := make([]int, r.Intn(20))
+				for m := range vs {
+					vs[m] = k*20 + m
+				}
+				if ok := l.AddAll(i, vs...); !ok {
+					t.Fatalf("%s: cannot add %d elements at %d", name, len(vs), i)
+				}
+				e = slices.Insert(e, i, vs...)
+			case 2:
+				i, j = min(i, j), max(i, j)
+				if ok := l.RemoveRange(i, j); !ok {
+					t.Fatalf("%s: cannot remove [%d, %d)", name, i, j)
+				}
+				e = slices.Delete(e, i, j)
+			case 3:
+				i, j = min(i, j), max(i, j)
+				if s, ok := l.Slice(i, j); !ok || !slices.Equal(s, e[i:j]) {
+					t.Fatalf("%s: slice [%d, %d): want %v, got %v", name, i, j, e[i:j], s)
+				}
+			case 4:
+				l.Reverse()
+				slices.Reverse(e)
+			}
+			if l.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), l.Len())
+			}
+		}
+		for i, x := range e {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Fatalf("%s: index %d: want %d, got %d", name, i, x, v)
+			}
+		}
+		l.Clear()
+		if l.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, l.Len())
+		}
+		if ok := l.AddAll(0, 1, 2, 3); !ok {
+			t.Errorf("%s: cannot add after clear", name)
+		}
+		if s, _ := l.Slice(0, l.Len()); !slices.Equal(s, []int{1, 2, 3}) {
+			t.Errorf("%s: want %v, got %v", name, []int{1, 2, 3}, s)
+		}
+	}
+}
+
+func TestBulkResize(t *testing.T) {
+	const n = 1000
+	vs := make([]int, n)
+	var a Array[int]
+	a.AddAll(0, vs...)
+	if len(a.s) != 2*n {
+		t.Errorf("array: want capacity %d, got %d", 2*n, len(a.s))
+	}
+	a.RemoveRange(10, n)
+	if len(a.s) != 20 {
+		t.Errorf("array: want capacity %d, got %d", 20, len(a.s))
+	}
+	var d Dequeue[int]
+	d.AddAll(0, vs...)
+	if len(d.s) != 2*n {
+		t.Errorf("dequeue: want capacity %d, got %d", 2*n, len(d.s))
+	}
+	d.RemoveRange(0, n-10)
+	if len(d.s) != 20 {
+		t.Errorf("dequeue: want capacity %d, got %d", 20, len(d.s))
+	}
+}
+
+func TestDeque(t *testing.T) {
+	const n = 65
+	deques := map[string]func() Deque[int]{
+		"Dequeue":     func() Deque[int] { return new(Dequeue[int]) },
+		"DualDequeue": func() Deque[int] { return new(DualDequeue[int]) },
+		"DList":       func() Deque[int] { return new(DList[int]) },
+		"SEList":      func() Deque[int] { return NewSEList[int](3) },
+	}
+	for name, newDeque := range deques {
+		d := newDeque()
+		for i := 0; i < n; i++ {
+			d.AddFirst(-i)
+			d.AddLast(i)
+		}
+		if d.Len() != 2*n {
+			t.Errorf("%s: want %d, got %d", name, 2*n, d.Len())
+		}
+		for i := n - 1; i >= 0; i-- {
+			if v, ok := d.RemoveFirst(); !ok || v != -i {
+				t.Errorf("%s: want %d, got %d", name, -i, v)
+			}
+			if v, ok := d.RemoveLast(); !ok || v != i {
+				t.Errorf("%s: want %d, got %d", name, i, v)
+			}
+		}
+		if _, ok := d.RemoveFirst(); ok {
+			t.Errorf("%s: no element in deque expected", name)
+		}
+		if _, ok := d.RemoveLast(); ok {
+			t.Errorf("%s: no element in deque expected", name)
+		}
+	}
+}
+
+type iterable[T any] interface {
+	All() iter.Seq2[int, T]
+	Backward() iter.Seq2[int, T]
+}
+
+func TestIter(t *testing.T) {
+	const n = 65
+	var (
+		a  ArrayStack[int]
+		q  ArrayQueue[int]
+		sl SList[int]
+	)
+	iterables := map[string]iterable[int]{"ArrayStack": &a, "ArrayQueue": &q, "SList": &sl}
+	for i := 0; i < n; i++ {
+		a.Push(i)
+		q.Enqueue(i)
+		sl.Enqueue(i)
+	}
+	for name, l := range map[string]List[int]{
+		"Array":        new(Array[int]),
+		"Dequeue":      new(Dequeue[int]),
+		"DualDequeue":  new(DualDequeue[int]),
+		"RootishStack": new(RootishStack[int]),
+		"DList":        new(DList[int]),
+		"SEList":       NewSEList[int](3),
+		"SkiplistList": new(SkiplistList[int]),
+	} {
+		for i := 0; i < n; i++ {
+			l.Add(i, i)
+		}
+		iterables[name] = l.(iterable[int])
+	}
+
+	for name, it := range iterables {
+		j := 0
+		for i, v := range it.All() {
+			if i != j || v != j {
+				t.Errorf("%s: want (%d, %d), got (%d, %d)", name, j, j, i, v)
+			}
+			j++
+		}
+		if j != n {
+			t.Errorf("%s: want %d, got %d", name, n, j)
+		}
+
+		j = n - 1
+		for i, v := range it.Backward() {
+			if i != j || v != j {
+				t.Errorf("%s: want (%d, %d), got (%d, %d)", name, j, j, i, v)
+			}
+			j--
+		}
+		if j != -1 {
+			t.Errorf("%s: want %d, got %d", name, -1, j)
+		}
+
+		for i := range it.All() {
+			if i == n/2 {
+				break
+			}
+		}
+	}
+}
+
+func TestIterShrink(t *testing.T) {
+	var a Array[int]
+	for i := 0; i < 10; i++ {
+		a.Add(i, i)
+	}
+	// the iteration stops once the index is out of range
+	var s []int
+	for i, v := range a.Backward() {
+		s = append(s, v)
+		if i == 8 {
+			a.RemoveRange(0, 5)
+		}
+	}
+	if want := []int{9, 8}; !slices.Equal(s, want) {
+		t.Errorf("want %v, got %v", want, s)
+	}
+}
+
+// --- Benchmarks -------
+
+// The benchmarks measure the operations of the lists, deques and
+// queues at the front, in the middle, at the back and at random
+// indices for several sizes. Each operation which changes the size
+// is paired with an operation at the back, which takes constant
+// time in all lists, to keep the size; Enqueue is paired with
+// Dequeue. The bulk operations add and remove benchBulk elements.
+// The bench command prints them as table.
+
+var (
+	benchSizes    = []int{1 << 8, 1 << 12, 1 << 16}
+	benchPatterns = []string{"front", "middle", "back", "random"}
+	benchBulk     = make([]int, 16)
+	benchLists    = []struct {
+		name string
+		new  func() any
+	}{
+		{"Array", func() any { return new(Array[int]) }},
+		{"Dequeue", func() any { return new(Dequeue[int]) }},
+		{"DualDequeue", func() any { return new(DualDequeue[int]) }},
+		{"RootishStack", func() any { return new(RootishStack[int]) }},
+		{"DList", func() any { return new(DList[int]) }},
+		{"ArrayQueue", func() any { return new(ArrayQueue[int]) }},
+		{"SList", func() any { return new(SList[int]) }},
+	}
+)
+
+// benchIndices returns indices of a list with
+// n elements for the given access pattern.
+func benchIndices(p string, n int) []int {
+	is := make([]int, 1024)
+	r := rand.New(rand.NewSource(1))
+	for k := range is {
+		switch p {
+		case "middle":
+			is[k] = n / 2
+		case "back":
+			is[k] = n - 1
+		case "random":
+			is[k] = r.Intn(n)
+		}
+	}
+	return is
+}
+
+// bench runs f on all data structures of type C
+// for the given access patterns and all sizes,
+// where i is the index and k the iteration.
+func bench[C any](b *testing.B, patterns []string, f func(c C, i, k int)) {
+	for _, bl := range benchLists {
+		if _, ok := bl.new().(C); !ok {
+			continue
+		}
+		for _, p := range patterns {
+			for _, n := range benchSizes {
+				b.Run(fmt.Sprintf("%s/%s/n=%d", bl.name, p, n), func(b *testing.B) {
+					c := bl.new()
+					for i := 0; i < n; i++ {
+						switch c := c.(type) {
+						case List[int]:
+							c.Add(i, i)
+						case Queue[int]:
+							c.Enqueue(i)
+						}
+					}
+					is := benchIndices(p, n)
+					b.ResetTimer()
+					for k := 0; k < b.N; k++ {
+						f(c.(C), is[k%len(is)], k)
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	bench(b, benchPatterns, func(l List[int], i, _ int) { l.Get(i) })
+}
+
+func BenchmarkSet(b *testing.B) {
+	bench(b, benchPatterns, func(l List[int], i, k int) { l.Set(i, k) })
+}
+
+func BenchmarkAdd(b *testing.B) {
+	bench(b, benchPatterns, func(l List[int], i, k int) {
+		l.Add(i, k)
+		l.Remove(l.Len() - 1)
+	})
+}
+
+func BenchmarkRemove(b *testing.B) {
+	bench(b, benchPatterns, func(l List[int], i, k int) {
+		l.Remove(i)
+		l.Add(l.Len(), k)
+	})
+}
+
+func BenchmarkAddFirst(b *testing.B) {
+	bench(b, []string{"front"}, func(d Deque[int], _, k int) {
+		d.AddFirst(k)
+		d.RemoveLast()
+	})
+}
+
+func BenchmarkAddLast(b *testing.B) {
+	bench(b, []string{"back"}, func(d Deque[int], _, k int) {
+		d.AddLast(k)
+		d.RemoveLast()
+	})
+}
+
+func BenchmarkRemoveFirst(b *testing.B) {
+	bench(b, []string{"front"}, func(d Deque[int], _, k int) {
+		d.RemoveFirst()
+		d.AddLast(k)
+	})
+}
+
+func BenchmarkRemoveLast(b *testing.B) {
+	bench(b, []string{"back"}, func(d Deque[int], _, k int) {
+		d.RemoveLast()
+		d.AddLast(k)
+	})
+}
+
+func BenchmarkEnqueueDequeue(b *testing.B) {
+	bench(b, []string{"back"}, func(q Queue[int], _, k int) {
+		q.Enqueue(k)
+		q.Dequeue()
+	})
+}
+
+func BenchmarkAddAll(b *testing.B) {
+	bench(b, benchPatterns, func(l bulkList[int], i, _ int) {
+		l.AddAll(i, benchBulk...)
+		l.RemoveRange(l.Len()-len(benchBulk), l.Len())
+	})
+}
+
+func BenchmarkRemoveRange(b *testing.B) {
+	bench(b, benchPatterns, func(l bulkList[int], i, _ int) {
+		i = min(i, l.Len()-len(benchBulk))
+		l.RemoveRange(i, i+len(benchBulk))
+		l.AddAll(l.Len(), benchBulk...)
+	})
+}
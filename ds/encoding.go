@@ -0,0 +1,1597 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// The data structures encode their elements in order,
+// as JSON array, as gob-encoded slice or in a binary
+// format. The binary format consists of the number of
+// elements as uvarint followed by the elements:
+//
+//   - integers of type int or uint as varint or uvarint,
+//   - strings as uvarint length followed by the bytes,
+//   - types implementing encoding.BinaryMarshaler (and
+//     encoding.BinaryUnmarshaler on their pointers) as
+//     uvarint length followed by their encoding,
+//   - other fixed-size types as by encoding/binary in
+//     big-endian byte order.
+//
+// Hash tables encode their entries as pairs of key and
+// value: as JSON array of objects with the fields Key
+// and Value, as gob-encoded slice of such pairs, or in
+// the binary format as the keys followed by the values.
+//
+// Decoding replaces the elements of a data structure.
+// Sorted sets and priority queues must have been
+// created with a comparison function beforehand,
+// and the synchronized wrappers with the data
+// structure they wrap.
+
+// errNoCmp is returned when decoding into a sorted
+// set or priority queue without comparison function.
+var errNoCmp = errors.New("ds: cannot decode into a data structure without comparison function")
+
+// errUnwrapped is returned when decoding into
+// a synchronized wrapper without data structure.
+var errUnwrapped = errors.New("ds: cannot decode into a synchronized wrapper without data structure")
+
+// errBinary is returned when decoding invalid data.
+var errBinary = errors.New("ds: invalid binary encoding")
+
+// elements returns an iterator
+// over the elements of seq.
+func elements[T any](seq iter.Seq2[int, T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// decode decodes b by f and
+// replaces the elements by set.
+func decode[T any](b []byte, f func([]byte) ([]T, error), set func(vs []T)) error {
+	vs, err := f(b)
+	if err != nil {
+		return err
+	}
+	set(vs)
+	return nil
+}
+
+// replaceSet replaces the elements of s by vs.
+func replaceSet[T any](s SSet[T], vs []T) {
+	for _, v := range slices.Collect(s.All()) {
+		s.Remove(v)
+	}
+	for _, v := range vs {
+		s.Add(v)
+	}
+}
+
+// replaceHeap replaces the elements of h by vs.
+func replaceHeap[T any](h PriorityQueue[T], vs []T) {
+	for h.Len() > 0 {
+		h.Remove()
+	}
+	for _, v := range vs {
+		h.Add(v)
+	}
+}
+
+func marshalJSON[T any](seq iter.Seq[T]) ([]byte, error) {
+	b, err := json.Marshal(collectAll(seq))
+	if err != nil {
+		return nil, fmt.Errorf("ds: cannot encode elements of type %v: %w", reflect.TypeFor[T](), err)
+	}
+	return b, nil
+}
+
+func unmarshalJSON[T any](b []byte) ([]T, error) {
+	var vs []T
+	if err := json.Unmarshal(b, &vs); err != nil {
+		return nil, fmt.Errorf("ds: cannot decode elements of type %v: %w", reflect.TypeFor[T](), err)
+	}
+	return vs, nil
+}
+
+func gobEncode[T any](seq iter.Seq[T]) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(collectAll(seq)); err != nil {
+		return nil, fmt.Errorf("ds: cannot encode elements of type %v: %w", reflect.TypeFor[T](), err)
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode[T any](b []byte) ([]T, error) {
+	var vs []T
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&vs); err != nil {
+		return nil, fmt.Errorf("ds: cannot decode elements of type %v: %w", reflect.TypeFor[T](), err)
+	}
+	return vs, nil
+}
+
+// collectAll is like slices.Collect, but never returns
+// nil, such that an empty sequence is encoded as such.
+func collectAll[T any](seq iter.Seq[T]) []T {
+	vs := []T{}
+	for v := range seq {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// binaryKind is the binary encoding of an element type.
+type binaryKind int
+
+const (
+	binaryFixed binaryKind = iota
+	binaryInt
+	binaryUint
+	binaryString
+	binaryMarshaler
+)
+
+var (
+	marshalerType   = reflect.TypeFor[encoding.BinaryMarshaler]()
+	unmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// binaryKindOf returns the binary encoding of the
+// type T or an error if T cannot be encoded.
+func binaryKindOf[T any]() (binaryKind, error) {
+	t := reflect.TypeFor[T]()
+	switch {
+	case t.Implements(marshalerType) && reflect.PointerTo(t).Implements(unmarshalerType):
+		return binaryMarshaler, nil
+	case t.Kind() == reflect.Int:
+		return binaryInt, nil
+	case t.Kind() == reflect.Uint || t.Kind() == reflect.Uintptr:
+		return binaryUint, nil
+	case t.Kind() == reflect.String:
+		return binaryString, nil
+	case t.Kind() != reflect.Interface && binary.Size(*new(T)) >= 0:
+		return binaryFixed, nil
+	}
+	return 0, fmt.Errorf("ds: elements of type %v have no binary encoding; use JSON or gob instead", t)
+}
+
+func marshalBinary[T any](seq iter.Seq[T]) ([]byte, error) {
+	k, err := binaryKindOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	vs := collectAll(seq)
+	b := binary.AppendUvarint(nil, uint64(len(vs)))
+	for _, v := range vs {
+		switch k {
+		case binaryInt:
+			b = binary.AppendVarint(b, reflect.ValueOf(v).Int())
+		case binaryUint:
+			b = binary.AppendUvarint(b, reflect.ValueOf(v).Uint())
+		case binaryString:
+			s := reflect.ValueOf(v).String()
+			b = append(binary.AppendUvarint(b, uint64(len(s))), s...)
+		case binaryMarshaler:
+			p, err := any(v).(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("ds: cannot encode element: %w", err)
+			}
+			b = append(binary.AppendUvarint(b, uint64(len(p))), p...)
+		default:
+			if b, err = binary.Append(b, binary.BigEndian, v); err != nil {
+				return nil, fmt.Errorf("ds: cannot encode element: %w", err)
+			}
+		}
+	}
+	return b, nil
+}
+
+func unmarshalBinary[T any](b []byte) ([]T, error) {
+	vs, b, err := readBinary[T](b)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		return nil, errBinary
+	}
+	return vs, nil
+}
+
+// readBinary decodes the elements at the beginning
+// of b and returns them and the remaining bytes.
+func readBinary[T any](b []byte) ([]T, []byte, error) {
+	k, err := binaryKindOf[T]()
+	if err != nil {
+		return nil, nil, err
+	}
+	// each element takes at least one byte,
+	// unless it is of a zero-size type
+	zero := k == binaryFixed && binary.Size(*new(T)) == 0
+	n, c := binary.Uvarint(b)
+	if c <= 0 || (!zero && n > uint64(len(b))) || (zero && n > math.MaxInt32) {
+		return nil, nil, errBinary
+	}
+	b = b[c:]
+	vs := make([]T, n)
+	if zero {
+		return vs, b, nil
+	}
+	for i := range vs {
+		switch k {
+		case binaryInt:
+			x, c := binary.Varint(b)
+			if c <= 0 {
+				return nil, nil, errBinary
+			}
+			reflect.ValueOf(&vs[i]).Elem().SetInt(x)
+			b = b[c:]
+		case binaryUint:
+			x, c := binary.Uvarint(b)
+			if c <= 0 {
+				return nil, nil, errBinary
+			}
+			reflect.ValueOf(&vs[i]).Elem().SetUint(x)
+			b = b[c:]
+		case binaryString, binaryMarshaler:
+			l, c := binary.Uvarint(b)
+			if c <= 0 || l > uint64(len(b)-c) {
+				return nil, nil, errBinary
+			}
+			p := b[c : c+int(l)]
+			if k == binaryString {
+				reflect.ValueOf(&vs[i]).Elem().SetString(string(p))
+			} else if err := any(&vs[i]).(encoding.BinaryUnmarshaler).UnmarshalBinary(p); err != nil {
+				return nil, nil, fmt.Errorf("ds: cannot decode element: %w", err)
+			}
+			b = b[c+int(l):]
+		default:
+			c, err := binary.Decode(b, binary.BigEndian, &vs[i])
+			if err != nil {
+				return nil, nil, errBinary
+			}
+			b = b[c:]
+		}
+	}
+	return vs, b, nil
+}
+
+// --- ArrayStack -------
+
+// MarshalJSON encodes the stack as JSON
+// array, from the bottom to the top.
+func (s *ArrayStack[T]) MarshalJSON() ([]byte, error) { return s.a.MarshalJSON() }
+
+// UnmarshalJSON replaces the elements
+// of the stack by a JSON array.
+func (s *ArrayStack[T]) UnmarshalJSON(b []byte) error { return s.a.UnmarshalJSON(b) }
+
+// GobEncode encodes the stack by gob.
+func (s *ArrayStack[T]) GobEncode() ([]byte, error) { return s.a.GobEncode() }
+
+// GobDecode replaces the elements of
+// the stack by a gob-encoded slice.
+func (s *ArrayStack[T]) GobDecode(b []byte) error { return s.a.GobDecode(b) }
+
+// MarshalBinary encodes the stack
+// in the binary format.
+func (s *ArrayStack[T]) MarshalBinary() ([]byte, error) { return s.a.MarshalBinary() }
+
+// UnmarshalBinary replaces the elements of
+// the stack by those in the binary format.
+func (s *ArrayStack[T]) UnmarshalBinary(b []byte) error { return s.a.UnmarshalBinary(b) }
+
+// --- Dynamic Array -------
+
+// MarshalJSON encodes the array as JSON array.
+func (a *Array[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(a.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the array by a JSON array.
+func (a *Array[T]) UnmarshalJSON(b []byte) error { return decode(b, unmarshalJSON[T], a.replace) }
+
+// GobEncode encodes the array by gob.
+func (a *Array[T]) GobEncode() ([]byte, error) { return gobEncode(elements(a.All())) }
+
+// GobDecode replaces the elements of
+// the array by a gob-encoded slice.
+func (a *Array[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], a.replace) }
+
+// MarshalBinary encodes the array
+// in the binary format.
+func (a *Array[T]) MarshalBinary() ([]byte, error) { return marshalBinary(elements(a.All())) }
+
+// UnmarshalBinary replaces the elements of
+// the array by those in the binary format.
+func (a *Array[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], a.replace)
+}
+
+func (a *Array[T]) replace(vs []T) {
+	a.Clear()
+	a.AddAll(0, vs...)
+}
+
+// --- ArrayQueue -------
+
+// MarshalJSON encodes the queue as JSON
+// array, from the head to the tail.
+func (q *ArrayQueue[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(q.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the queue by a JSON array.
+func (q *ArrayQueue[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], q.replace)
+}
+
+// GobEncode encodes the queue by gob.
+func (q *ArrayQueue[T]) GobEncode() ([]byte, error) { return gobEncode(elements(q.All())) }
+
+// GobDecode replaces the elements of
+// the queue by a gob-encoded slice.
+func (q *ArrayQueue[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], q.replace) }
+
+// MarshalBinary encodes the queue
+// in the binary format.
+func (q *ArrayQueue[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(q.All()))
+}
+
+// UnmarshalBinary replaces the elements of
+// the queue by those in the binary format.
+func (q *ArrayQueue[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], q.replace)
+}
+
+func (q *ArrayQueue[T]) replace(vs []T) {
+	*q = ArrayQueue[T]{g: q.g}
+	q.Reserve(len(vs))
+	for _, v := range vs {
+		q.Enqueue(v)
+	}
+}
+
+// --- Dequeue -------
+
+// MarshalJSON encodes the dequeue as JSON array.
+func (d *Dequeue[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(d.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the dequeue by a JSON array.
+func (d *Dequeue[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], d.replace)
+}
+
+// GobEncode encodes the dequeue by gob.
+func (d *Dequeue[T]) GobEncode() ([]byte, error) { return gobEncode(elements(d.All())) }
+
+// GobDecode replaces the elements of
+// the dequeue by a gob-encoded slice.
+func (d *Dequeue[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], d.replace) }
+
+// MarshalBinary encodes the dequeue
+// in the binary format.
+func (d *Dequeue[T]) MarshalBinary() ([]byte, error) { return marshalBinary(elements(d.All())) }
+
+// UnmarshalBinary replaces the elements of
+// the dequeue by those in the binary format.
+func (d *Dequeue[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], d.replace)
+}
+
+func (d *Dequeue[T]) replace(vs []T) {
+	d.Clear()
+	d.AddAll(0, vs...)
+}
+
+// --- DualDequeue -------
+
+// MarshalJSON encodes the dual
+// dequeue as JSON array.
+func (d *DualDequeue[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(d.All())) }
+
+// UnmarshalJSON replaces the elements of
+// the dual dequeue by a JSON array.
+func (d *DualDequeue[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], d.replace)
+}
+
+// GobEncode encodes the dual dequeue by gob.
+func (d *DualDequeue[T]) GobEncode() ([]byte, error) { return gobEncode(elements(d.All())) }
+
+// GobDecode replaces the elements of the
+// dual dequeue by a gob-encoded slice.
+func (d *DualDequeue[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], d.replace) }
+
+// MarshalBinary encodes the dual
+// dequeue in the binary format.
+func (d *DualDequeue[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(d.All()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// dual dequeue by those in the binary format.
+func (d *DualDequeue[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], d.replace)
+}
+
+func (d *DualDequeue[T]) replace(vs []T) {
+	d.Clear()
+	d.AddAll(0, vs...)
+}
+
+// --- RootishStack -------
+
+// MarshalJSON encodes the stack as JSON array.
+func (r *RootishStack[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(r.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the stack by a JSON array.
+func (r *RootishStack[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], r.replace)
+}
+
+// GobEncode encodes the stack by gob.
+func (r *RootishStack[T]) GobEncode() ([]byte, error) { return gobEncode(elements(r.All())) }
+
+// GobDecode replaces the elements of
+// the stack by a gob-encoded slice.
+func (r *RootishStack[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], r.replace) }
+
+// MarshalBinary encodes the stack
+// in the binary format.
+func (r *RootishStack[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(r.All()))
+}
+
+// UnmarshalBinary replaces the elements of
+// the stack by those in the binary format.
+func (r *RootishStack[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], r.replace)
+}
+
+func (r *RootishStack[T]) replace(vs []T) {
+	r.Clear()
+	r.AddAll(0, vs...)
+}
+
+// --- SList -------
+
+// MarshalJSON encodes the list as JSON
+// array, from the head to the tail.
+func (l *SList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the list by a JSON array.
+func (l *SList[T]) UnmarshalJSON(b []byte) error { return decode(b, unmarshalJSON[T], l.replace) }
+
+// GobEncode encodes the list by gob.
+func (l *SList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the elements of
+// the list by a gob-encoded slice.
+func (l *SList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l *SList[T]) MarshalBinary() ([]byte, error) { return marshalBinary(elements(l.All())) }
+
+// UnmarshalBinary replaces the elements of
+// the list by those in the binary format.
+func (l *SList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *SList[T]) replace(vs []T) {
+	*l = SList[T]{}
+	for _, v := range vs {
+		l.Enqueue(v)
+	}
+}
+
+// --- DList -------
+
+// MarshalJSON encodes the list as JSON array.
+func (l *DList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the list by a JSON array.
+func (l *DList[T]) UnmarshalJSON(b []byte) error { return decode(b, unmarshalJSON[T], l.replace) }
+
+// GobEncode encodes the list by gob.
+func (l *DList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the elements of
+// the list by a gob-encoded slice.
+func (l *DList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l *DList[T]) MarshalBinary() ([]byte, error) { return marshalBinary(elements(l.All())) }
+
+// UnmarshalBinary replaces the elements of
+// the list by those in the binary format.
+func (l *DList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *DList[T]) replace(vs []T) {
+	l.Clear()
+	l.AddAll(0, vs...)
+}
+
+// --- SEList -------
+
+// MarshalJSON encodes the list as JSON array.
+func (l *SEList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the list by a JSON array.
+func (l *SEList[T]) UnmarshalJSON(b []byte) error { return decode(b, unmarshalJSON[T], l.replace) }
+
+// GobEncode encodes the list by gob.
+func (l *SEList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the elements of
+// the list by a gob-encoded slice.
+func (l *SEList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l *SEList[T]) MarshalBinary() ([]byte, error) { return marshalBinary(elements(l.All())) }
+
+// UnmarshalBinary replaces the elements of
+// the list by those in the binary format.
+func (l *SEList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *SEList[T]) replace(vs []T) {
+	*l = SEList[T]{b: l.b}
+	for _, v := range vs {
+		l.AddLast(v)
+	}
+}
+
+// --- SkiplistList -------
+
+// MarshalJSON encodes the list as JSON array.
+func (l *SkiplistList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the elements
+// of the list by a JSON array.
+func (l *SkiplistList[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], l.replace)
+}
+
+// GobEncode encodes the list by gob.
+func (l *SkiplistList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the elements of
+// the list by a gob-encoded slice.
+func (l *SkiplistList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l *SkiplistList[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(l.All()))
+}
+
+// UnmarshalBinary replaces the elements of
+// the list by those in the binary format.
+func (l *SkiplistList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *SkiplistList[T]) replace(vs []T) {
+	*l = SkiplistList[T]{rnd: l.rnd}
+	for i, v := range vs {
+		l.Add(i, v)
+	}
+}
+
+// --- Sorted Sets -------
+
+// MarshalJSON encodes the tree as JSON
+// array, in ascending order.
+func (b *bst[T]) MarshalJSON() ([]byte, error) { return marshalJSON(b.All()) }
+
+// GobEncode encodes the tree by gob.
+func (b *bst[T]) GobEncode() ([]byte, error) { return gobEncode(b.All()) }
+
+// MarshalBinary encodes the tree
+// in the binary format.
+func (b *bst[T]) MarshalBinary() ([]byte, error) { return marshalBinary(b.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the tree by a JSON array.
+func (t *BinarySearchTree[T]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[T], t, t.cmp != nil)
+}
+
+// GobDecode replaces the elements of
+// the tree by a gob-encoded slice.
+func (t *BinarySearchTree[T]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[T], t, t.cmp != nil)
+}
+
+// UnmarshalBinary replaces the elements of
+// the tree by those in the binary format.
+func (t *BinarySearchTree[T]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[T], t, t.cmp != nil)
+}
+
+// UnmarshalJSON replaces the elements
+// of the treap by a JSON array.
+func (t *Treap[T]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[T], t, t.cmp != nil)
+}
+
+// GobDecode replaces the elements of
+// the treap by a gob-encoded slice.
+func (t *Treap[T]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[T], t, t.cmp != nil)
+}
+
+// UnmarshalBinary replaces the elements of
+// the treap by those in the binary format.
+func (t *Treap[T]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[T], t, t.cmp != nil)
+}
+
+// UnmarshalJSON replaces the elements
+// of the tree by a JSON array.
+func (t *ScapegoatTree[T]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[T], t, t.cmp != nil)
+}
+
+// GobDecode replaces the elements of
+// the tree by a gob-encoded slice.
+func (t *ScapegoatTree[T]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[T], t, t.cmp != nil)
+}
+
+// UnmarshalBinary replaces the elements of
+// the tree by those in the binary format.
+func (t *ScapegoatTree[T]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[T], t, t.cmp != nil)
+}
+
+// UnmarshalJSON replaces the elements
+// of the tree by a JSON array.
+func (t *RedBlackTree[T]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[T], t, t.cmp != nil)
+}
+
+// GobDecode replaces the elements of
+// the tree by a gob-encoded slice.
+func (t *RedBlackTree[T]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[T], t, t.cmp != nil)
+}
+
+// UnmarshalBinary replaces the elements of
+// the tree by those in the binary format.
+func (t *RedBlackTree[T]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[T], t, t.cmp != nil)
+}
+
+// MarshalJSON encodes the skiplist as
+// JSON array, in ascending order.
+func (s *SkiplistSSet[T]) MarshalJSON() ([]byte, error) { return marshalJSON(s.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the skiplist by a JSON array.
+func (s *SkiplistSSet[T]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[T], s, s.cmp != nil)
+}
+
+// GobEncode encodes the skiplist by gob.
+func (s *SkiplistSSet[T]) GobEncode() ([]byte, error) { return gobEncode(s.All()) }
+
+// GobDecode replaces the elements of
+// the skiplist by a gob-encoded slice.
+func (s *SkiplistSSet[T]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[T], s, s.cmp != nil)
+}
+
+// MarshalBinary encodes the skiplist
+// in the binary format.
+func (s *SkiplistSSet[T]) MarshalBinary() ([]byte, error) { return marshalBinary(s.All()) }
+
+// UnmarshalBinary replaces the elements of the
+// skiplist by those in the binary format.
+func (s *SkiplistSSet[T]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[T], s, s.cmp != nil)
+}
+
+// decodeSet decodes b by f and replaces the
+// elements of s, which must have a comparison
+// function.
+func decodeSet[T any](b []byte, f func([]byte) ([]T, error), s SSet[T], cmp bool) error {
+	if !cmp {
+		return errNoCmp
+	}
+	return decode(b, f, func(vs []T) { replaceSet(s, vs) })
+}
+
+// --- Tries -------
+
+// MarshalJSON encodes the trie as JSON
+// array, in ascending order.
+func (t *trie[K, P]) MarshalJSON() ([]byte, error) { return marshalJSON(t.All()) }
+
+// GobEncode encodes the trie by gob.
+func (t *trie[K, P]) GobEncode() ([]byte, error) { return gobEncode(t.All()) }
+
+// MarshalBinary encodes the trie
+// in the binary format.
+func (t *trie[K, P]) MarshalBinary() ([]byte, error) { return marshalBinary(t.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the trie by a JSON array.
+func (t *BinaryTrie[K]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[K], t, true)
+}
+
+// GobDecode replaces the elements of
+// the trie by a gob-encoded slice.
+func (t *BinaryTrie[K]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[K], t, true)
+}
+
+// UnmarshalBinary replaces the elements of
+// the trie by those in the binary format.
+func (t *BinaryTrie[K]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[K], t, true)
+}
+
+// UnmarshalJSON replaces the elements
+// of the trie by a JSON array.
+func (t *XFastTrie[K]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[K], t, true)
+}
+
+// GobDecode replaces the elements of
+// the trie by a gob-encoded slice.
+func (t *XFastTrie[K]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[K], t, true)
+}
+
+// UnmarshalBinary replaces the elements of
+// the trie by those in the binary format.
+func (t *XFastTrie[K]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[K], t, true)
+}
+
+// MarshalJSON encodes the trie as JSON
+// array, in ascending order.
+func (t *YFastTrie[K]) MarshalJSON() ([]byte, error) { return marshalJSON(t.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the trie by a JSON array.
+func (t *YFastTrie[K]) UnmarshalJSON(b []byte) error {
+	return decodeSet(b, unmarshalJSON[K], t, true)
+}
+
+// GobEncode encodes the trie by gob.
+func (t *YFastTrie[K]) GobEncode() ([]byte, error) { return gobEncode(t.All()) }
+
+// GobDecode replaces the elements of
+// the trie by a gob-encoded slice.
+func (t *YFastTrie[K]) GobDecode(b []byte) error {
+	return decodeSet(b, gobDecode[K], t, true)
+}
+
+// MarshalBinary encodes the trie
+// in the binary format.
+func (t *YFastTrie[K]) MarshalBinary() ([]byte, error) { return marshalBinary(t.All()) }
+
+// UnmarshalBinary replaces the elements of
+// the trie by those in the binary format.
+func (t *YFastTrie[K]) UnmarshalBinary(b []byte) error {
+	return decodeSet(b, unmarshalBinary[K], t, true)
+}
+
+// --- Heaps -------
+
+// MarshalJSON encodes the heap as JSON array.
+func (h *BinaryHeap[T]) MarshalJSON() ([]byte, error) { return marshalJSON(h.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the heap by a JSON array.
+func (h *BinaryHeap[T]) UnmarshalJSON(b []byte) error {
+	return decodeHeap(b, unmarshalJSON[T], h, h.cmp != nil)
+}
+
+// GobEncode encodes the heap by gob.
+func (h *BinaryHeap[T]) GobEncode() ([]byte, error) { return gobEncode(h.All()) }
+
+// GobDecode replaces the elements of
+// the heap by a gob-encoded slice.
+func (h *BinaryHeap[T]) GobDecode(b []byte) error {
+	return decodeHeap(b, gobDecode[T], h, h.cmp != nil)
+}
+
+// MarshalBinary encodes the heap
+// in the binary format.
+func (h *BinaryHeap[T]) MarshalBinary() ([]byte, error) { return marshalBinary(h.All()) }
+
+// UnmarshalBinary replaces the elements of
+// the heap by those in the binary format.
+func (h *BinaryHeap[T]) UnmarshalBinary(b []byte) error {
+	return decodeHeap(b, unmarshalBinary[T], h, h.cmp != nil)
+}
+
+// MarshalJSON encodes the heap as JSON array.
+func (h *MeldableHeap[T]) MarshalJSON() ([]byte, error) { return marshalJSON(h.All()) }
+
+// UnmarshalJSON replaces the elements
+// of the heap by a JSON array.
+func (h *MeldableHeap[T]) UnmarshalJSON(b []byte) error {
+	return decodeHeap(b, unmarshalJSON[T], h, h.cmp != nil)
+}
+
+// GobEncode encodes the heap by gob.
+func (h *MeldableHeap[T]) GobEncode() ([]byte, error) { return gobEncode(h.All()) }
+
+// GobDecode replaces the elements of
+// the heap by a gob-encoded slice.
+func (h *MeldableHeap[T]) GobDecode(b []byte) error {
+	return decodeHeap(b, gobDecode[T], h, h.cmp != nil)
+}
+
+// MarshalBinary encodes the heap
+// in the binary format.
+func (h *MeldableHeap[T]) MarshalBinary() ([]byte, error) { return marshalBinary(h.All()) }
+
+// UnmarshalBinary replaces the elements of
+// the heap by those in the binary format.
+func (h *MeldableHeap[T]) UnmarshalBinary(b []byte) error {
+	return decodeHeap(b, unmarshalBinary[T], h, h.cmp != nil)
+}
+
+// decodeHeap decodes b by f and replaces the
+// elements of h, which must have a comparison
+// function.
+func decodeHeap[T any](b []byte, f func([]byte) ([]T, error), h PriorityQueue[T], cmp bool) error {
+	if !cmp {
+		return errNoCmp
+	}
+	return decode(b, f, func(vs []T) { replaceHeap(h, vs) })
+}
+
+// --- RingBuffer -------
+
+// MarshalJSON encodes the ring buffer as
+// JSON array, from the head to the tail.
+func (b *RingBuffer[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(b.All())) }
+
+// UnmarshalJSON replaces the elements of the
+// ring buffer by a JSON array. If there are more
+// elements than the capacity, it is increased.
+func (b *RingBuffer[T]) UnmarshalJSON(p []byte) error {
+	return decode(p, unmarshalJSON[T], b.replace)
+}
+
+// GobEncode encodes the ring buffer by gob.
+func (b *RingBuffer[T]) GobEncode() ([]byte, error) { return gobEncode(elements(b.All())) }
+
+// GobDecode replaces the elements of the ring
+// buffer by a gob-encoded slice. If there are more
+// elements than the capacity, it is increased.
+func (b *RingBuffer[T]) GobDecode(p []byte) error { return decode(p, gobDecode[T], b.replace) }
+
+// MarshalBinary encodes the ring
+// buffer in the binary format.
+func (b *RingBuffer[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(b.All()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// ring buffer by those in the binary format. If
+// there are more elements than the capacity, it
+// is increased.
+func (b *RingBuffer[T]) UnmarshalBinary(p []byte) error {
+	return decode(p, unmarshalBinary[T], b.replace)
+}
+
+func (b *RingBuffer[T]) replace(vs []T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.init()
+	if len(vs) > len(b.s) {
+		b.s = make([]T, len(vs))
+	} else {
+		clear(b.s)
+	}
+	copy(b.s, vs)
+	b.r, b.n = 0, len(vs)
+	b.notify()
+}
+
+// --- Hash Tables -------
+
+// pair is the encoding of an
+// entry of a hash table.
+type pair[K, T any] struct {
+	Key   K
+	Value T
+}
+
+// pairs returns an iterator over
+// the key-value pairs of seq.
+func pairs[K, T any](seq iter.Seq2[K, T]) iter.Seq[pair[K, T]] {
+	return func(yield func(pair[K, T]) bool) {
+		for k, v := range seq {
+			if !yield(pair[K, T]{k, v}) {
+				return
+			}
+		}
+	}
+}
+
+// marshalPairs encodes the keys of seq
+// followed by its values in the binary
+// format.
+func marshalPairs[K, T any](seq iter.Seq2[K, T]) ([]byte, error) {
+	var ks []K
+	var vs []T
+	for k, v := range seq {
+		ks = append(ks, k)
+		vs = append(vs, v)
+	}
+	b, err := marshalBinary(slices.Values(ks))
+	if err != nil {
+		return nil, err
+	}
+	p, err := marshalBinary(slices.Values(vs))
+	if err != nil {
+		return nil, err
+	}
+	return append(b, p...), nil
+}
+
+// unmarshalPairs decodes the keys followed
+// by the values in the binary format.
+func unmarshalPairs[K, T any](b []byte) ([]pair[K, T], error) {
+	ks, b, err := readBinary[K](b)
+	if err != nil {
+		return nil, err
+	}
+	vs, b, err := readBinary[T](b)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 || len(ks) != len(vs) {
+		return nil, errBinary
+	}
+	ps := make([]pair[K, T], len(ks))
+	for i := range ps {
+		ps[i] = pair[K, T]{ks[i], vs[i]}
+	}
+	return ps, nil
+}
+
+// MarshalJSON encodes the hash table as JSON
+// array of objects with the fields Key and Value.
+func (h *ChainedHashTable[K, T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(pairs(h.All()))
+}
+
+// UnmarshalJSON replaces the entries of the hash table
+// by a JSON array of objects with the fields Key and
+// Value. The hash function is kept.
+func (h *ChainedHashTable[K, T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[pair[K, T]], h.replace)
+}
+
+// GobEncode encodes the hash table by gob.
+func (h *ChainedHashTable[K, T]) GobEncode() ([]byte, error) { return gobEncode(pairs(h.All())) }
+
+// GobDecode replaces the entries of the hash table
+// by a gob-encoded slice. The hash function is kept.
+func (h *ChainedHashTable[K, T]) GobDecode(b []byte) error {
+	return decode(b, gobDecode[pair[K, T]], h.replace)
+}
+
+// MarshalBinary encodes the hash
+// table in the binary format.
+func (h *ChainedHashTable[K, T]) MarshalBinary() ([]byte, error) { return marshalPairs(h.All()) }
+
+// UnmarshalBinary replaces the entries of the hash
+// table by those in the binary format. The hash
+// function is kept.
+func (h *ChainedHashTable[K, T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalPairs[K, T], h.replace)
+}
+
+func (h *ChainedHashTable[K, T]) replace(ps []pair[K, T]) {
+	*h = ChainedHashTable[K, T]{hash: h.hash}
+	for _, p := range ps {
+		h.Put(p.Key, p.Value)
+	}
+}
+
+// MarshalJSON encodes the hash table as JSON
+// array of objects with the fields Key and Value.
+func (h *LinearHashTable[K, T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(pairs(h.All()))
+}
+
+// UnmarshalJSON replaces the entries of the hash table
+// by a JSON array of objects with the fields Key and
+// Value. The hash function is kept.
+func (h *LinearHashTable[K, T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[pair[K, T]], h.replace)
+}
+
+// GobEncode encodes the hash table by gob.
+func (h *LinearHashTable[K, T]) GobEncode() ([]byte, error) { return gobEncode(pairs(h.All())) }
+
+// GobDecode replaces the entries of the hash table
+// by a gob-encoded slice. The hash function is kept.
+func (h *LinearHashTable[K, T]) GobDecode(b []byte) error {
+	return decode(b, gobDecode[pair[K, T]], h.replace)
+}
+
+// MarshalBinary encodes the hash
+// table in the binary format.
+func (h *LinearHashTable[K, T]) MarshalBinary() ([]byte, error) { return marshalPairs(h.All()) }
+
+// UnmarshalBinary replaces the entries of the hash
+// table by those in the binary format. The hash
+// function is kept.
+func (h *LinearHashTable[K, T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalPairs[K, T], h.replace)
+}
+
+func (h *LinearHashTable[K, T]) replace(ps []pair[K, T]) {
+	*h = LinearHashTable[K, T]{hash: h.hash}
+	for _, p := range ps {
+		h.Put(p.Key, p.Value)
+	}
+}
+
+// --- Synchronized Wrappers -------
+
+// snapshot returns the elements of the data structure
+// wrapped by s, as returned by f while holding the lock.
+func snapshot[C, T any](s *Synchronized[C], f func(c C) []T) []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if any(s.c) == nil {
+		return nil
+	}
+	return f(s.c)
+}
+
+// decodeSync decodes b by f and replaces the elements of
+// the data structure wrapped by s by set while holding
+// the lock.
+func decodeSync[C, T any](s *Synchronized[C], b []byte, f func([]byte) ([]T, error), set func(c C, vs []T)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if any(s.c) == nil {
+		return errUnwrapped
+	}
+	return decode(b, f, func(vs []T) { set(s.c, vs) })
+}
+
+// MarshalJSON encodes the list as JSON
+// array, in order.
+func (s *SyncList[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slices.Values(s.snapshot()))
+}
+
+// UnmarshalJSON replaces the elements of
+// the wrapped list by a JSON array.
+func (s *SyncList[T]) UnmarshalJSON(b []byte) error { return s.decode(b, unmarshalJSON[T]) }
+
+// GobEncode encodes the list by gob.
+func (s *SyncList[T]) GobEncode() ([]byte, error) {
+	return gobEncode(slices.Values(s.snapshot()))
+}
+
+// GobDecode replaces the elements of the
+// wrapped list by a gob-encoded slice.
+func (s *SyncList[T]) GobDecode(b []byte) error { return s.decode(b, gobDecode[T]) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (s *SyncList[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(slices.Values(s.snapshot()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// wrapped list by those in the binary format.
+func (s *SyncList[T]) UnmarshalBinary(b []byte) error {
+	return s.decode(b, unmarshalBinary[T])
+}
+
+func (s *SyncList[T]) snapshot() []T {
+	return snapshot(&s.Synchronized, func(l List[T]) []T {
+		vs := make([]T, l.Len())
+		for i := range vs {
+			vs[i], _ = l.Get(i)
+		}
+		return vs
+	})
+}
+
+func (s *SyncList[T]) decode(b []byte, f func([]byte) ([]T, error)) error {
+	return decodeSync(&s.Synchronized, b, f, func(l List[T], vs []T) {
+		for l.Len() > 0 {
+			l.Remove(l.Len() - 1)
+		}
+		for i, v := range vs {
+			l.Add(i, v)
+		}
+	})
+}
+
+// MarshalJSON encodes the stack as JSON
+// array, from the bottom to the top.
+func (s *SyncStack[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slices.Values(s.snapshot()))
+}
+
+// UnmarshalJSON replaces the elements of
+// the wrapped stack by a JSON array.
+func (s *SyncStack[T]) UnmarshalJSON(b []byte) error { return s.decode(b, unmarshalJSON[T]) }
+
+// GobEncode encodes the stack by gob.
+func (s *SyncStack[T]) GobEncode() ([]byte, error) {
+	return gobEncode(slices.Values(s.snapshot()))
+}
+
+// GobDecode replaces the elements of the
+// wrapped stack by a gob-encoded slice.
+func (s *SyncStack[T]) GobDecode(b []byte) error { return s.decode(b, gobDecode[T]) }
+
+// MarshalBinary encodes the stack
+// in the binary format.
+func (s *SyncStack[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(slices.Values(s.snapshot()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// wrapped stack by those in the binary format.
+func (s *SyncStack[T]) UnmarshalBinary(b []byte) error {
+	return s.decode(b, unmarshalBinary[T])
+}
+
+// snapshot returns the elements of the stack. As a
+// stack cannot be iterated, the elements are popped
+// and pushed again.
+func (s *SyncStack[T]) snapshot() []T {
+	return snapshot(&s.Synchronized, func(st Stack[T]) []T {
+		vs := make([]T, st.Len())
+		for i := len(vs) - 1; i >= 0; i-- {
+			vs[i], _ = st.Pop()
+		}
+		for _, v := range vs {
+			st.Push(v)
+		}
+		return vs
+	})
+}
+
+func (s *SyncStack[T]) decode(b []byte, f func([]byte) ([]T, error)) error {
+	return decodeSync(&s.Synchronized, b, f, func(st Stack[T], vs []T) {
+		for st.Len() > 0 {
+			st.Pop()
+		}
+		for _, v := range vs {
+			st.Push(v)
+		}
+	})
+}
+
+// MarshalJSON encodes the queue as JSON
+// array, from the head to the tail.
+func (s *SyncQueue[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slices.Values(s.snapshot()))
+}
+
+// UnmarshalJSON replaces the elements of
+// the wrapped queue by a JSON array.
+func (s *SyncQueue[T]) UnmarshalJSON(b []byte) error { return s.decode(b, unmarshalJSON[T]) }
+
+// GobEncode encodes the queue by gob.
+func (s *SyncQueue[T]) GobEncode() ([]byte, error) {
+	return gobEncode(slices.Values(s.snapshot()))
+}
+
+// GobDecode replaces the elements of the
+// wrapped queue by a gob-encoded slice.
+func (s *SyncQueue[T]) GobDecode(b []byte) error { return s.decode(b, gobDecode[T]) }
+
+// MarshalBinary encodes the queue
+// in the binary format.
+func (s *SyncQueue[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(slices.Values(s.snapshot()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// wrapped queue by those in the binary format.
+func (s *SyncQueue[T]) UnmarshalBinary(b []byte) error {
+	return s.decode(b, unmarshalBinary[T])
+}
+
+// snapshot returns the elements of the queue. As
+// a queue cannot be iterated, each element is
+// dequeued and enqueued again.
+func (s *SyncQueue[T]) snapshot() []T {
+	return snapshot(&s.Synchronized, func(q Queue[T]) []T {
+		vs := make([]T, q.Len())
+		for i := range vs {
+			vs[i], _ = q.Dequeue()
+			q.Enqueue(vs[i])
+		}
+		return vs
+	})
+}
+
+func (s *SyncQueue[T]) decode(b []byte, f func([]byte) ([]T, error)) error {
+	return decodeSync(&s.Synchronized, b, f, func(q Queue[T], vs []T) {
+		for q.Len() > 0 {
+			q.Dequeue()
+		}
+		for _, v := range vs {
+			q.Enqueue(v)
+		}
+	})
+}
+
+// MarshalJSON encodes the deque as JSON
+// array, from the front to the back.
+func (s *SyncDeque[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON(slices.Values(s.snapshot()))
+}
+
+// UnmarshalJSON replaces the elements of
+// the wrapped deque by a JSON array.
+func (s *SyncDeque[T]) UnmarshalJSON(b []byte) error { return s.decode(b, unmarshalJSON[T]) }
+
+// GobEncode encodes the deque by gob.
+func (s *SyncDeque[T]) GobEncode() ([]byte, error) {
+	return gobEncode(slices.Values(s.snapshot()))
+}
+
+// GobDecode replaces the elements of the
+// wrapped deque by a gob-encoded slice.
+func (s *SyncDeque[T]) GobDecode(b []byte) error { return s.decode(b, gobDecode[T]) }
+
+// MarshalBinary encodes the deque
+// in the binary format.
+func (s *SyncDeque[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(slices.Values(s.snapshot()))
+}
+
+// UnmarshalBinary replaces the elements of the
+// wrapped deque by those in the binary format.
+func (s *SyncDeque[T]) UnmarshalBinary(b []byte) error {
+	return s.decode(b, unmarshalBinary[T])
+}
+
+// snapshot returns the elements of the deque. As a
+// deque cannot be iterated, each element is removed
+// from the front and added to the back again.
+func (s *SyncDeque[T]) snapshot() []T {
+	return snapshot(&s.Synchronized, func(d Deque[T]) []T {
+		vs := make([]T, d.Len())
+		for i := range vs {
+			vs[i], _ = d.RemoveFirst()
+			d.AddLast(vs[i])
+		}
+		return vs
+	})
+}
+
+func (s *SyncDeque[T]) decode(b []byte, f func([]byte) ([]T, error)) error {
+	return decodeSync(&s.Synchronized, b, f, func(d Deque[T], vs []T) {
+		for d.Len() > 0 {
+			d.RemoveLast()
+		}
+		for _, v := range vs {
+			d.AddLast(v)
+		}
+	})
+}
+
+// --- BlockingQueue -------
+
+// MarshalJSON encodes the queue as JSON
+// array, from the head to the tail.
+func (b *BlockingQueue[T]) MarshalJSON() ([]byte, error) { return marshalJSON(b.all()) }
+
+// UnmarshalJSON replaces the elements of the
+// queue by a JSON array. If there are more
+// elements than the capacity, it is increased.
+func (b *BlockingQueue[T]) UnmarshalJSON(p []byte) error {
+	return decode(p, unmarshalJSON[T], b.replace)
+}
+
+// GobEncode encodes the queue by gob.
+func (b *BlockingQueue[T]) GobEncode() ([]byte, error) { return gobEncode(b.all()) }
+
+// GobDecode replaces the elements of the queue
+// by a gob-encoded slice. If there are more
+// elements than the capacity, it is increased.
+func (b *BlockingQueue[T]) GobDecode(p []byte) error { return decode(p, gobDecode[T], b.replace) }
+
+// MarshalBinary encodes the queue
+// in the binary format.
+func (b *BlockingQueue[T]) MarshalBinary() ([]byte, error) { return marshalBinary(b.all()) }
+
+// UnmarshalBinary replaces the elements of the
+// queue by those in the binary format. If there
+// are more elements than the capacity, it is
+// increased.
+func (b *BlockingQueue[T]) UnmarshalBinary(p []byte) error {
+	return decode(p, unmarshalBinary[T], b.replace)
+}
+
+// all returns an iterator over the elements
+// of the queue, from the head to the tail.
+// The queue is locked during the iteration.
+func (b *BlockingQueue[T]) all() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, v := range b.q.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (b *BlockingQueue[T]) replace(vs []T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.init()
+	b.c = max(b.c, len(vs))
+	b.q.replace(vs)
+	b.notify()
+}
+
+// --- LockFreeQueue -------
+
+// MarshalJSON encodes the queue as JSON
+// array, from the head to the tail.
+func (q *LockFreeQueue[T]) MarshalJSON() ([]byte, error) { return marshalJSON(q.all()) }
+
+// UnmarshalJSON replaces the elements of
+// the queue by a JSON array. The queue must
+// not be used concurrently while decoding.
+func (q *LockFreeQueue[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], q.replace)
+}
+
+// GobEncode encodes the queue by gob.
+func (q *LockFreeQueue[T]) GobEncode() ([]byte, error) { return gobEncode(q.all()) }
+
+// GobDecode replaces the elements of the queue
+// by a gob-encoded slice. The queue must not be
+// used concurrently while decoding.
+func (q *LockFreeQueue[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], q.replace) }
+
+// MarshalBinary encodes the queue
+// in the binary format.
+func (q *LockFreeQueue[T]) MarshalBinary() ([]byte, error) { return marshalBinary(q.all()) }
+
+// UnmarshalBinary replaces the elements of the
+// queue by those in the binary format. The queue
+// must not be used concurrently while decoding.
+func (q *LockFreeQueue[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], q.replace)
+}
+
+// all returns an iterator over the elements of
+// the queue, from the head to the tail. It is
+// only a snapshot while other goroutines
+// modify the queue.
+func (q *LockFreeQueue[T]) all() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q.init()
+		for n := q.h.Load().n.Load(); n != nil; n = n.n.Load() {
+			if !yield(n.v) {
+				return
+			}
+		}
+	}
+}
+
+func (q *LockFreeQueue[T]) replace(vs []T) {
+	q.h.Store(nil)
+	q.t.Store(nil)
+	q.n.Store(0)
+	for _, v := range vs {
+		q.Enqueue(v)
+	}
+}
+
+// --- LockFreeStack -------
+
+// MarshalJSON encodes the stack as JSON
+// array, from the bottom to the top.
+func (s *LockFreeStack[T]) MarshalJSON() ([]byte, error) { return marshalJSON(s.all()) }
+
+// UnmarshalJSON replaces the elements of
+// the stack by a JSON array. The stack must
+// not be used concurrently while decoding.
+func (s *LockFreeStack[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], s.replace)
+}
+
+// GobEncode encodes the stack by gob.
+func (s *LockFreeStack[T]) GobEncode() ([]byte, error) { return gobEncode(s.all()) }
+
+// GobDecode replaces the elements of the stack
+// by a gob-encoded slice. The stack must not be
+// used concurrently while decoding.
+func (s *LockFreeStack[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], s.replace) }
+
+// MarshalBinary encodes the stack
+// in the binary format.
+func (s *LockFreeStack[T]) MarshalBinary() ([]byte, error) { return marshalBinary(s.all()) }
+
+// UnmarshalBinary replaces the elements of the
+// stack by those in the binary format. The stack
+// must not be used concurrently while decoding.
+func (s *LockFreeStack[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], s.replace)
+}
+
+// all returns an iterator over the elements of
+// the stack, from the bottom to the top. It is
+// only a snapshot while other goroutines modify
+// the stack.
+func (s *LockFreeStack[T]) all() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var vs []T
+		for n := s.h.Load(); n != nil; n = n.n.Load() {
+			vs = append(vs, n.v)
+		}
+		for _, v := range slices.Backward(vs) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (s *LockFreeStack[T]) replace(vs []T) {
+	s.h.Store(nil)
+	s.n.Store(0)
+	for _, v := range vs {
+		s.Push(v)
+	}
+}
+
+// --- Persistent Lists -------
+
+// MarshalJSON encodes the list as JSON
+// array, from the head to the tail.
+func (l PersistentSList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the list by
+// one with the elements of a JSON array.
+func (l *PersistentSList[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], l.replace)
+}
+
+// GobEncode encodes the list by gob.
+func (l PersistentSList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the list by one with
+// the elements of a gob-encoded slice.
+func (l *PersistentSList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l PersistentSList[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(l.All()))
+}
+
+// UnmarshalBinary replaces the list by one
+// with the elements in the binary format.
+func (l *PersistentSList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *PersistentSList[T]) replace(vs []T) {
+	*l = PersistentSList[T]{}
+	for _, v := range slices.Backward(vs) {
+		*l = l.Push(v)
+	}
+}
+
+// MarshalJSON encodes the list as JSON array.
+func (l PersistentList[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(l.All())) }
+
+// UnmarshalJSON replaces the list by
+// one with the elements of a JSON array.
+func (l *PersistentList[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], l.replace)
+}
+
+// GobEncode encodes the list by gob.
+func (l PersistentList[T]) GobEncode() ([]byte, error) { return gobEncode(elements(l.All())) }
+
+// GobDecode replaces the list by one with
+// the elements of a gob-encoded slice.
+func (l *PersistentList[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], l.replace) }
+
+// MarshalBinary encodes the list
+// in the binary format.
+func (l PersistentList[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(l.All()))
+}
+
+// UnmarshalBinary replaces the list by one
+// with the elements in the binary format.
+func (l *PersistentList[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], l.replace)
+}
+
+func (l *PersistentList[T]) replace(vs []T) {
+	*l = PersistentList[T]{}
+	for _, v := range vs {
+		*l = l.AddLast(v)
+	}
+}
+
+// MarshalJSON encodes the deque as JSON
+// array, from the front to the back.
+func (d PersistentDeque[T]) MarshalJSON() ([]byte, error) { return marshalJSON(elements(d.All())) }
+
+// UnmarshalJSON replaces the deque by
+// one with the elements of a JSON array.
+func (d *PersistentDeque[T]) UnmarshalJSON(b []byte) error {
+	return decode(b, unmarshalJSON[T], d.replace)
+}
+
+// GobEncode encodes the deque by gob.
+func (d PersistentDeque[T]) GobEncode() ([]byte, error) { return gobEncode(elements(d.All())) }
+
+// GobDecode replaces the deque by one with
+// the elements of a gob-encoded slice.
+func (d *PersistentDeque[T]) GobDecode(b []byte) error { return decode(b, gobDecode[T], d.replace) }
+
+// MarshalBinary encodes the deque
+// in the binary format.
+func (d PersistentDeque[T]) MarshalBinary() ([]byte, error) {
+	return marshalBinary(elements(d.All()))
+}
+
+// UnmarshalBinary replaces the deque by one
+// with the elements in the binary format.
+func (d *PersistentDeque[T]) UnmarshalBinary(b []byte) error {
+	return decode(b, unmarshalBinary[T], d.replace)
+}
+
+func (d *PersistentDeque[T]) replace(vs []T) {
+	*d = PersistentDeque[T]{}
+	for _, v := range vs {
+		*d = d.AddLast(v)
+	}
+}
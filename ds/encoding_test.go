@@ -0,0 +1,446 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"iter"
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type codec interface {
+	json.Marshaler
+	json.Unmarshaler
+	gob.GobEncoder
+	gob.GobDecoder
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// formats encodes c in every format and
+// returns a decoder for each encoding.
+func formats(t *testing.T, c codec) map[string]func(codec) error {
+	t.Helper()
+	j, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g bytes.Buffer
+	if err := gob.NewEncoder(&g).Encode(c); err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]func(codec) error{
+		"JSON":   func(d codec) error { return json.Unmarshal(j, d) },
+		"gob":    func(d codec) error { return gob.NewDecoder(bytes.NewReader(g.Bytes())).Decode(d) },
+		"binary": func(d codec) error { return d.UnmarshalBinary(b) },
+	}
+}
+
+func TestEncodingSequences(t *testing.T) {
+	type seq interface {
+		codec
+		Len() int
+	}
+	add := func(s seq, v int) {
+		switch s := s.(type) {
+		case List[int]:
+			s.Add(s.Len(), v)
+		case Queue[int]:
+			s.Enqueue(v)
+		case Stack[int]:
+			s.Push(v)
+		case *RingBuffer[int]:
+			s.Push(v)
+		case *BlockingQueue[int]:
+			s.TryEnqueue(v)
+		case *SyncDeque[int]:
+			s.AddLast(v)
+		case *PersistentSList[int]:
+			*s, _ = s.Add(s.Len(), v)
+		case *PersistentList[int]:
+			*s = s.AddLast(v)
+		case *PersistentDeque[int]:
+			*s = s.AddLast(v)
+		}
+	}
+	all := func(s seq) []int {
+		var a []int
+		switch s := s.(type) {
+		case *ArrayStack[int]:
+			a = collect(s.All())
+		case *ArrayQueue[int]:
+			a = collect(s.All())
+		case *SList[int]:
+			a = collect(s.All())
+		case *RingBuffer[int]:
+			a = collect(s.All())
+		case *BlockingQueue[int]:
+			a = slices.Collect(s.all())
+		case *SyncList[int]:
+			a = s.snapshot()
+		case *SyncStack[int]:
+			a = s.snapshot()
+		case *SyncQueue[int]:
+			a = s.snapshot()
+		case *SyncDeque[int]:
+			a = s.snapshot()
+		case *PersistentSList[int]:
+			a = collect(s.All())
+		case *PersistentList[int]:
+			a = collect(s.All())
+		case *PersistentDeque[int]:
+			a = collect(s.All())
+		case *LockFreeQueue[int]:
+			a = slices.Collect(s.all())
+		case *LockFreeStack[int]:
+			a = slices.Collect(s.all())
+		case List[int]:
+			for i := 0; i < s.Len(); i++ {
+				v, _ := s.Get(i)
+				a = append(a, v)
+			}
+		}
+		return a
+	}
+	seqs := map[string]func() seq{
+		"ArrayStack":    func() seq { return new(ArrayStack[int]) },
+		"Array":         func() seq { return new(Array[int]) },
+		"ArrayQueue":    func() seq { return new(ArrayQueue[int]) },
+		"Dequeue":       func() seq { return new(Dequeue[int]) },
+		"DualDequeue":   func() seq { return new(DualDequeue[int]) },
+		"RootishStack":  func() seq { return new(RootishStack[int]) },
+		"SList":         func() seq { return new(SList[int]) },
+		"DList":         func() seq { return new(DList[int]) },
+		"SEList":        func() seq { return new(SEList[int]) },
+		"SkiplistList":  func() seq { return new(SkiplistList[int]) },
+		"RingBuffer":    func() seq { return NewRingBuffer[int](128, OverflowReject) },
+		"BlockingQueue": func() seq { return NewBlockingQueue[int](128) },
+		"SyncList":      func() seq { return NewSyncList[int](new(Array[int])) },
+		"SyncStack":     func() seq { return NewSyncStack[int](new(ArrayStack[int])) },
+		"SyncQueue":     func() seq { return NewSyncQueue[int](new(ArrayQueue[int])) },
+		"SyncDeque":     func() seq { return NewSyncDeque[int](new(Dequeue[int])) },
+		"LockFreeQueue": func() seq { return new(LockFreeQueue[int]) },
+		"LockFreeStack": func() seq { return new(LockFreeStack[int]) },
+
+		"PersistentSList": func() seq { return new(PersistentSList[int]) },
+		"PersistentList":  func() seq { return new(PersistentList[int]) },
+		"PersistentDeque": func() seq { return new(PersistentDeque[int]) },
+	}
+	r := rand.New(rand.NewSource(1))
+	for name, newSeq := range seqs {
+		for _, n := range []int{0, 1, 100} {
+			s := newSeq()
+			var e []int
+			for i := 0; i < n; i++ {
+				v := r.Intn(2000) - 1000
+				add(s, v)
+				e = append(e, v)
+			}
+			for f, dec := range formats(t, s) {
+				// decoding replaces the elements
+				d := newSeq()
+				add(d, 42)
+				if err := dec(d); err != nil {
+					t.Fatalf("%s: %s: %v", name, f, err)
+				}
+				if a := all(d); !slices.Equal(a, e) || d.Len() != n {
+					t.Errorf("%s: %s: want %v, got %v", name, f, e, a)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodingCapacity(t *testing.T) {
+	src := NewRingBuffer[int](8, OverflowReject)
+	for i := 0; i < 8; i++ {
+		src.Push(i)
+	}
+	// decoding increases the capacity if needed
+	for f, dec := range formats(t, src) {
+		b := NewRingBuffer[int](2, OverflowReject)
+		q := NewBlockingQueue[int](2)
+		if err := dec(b); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if err := dec(q); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if b.Cap() != 8 || b.Len() != 8 {
+			t.Errorf("%s: want %d, got %d", f, 8, b.Cap())
+		}
+		if q.Cap() != 8 || q.Len() != 8 {
+			t.Errorf("%s: want %d, got %d", f, 8, q.Cap())
+		}
+	}
+}
+
+func TestEncodingHashTables(t *testing.T) {
+	type table interface {
+		codec
+		Len() int
+		Put(k string, v int) (int, bool)
+		All() iter.Seq2[string, int]
+	}
+	calls := 0
+	hash := func(k string) uint64 {
+		calls++
+		return HashCode(k)
+	}
+	tables := map[string]func() table{
+		"ChainedHashTable": func() table { return NewChainedHashTable[string, int](hash) },
+		"LinearHashTable":  func() table { return NewLinearHashTable[string, int](hash) },
+	}
+	for name, newTable := range tables {
+		for _, n := range []int{0, 1, 100} {
+			h := newTable()
+			e := make(map[string]int)
+			for i := 0; i < n; i++ {
+				k := "k" + strconv.Itoa(i)
+				h.Put(k, i)
+				e[k] = i
+			}
+			for f, dec := range formats(t, h) {
+				// decoding replaces the entries
+				// and keeps the hash function
+				d := newTable()
+				d.Put("x", 42)
+				calls = 0
+				if err := dec(d); err != nil {
+					t.Fatalf("%s: %s: %v", name, f, err)
+				}
+				if a := maps.Collect(d.All()); !maps.Equal(a, e) || d.Len() != n {
+					t.Errorf("%s: %s: want %v, got %v", name, f, e, a)
+				}
+				if calls < n {
+					t.Errorf("%s: %s: want at least %d calls, got %d", name, f, n, calls)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodingSortedSets(t *testing.T) {
+	type set interface {
+		codec
+		SSet[uint32]
+	}
+	sets := map[string]func() set{
+		"BinarySearchTree": func() set { return NewBinarySearchTree(cmp.Compare[uint32]) },
+		"Treap":            func() set { return NewTreap(cmp.Compare[uint32]) },
+		"ScapegoatTree":    func() set { return NewScapegoatTree(cmp.Compare[uint32]) },
+		"RedBlackTree":     func() set { return NewRedBlackTree(cmp.Compare[uint32]) },
+		"SkiplistSSet":     func() set { return NewSkiplistSSet(cmp.Compare[uint32]) },
+		"BinaryTrie":       func() set { return new(BinaryTrie[uint32]) },
+		"XFastTrie":        func() set { return new(XFastTrie[uint32]) },
+		"YFastTrie":        func() set { return new(YFastTrie[uint32]) },
+	}
+	r := rand.New(rand.NewSource(1))
+	for name, newSet := range sets {
+		s := newSet()
+		var e []uint32
+		for i := 0; i < 200; i++ {
+			v := uint32(r.Intn(1000))
+			if s.Add(v) {
+				e = append(e, v)
+			}
+		}
+		slices.Sort(e)
+		for f, dec := range formats(t, s) {
+			d := newSet()
+			d.Add(1000)
+			if err := dec(d); err != nil {
+				t.Fatalf("%s: %s: %v", name, f, err)
+			}
+			if a := slices.Collect(d.All()); !slices.Equal(a, e) || d.Len() != len(e) {
+				t.Errorf("%s: %s: want %v, got %v", name, f, e, a)
+			}
+		}
+	}
+}
+
+func TestEncodingHeaps(t *testing.T) {
+	type heap interface {
+		codec
+		PriorityQueue[int]
+	}
+	heaps := map[string]func() heap{
+		"BinaryHeap":   func() heap { return NewBinaryHeap(cmp.Compare[int]) },
+		"MeldableHeap": func() heap { return NewMeldableHeap(cmp.Compare[int]) },
+	}
+	r := rand.New(rand.NewSource(1))
+	for name, newHeap := range heaps {
+		h := newHeap()
+		var e []int
+		for i := 0; i < 100; i++ {
+			v := r.Intn(1000)
+			h.Add(v)
+			e = append(e, v)
+		}
+		slices.Sort(e)
+		for f, dec := range formats(t, h) {
+			d := newHeap()
+			d.Add(-1)
+			if err := dec(d); err != nil {
+				t.Fatalf("%s: %s: %v", name, f, err)
+			}
+			var a []int
+			for d.Len() > 0 {
+				v, _ := d.Remove()
+				a = append(a, v)
+			}
+			if !slices.Equal(a, e) {
+				t.Errorf("%s: %s: want %v, got %v", name, f, e, a)
+			}
+		}
+	}
+}
+
+type point struct{ X, Y int16 }
+
+type name struct{ first, last string }
+
+func (n name) MarshalBinary() ([]byte, error) {
+	return []byte(n.first + " " + n.last), nil
+}
+
+func (n *name) UnmarshalBinary(b []byte) error {
+	var ok bool
+	if n.first, n.last, ok = strings.Cut(string(b), " "); !ok {
+		return errors.New("invalid name")
+	}
+	return nil
+}
+
+func TestEncodingBinary(t *testing.T) {
+	var s DList[string]
+	s.AddAll(0, "", "a", "ö", strings.Repeat("x", 300))
+	var u ArrayQueue[uint]
+	u.Enqueue(0)
+	u.Enqueue(1 << 63)
+	var p Dequeue[point]
+	p.AddAll(0, point{1, -2}, point{-32768, 32767})
+	var f Array[float64]
+	f.AddAll(0, 1.5, -0.25)
+	var n SList[name]
+	n.Push(name{"Ada", "Lovelace"})
+	n.Push(name{"Alan", "Turing"})
+	var z Array[struct{}]
+	z.AddAll(0, struct{}{}, struct{}{}, struct{}{})
+	var m ChainedHashTable[string, point]
+	m.Put("a", point{1, 2})
+	m.Put("bc", point{-3, 4})
+
+	for _, tt := range []struct {
+		in, out encoding.BinaryUnmarshaler
+		all     func(encoding.BinaryUnmarshaler) any
+	}{
+		{&s, new(DList[string]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*DList[string]).All()) }},
+		{&u, new(ArrayQueue[uint]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*ArrayQueue[uint]).All()) }},
+		{&p, new(Dequeue[point]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*Dequeue[point]).All()) }},
+		{&f, new(Array[float64]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*Array[float64]).All()) }},
+		{&n, new(SList[name]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*SList[name]).All()) }},
+		{&z, new(Array[struct{}]), func(c encoding.BinaryUnmarshaler) any { return collect(c.(*Array[struct{}]).All()) }},
+		{&m, new(ChainedHashTable[string, point]), func(c encoding.BinaryUnmarshaler) any {
+			return maps.Collect(c.(*ChainedHashTable[string, point]).All())
+		}},
+	} {
+		b, err := tt.in.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.out.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.all(tt.in), tt.all(tt.out); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+		for i := 0; i < len(b); i++ {
+			if err := tt.out.UnmarshalBinary(b[:i]); err == nil {
+				t.Errorf("%T: truncated to %d bytes: error expected", tt.in, i)
+			}
+		}
+	}
+}
+
+func TestEncodingErrors(t *testing.T) {
+	var c Array[chan int]
+	c.Add(0, make(chan int))
+	if _, err := json.Marshal(&c); err == nil || !strings.Contains(err.Error(), "chan int") {
+		t.Errorf("want error about chan int, got %v", err)
+	}
+	if err := gob.NewEncoder(new(bytes.Buffer)).Encode(&c); err == nil || !strings.Contains(err.Error(), "chan int") {
+		t.Errorf("want error about chan int, got %v", err)
+	}
+	if _, err := c.MarshalBinary(); err == nil || !strings.Contains(err.Error(), "chan int") {
+		t.Errorf("want error about chan int, got %v", err)
+	}
+
+	var v DList[V]
+	v.Add(0, 1)
+	if _, err := v.MarshalBinary(); err == nil {
+		t.Errorf("binary encoding of interface elements: error expected")
+	}
+	var s Array[[]int]
+	if err := s.UnmarshalBinary([]byte{0}); err == nil {
+		t.Errorf("binary decoding of slice elements: error expected")
+	}
+
+	var i Array[int]
+	if err := json.Unmarshal([]byte(`["a"]`), &i); err == nil || !strings.Contains(err.Error(), "int") {
+		t.Errorf("want error about int, got %v", err)
+	}
+	if err := i.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Errorf("trailing bytes: error expected")
+	}
+
+	// the numbers of keys and values differ
+	var h LinearHashTable[int, int]
+	if err := h.UnmarshalBinary([]byte{1, 2, 0}); err != errBinary {
+		t.Errorf("want %v, got %v", errBinary, err)
+	}
+
+	// decoding fails without wrapped data structure
+	for _, c := range []codec{
+		new(SyncList[int]),
+		new(SyncStack[int]),
+		new(SyncQueue[int]),
+		new(SyncDeque[int]),
+	} {
+		if err := c.UnmarshalJSON([]byte(`[1]`)); err != errUnwrapped {
+			t.Errorf("%T: want %v, got %v", c, errUnwrapped, err)
+		}
+	}
+
+	// decoding fails without comparison function
+	for _, c := range []codec{
+		new(BinarySearchTree[int]),
+		new(Treap[int]),
+		new(ScapegoatTree[int]),
+		new(RedBlackTree[int]),
+		new(SkiplistSSet[int]),
+		new(BinaryHeap[int]),
+		new(MeldableHeap[int]),
+	} {
+		if err := c.UnmarshalJSON([]byte(`[1]`)); err != errNoCmp {
+			t.Errorf("%T: want %v, got %v", c, errNoCmp, err)
+		}
+	}
+}
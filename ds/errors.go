@@ -0,0 +1,202 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The operations of the data structures report failures
+// by a boolean. The following functions perform the same
+// operations on any data structure with the respective
+// methods, but return an error which tells why they
+// failed: an *IndexError, ErrEmpty or ErrNotFound. A zero
+// value with a nil error is an element which was stored.
+// The functions have the time complexity of the operation.
+
+var (
+	// ErrEmpty is returned when an element
+	// is requested from an empty data structure.
+	ErrEmpty = errors.New("ds: empty data structure")
+
+	// ErrNotFound is returned when no
+	// matching element is contained.
+	ErrNotFound = errors.New("ds: element not found")
+)
+
+// IndexError is returned when an index
+// is out of the range of a list.
+type IndexError struct {
+	Index int // requested index
+	Len   int // length of the list
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("ds: index %d out of range with length %d", e.Index, e.Len)
+}
+
+// GetE returns the element at the given index
+// or an *IndexError if it is out of range. Besides
+// lists, it accepts e.g. a RingBuffer and the
+// persistent lists.
+func GetE[T any](l interface {
+	Len() int
+	Get(i int) (T, bool)
+}, i int) (T, error) {
+	v, ok := l.Get(i)
+	if !ok {
+		return v, &IndexError{Index: i, Len: l.Len()}
+	}
+	return v, nil
+}
+
+// SetE sets the element at the given index and
+// returns the old one or an *IndexError if the
+// index is out of range.
+func SetE[T any](l List[T], i int, v T) (T, error) {
+	n := l.Len()
+	old, ok := l.Set(i, v)
+	if !ok {
+		return old, &IndexError{Index: i, Len: n}
+	}
+	return old, nil
+}
+
+// AddE adds an element at the given index or
+// returns an *IndexError if it is out of range.
+// An element can be added at index Len().
+func AddE[T any](l List[T], i int, v T) error {
+	n := l.Len()
+	if !l.Add(i, v) {
+		return &IndexError{Index: i, Len: n}
+	}
+	return nil
+}
+
+// RemoveE removes the element at the given
+// index and returns it or an *IndexError
+// if the index is out of range.
+func RemoveE[T any](l List[T], i int) (T, error) {
+	n := l.Len()
+	v, ok := l.Remove(i)
+	if !ok {
+		return v, &IndexError{Index: i, Len: n}
+	}
+	return v, nil
+}
+
+// SetPersistentE returns a version of the
+// persistent list with the element at the
+// given index set and the old element, or an
+// *IndexError if the index is out of range.
+func SetPersistentE[L interface {
+	Len() int
+	Set(i int, v T) (L, T, bool)
+}, T any](l L, i int, v T) (L, T, error) {
+	u, old, ok := l.Set(i, v)
+	if !ok {
+		return u, old, &IndexError{Index: i, Len: l.Len()}
+	}
+	return u, old, nil
+}
+
+// AddPersistentE returns a version of the
+// persistent list with the element added at
+// the given index, or an *IndexError if the
+// index is out of range. An element can be
+// added at index Len().
+func AddPersistentE[L interface {
+	Len() int
+	Add(i int, v T) (L, bool)
+}, T any](l L, i int, v T) (L, error) {
+	u, ok := l.Add(i, v)
+	if !ok {
+		return u, &IndexError{Index: i, Len: l.Len()}
+	}
+	return u, nil
+}
+
+// RemovePersistentE returns a version of the
+// persistent list without the element at the
+// given index and the element, or an *IndexError
+// if the index is out of range.
+func RemovePersistentE[L interface {
+	Len() int
+	Remove(i int) (L, T, bool)
+}, T any](l L, i int) (L, T, error) {
+	u, v, ok := l.Remove(i)
+	if !ok {
+		return u, v, &IndexError{Index: i, Len: l.Len()}
+	}
+	return u, v, nil
+}
+
+// GetKeyE returns the element of the given key
+// or ErrNotFound if there is no such element.
+func GetKeyE[K, T any](m interface{ Get(k K) (T, bool) }, k K) (T, error) {
+	return found(m.Get(k))
+}
+
+// RemoveKeyE removes and returns the element of the
+// given key or ErrNotFound if there is no such element.
+func RemoveKeyE[K, T any](m interface{ Remove(k K) (T, bool) }, k K) (T, error) {
+	return found(m.Remove(k))
+}
+
+// PopE removes and returns the element on
+// top of the stack or ErrEmpty if it is empty.
+func PopE[T any](s Stack[T]) (T, error) { return empty(s.Pop()) }
+
+// DequeueE removes and returns the element at
+// the head of the queue or ErrEmpty if it is empty.
+func DequeueE[T any](q Queue[T]) (T, error) { return empty(q.Dequeue()) }
+
+// RemoveFirstE removes and returns the element at
+// the front of the deque or ErrEmpty if it is empty.
+func RemoveFirstE[T any](d Deque[T]) (T, error) { return empty(d.RemoveFirst()) }
+
+// RemoveLastE removes and returns the element at
+// the back of the deque or ErrEmpty if it is empty.
+func RemoveLastE[T any](d Deque[T]) (T, error) { return empty(d.RemoveLast()) }
+
+// RemoveMinE removes and returns the smallest element
+// of the priority queue or ErrEmpty if it is empty.
+func RemoveMinE[T any](q PriorityQueue[T]) (T, error) { return empty(q.Remove()) }
+
+// PeekE returns the smallest element of a priority
+// queue or the element at the head of a queue, e.g.
+// a RingBuffer or BlockingQueue, without removing
+// it, or ErrEmpty if it is empty.
+func PeekE[T any](q interface{ Peek() (T, bool) }) (T, error) { return empty(q.Peek()) }
+
+// MinE returns the smallest element of
+// the sorted set or ErrEmpty if it is empty.
+func MinE[T any](s SSet[T]) (T, error) { return empty(s.Min()) }
+
+// MaxE returns the largest element of the
+// sorted set or ErrEmpty if it is empty.
+func MaxE[T any](s SSet[T]) (T, error) { return empty(s.Max()) }
+
+// FindE returns the smallest element of the sorted
+// set which is greater than or equal to v, or
+// ErrNotFound if there is no such element.
+func FindE[T any](s SSet[T], v T) (T, error) { return found(s.Find(v)) }
+
+// found returns ErrNotFound if ok is false.
+func found[T any](v T, ok bool) (T, error) {
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+// empty returns ErrEmpty if ok is false.
+func empty[T any](v T, ok bool) (T, error) {
+	if !ok {
+		return v, ErrEmpty
+	}
+	return v, nil
+}
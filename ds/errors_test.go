@@ -0,0 +1,148 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	var l DList[*int]
+	if err := AddE[*int](&l, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	// a stored nil is no error
+	if v, err := GetE[*int](&l, 0); v != nil || err != nil {
+		t.Errorf("want (nil, nil), got (%v, %v)", v, err)
+	}
+
+	var ie *IndexError
+	for _, err := range []error{
+		AddE[*int](&l, 2, nil),
+		func() error { _, err := GetE[*int](&l, 1); return err }(),
+		func() error { _, err := SetE[*int](&l, -1, nil); return err }(),
+		func() error { _, err := RemoveE[*int](&l, 1); return err }(),
+	} {
+		if !errors.As(err, &ie) {
+			t.Fatalf("want *IndexError, got %v", err)
+		}
+		if ie.Len != 1 {
+			t.Errorf("want %d, got %d", 1, ie.Len)
+		}
+	}
+	if want := "ds: index 1 out of range with length 1"; ie.Error() != want {
+		t.Errorf("want %q, got %q", want, ie.Error())
+	}
+
+	var d Dequeue[int]
+	var q ArrayQueue[int]
+	var s ArrayStack[int]
+	h := NewBinaryHeap(cmp.Compare[int])
+	r := NewRedBlackTree(cmp.Compare[int])
+	for _, f := range []func() (int, error){
+		func() (int, error) { return RemoveFirstE[int](&d) },
+		func() (int, error) { return RemoveLastE[int](&d) },
+		func() (int, error) { return DequeueE[int](&q) },
+		func() (int, error) { return PopE[int](&s) },
+		func() (int, error) { return RemoveMinE[int](h) },
+		func() (int, error) { return PeekE[int](h) },
+		func() (int, error) { return MinE[int](r) },
+		func() (int, error) { return MaxE[int](r) },
+	} {
+		if _, err := f(); !errors.Is(err, ErrEmpty) {
+			t.Errorf("want %v, got %v", ErrEmpty, err)
+		}
+	}
+
+	r.Add(1)
+	if v, err := FindE[int](r, 1); err != nil || v != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+	if _, err := FindE[int](r, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want %v, got %v", ErrNotFound, err)
+	}
+	if v, err := MaxE[int](r); err != nil || v != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+
+	// the queues with Peek and Get
+	rb := NewRingBuffer[int](2, OverflowReject)
+	var bq BlockingQueue[int]
+	var ps PersistentSList[int]
+	for _, f := range []func() (int, error){
+		func() (int, error) { return PeekE(rb) },
+		func() (int, error) { return PeekE(&bq) },
+		func() (int, error) { return PeekE(ps) },
+	} {
+		if _, err := f(); !errors.Is(err, ErrEmpty) {
+			t.Errorf("want %v, got %v", ErrEmpty, err)
+		}
+	}
+	rb.Push(1)
+	if v, err := PeekE(rb); err != nil || v != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+	if _, err := GetE(rb, 1); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if v, err := GetE(rb, 0); err != nil || v != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+
+	// the persistent lists
+	ps = ps.Push(1)
+	var pl PersistentList[int]
+	pl = pl.AddLast(1)
+	if _, err := GetE(ps, 1); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if _, err := GetE(pl, -1); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if _, _, err := RemovePersistentE(ps, 1); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if u, v, err := RemovePersistentE(pl, 0); err != nil || v != 1 || u.Len() != 0 || pl.Len() != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+	if _, _, err := SetPersistentE(ps, -1, 2); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if u, v, err := SetPersistentE(pl, 0, 2); err != nil || v != 1 || collect(u.All())[0] != 2 || collect(pl.All())[0] != 1 {
+		t.Errorf("want %d, got %d (%v)", 1, v, err)
+	}
+	if _, err := AddPersistentE(ps, 2, 2); !errors.As(err, &ie) || ie.Len != 1 {
+		t.Errorf("want *IndexError, got %v", err)
+	}
+	if u, err := AddPersistentE(pl, 1, 2); err != nil || u.Len() != 2 || pl.Len() != 1 {
+		t.Errorf("want %d, got %d (%v)", 2, u.Len(), err)
+	}
+
+	// the hash tables
+	for _, m := range []interface {
+		Get(k string) (int, bool)
+		Put(k string, v int) (int, bool)
+		Remove(k string) (int, bool)
+	}{
+		NewChainedHashTable[string, int](HashCode[string]),
+		NewLinearHashTable[string, int](HashCode[string]),
+	} {
+		m.Put("a", 1)
+		if v, err := GetKeyE(m, "a"); err != nil || v != 1 {
+			t.Errorf("want %d, got %d (%v)", 1, v, err)
+		}
+		if v, err := RemoveKeyE(m, "a"); err != nil || v != 1 {
+			t.Errorf("want %d, got %d (%v)", 1, v, err)
+		}
+		if _, err := GetKeyE(m, "a"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("want %v, got %v", ErrNotFound, err)
+		}
+		if _, err := RemoveKeyE(m, "a"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("want %v, got %v", ErrNotFound, err)
+		}
+	}
+}
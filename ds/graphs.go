@@ -0,0 +1,285 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import "iter"
+
+// --- AdjacencyMatrix -------
+
+// AdjacencyMatrix implements a directed
+// graph with the vertices 0, ..., n-1 on
+// top of an n×n matrix.
+type AdjacencyMatrix struct {
+	a []bool // backing matrix, in row-major order
+	n int    // number of vertices
+	m int    // number of edges
+}
+
+// NewAdjacencyMatrix returns a graph
+// with n vertices and no edges.
+func NewAdjacencyMatrix(n int) *AdjacencyMatrix {
+	return &AdjacencyMatrix{a: make([]bool, n*n), n: n}
+}
+
+// Len returns the number of vertices.
+func (g *AdjacencyMatrix) Len() int { return g.n }
+
+// Edges returns the number of edges.
+func (g *AdjacencyMatrix) Edges() int { return g.m }
+
+func (g *AdjacencyMatrix) valid(i, j int) bool {
+	return i >= 0 && i < g.n && j >= 0 && j < g.n
+}
+
+// AddEdge adds the edge (i, j) and reports
+// whether it was added, i.e. both vertices
+// exist and the edge did not.
+//
+// This operation has a time complexity of O(1).
+func (g *AdjacencyMatrix) AddEdge(i, j int) bool {
+	if !g.valid(i, j) || g.a[i*g.n+j] {
+		return false
+	}
+	g.a[i*g.n+j] = true
+	g.m++
+	return true
+}
+
+// RemoveEdge removes the edge (i, j) and
+// reports whether it was removed, i.e. it
+// existed.
+//
+// This operation has a time complexity of O(1).
+func (g *AdjacencyMatrix) RemoveEdge(i, j int) bool {
+	if !g.valid(i, j) || !g.a[i*g.n+j] {
+		return false
+	}
+	g.a[i*g.n+j] = false
+	g.m--
+	return true
+}
+
+// HasEdge reports whether the edge (i, j) exists.
+//
+// This operation has a time complexity of O(1).
+func (g *AdjacencyMatrix) HasEdge(i, j int) bool {
+	return g.valid(i, j) && g.a[i*g.n+j]
+}
+
+// OutEdges returns an iterator over all
+// vertices j such that the edge (i, j)
+// exists, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (g *AdjacencyMatrix) OutEdges(i int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if i < 0 || i >= g.n {
+			return
+		}
+		for j := 0; j < g.n; j++ {
+			if g.a[i*g.n+j] && !yield(j) {
+				return
+			}
+		}
+	}
+}
+
+// InEdges returns an iterator over all
+// vertices j such that the edge (j, i)
+// exists, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (g *AdjacencyMatrix) InEdges(i int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if i < 0 || i >= g.n {
+			return
+		}
+		for j := 0; j < g.n; j++ {
+			if g.a[j*g.n+i] && !yield(j) {
+				return
+			}
+		}
+	}
+}
+
+// --- AdjacencyLists -------
+
+// AdjacencyLists implements a directed
+// graph with the vertices 0, ..., n-1
+// on top of a list of out-neighbours
+// for each vertex.
+type AdjacencyLists struct {
+	a []Array[int] // backing lists
+	m int          // number of edges
+}
+
+// NewAdjacencyLists returns a graph
+// with n vertices and no edges.
+func NewAdjacencyLists(n int) *AdjacencyLists {
+	return &AdjacencyLists{a: make([]Array[int], n)}
+}
+
+// Len returns the number of vertices.
+func (g *AdjacencyLists) Len() int { return len(g.a) }
+
+// Edges returns the number of edges.
+func (g *AdjacencyLists) Edges() int { return g.m }
+
+// index returns the index of j in the
+// list of i, or -1 if there is none.
+func (g *AdjacencyLists) index(i, j int) int {
+	if i < 0 || i >= len(g.a) || j < 0 || j >= len(g.a) {
+		return -1
+	}
+	for k, v := range g.a[i].All() {
+		if v == j {
+			return k
+		}
+	}
+	return -1
+}
+
+// AddEdge adds the edge (i, j) and reports
+// whether it was added, i.e. both vertices
+// exist and the edge did not.
+//
+// This operation has an amortized time
+// complexity of O(deg(i)).
+func (g *AdjacencyLists) AddEdge(i, j int) bool {
+	if i < 0 || i >= len(g.a) || j < 0 || j >= len(g.a) || g.index(i, j) >= 0 {
+		return false
+	}
+	g.a[i].Add(g.a[i].Len(), j)
+	g.m++
+	return true
+}
+
+// RemoveEdge removes the edge (i, j) and
+// reports whether it was removed, i.e. it
+// existed.
+//
+// This operation has an amortized time
+// complexity of O(deg(i)).
+func (g *AdjacencyLists) RemoveEdge(i, j int) bool {
+	k := g.index(i, j)
+	if k < 0 {
+		return false
+	}
+	g.a[i].Remove(k)
+	g.m--
+	return true
+}
+
+// HasEdge reports whether the edge (i, j) exists.
+//
+// This operation has a time complexity of O(deg(i)).
+func (g *AdjacencyLists) HasEdge(i, j int) bool { return g.index(i, j) >= 0 }
+
+// OutEdges returns an iterator over all
+// vertices j such that the edge (i, j)
+// exists, in the order the edges were added.
+//
+// This operation has a time complexity of O(deg(i)).
+func (g *AdjacencyLists) OutEdges(i int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if i < 0 || i >= len(g.a) {
+			return
+		}
+		for _, j := range g.a[i].All() {
+			if !yield(j) {
+				return
+			}
+		}
+	}
+}
+
+// InEdges returns an iterator over all
+// vertices j such that the edge (j, i)
+// exists, in ascending order.
+//
+// This operation has a time complexity
+// of O(n + m), where m is the number
+// of edges.
+func (g *AdjacencyLists) InEdges(i int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if i < 0 || i >= len(g.a) {
+			return
+		}
+		for j := range g.a {
+			if g.index(j, i) >= 0 && !yield(j) {
+				return
+			}
+		}
+	}
+}
+
+// --- Traversals -------
+
+// BFS returns an iterator over the vertices
+// reachable from r, in breadth-first order.
+//
+// This operation has a time complexity of
+// O(n + m) for adjacency lists and O(n^2)
+// for an adjacency matrix.
+func BFS(g Graph, r int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if r < 0 || r >= g.Len() {
+			return
+		}
+		seen := make([]bool, g.Len())
+		var q ArrayQueue[int]
+		q.Enqueue(r)
+		seen[r] = true
+		for q.Len() > 0 {
+			i, _ := q.Dequeue()
+			if !yield(i) {
+				return
+			}
+			for j := range g.OutEdges(i) {
+				if !seen[j] {
+					seen[j] = true
+					q.Enqueue(j)
+				}
+			}
+		}
+	}
+}
+
+// DFS returns an iterator over the vertices
+// reachable from r, in depth-first order.
+//
+// This operation has a time complexity of
+// O(n + m) for adjacency lists and O(n^2)
+// for an adjacency matrix.
+func DFS(g Graph, r int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if r < 0 || r >= g.Len() {
+			return
+		}
+		seen := make([]bool, g.Len())
+		var s, t ArrayStack[int]
+		s.Push(r)
+		for s.Len() > 0 {
+			i, _ := s.Pop()
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			if !yield(i) {
+				return
+			}
+			// push in reverse to visit the out-edges in order
+			for j := range g.OutEdges(i) {
+				t.Push(j)
+			}
+			for t.Len() > 0 {
+				j, _ := t.Pop()
+				if !seen[j] {
+					s.Push(j)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	const n, m = 50, 2000
+	graphs := map[string]Graph{
+		"AdjacencyMatrix": NewAdjacencyMatrix(n),
+		"AdjacencyLists":  NewAdjacencyLists(n),
+	}
+	for name, g := range graphs {
+		if g.Len() != n {
+			t.Errorf("%s: want %d, got %d", name, n, g.Len())
+		}
+		for _, e := range [][2]int{{-1, 0}, {0, -1}, {n, 0}, {0, n}} {
+			if g.AddEdge(e[0], e[1]) {
+				t.Errorf("%s: cannot add edge %v", name, e)
+			}
+			if g.HasEdge(e[0], e[1]) {
+				t.Errorf("%s: no edge %v expected", name, e)
+			}
+		}
+
+		r := rand.New(rand.NewSource(1))
+		var e [n][n]bool
+		edges := 0
+		for k := 0; k < m; k++ {
+			i, j := r.Intn(n), r.Intn(n)
+			if r.Intn(3) > 0 {
+				if ok := g.AddEdge(i, j); ok == e[i][j] {
+					t.Fatalf("%s: add (%d, %d): want %t, got %t", name, i, j, !e[i][j], ok)
+				}
+				if !e[i][j] {
+					edges++
+				}
+				e[i][j] = true
+			} else {
+				if ok := g.RemoveEdge(i, j); ok != e[i][j] {
+					t.Fatalf("%s: remove (%d, %d): want %t, got %t", name, i, j, e[i][j], ok)
+				}
+				if e[i][j] {
+					edges--
+				}
+				e[i][j] = false
+			}
+		}
+		if g.Edges() != edges {
+			t.Errorf("%s: want %d, got %d", name, edges, g.Edges())
+		}
+
+		for i := 0; i < n; i++ {
+			var out, in []int
+			for j := 0; j < n; j++ {
+				if g.HasEdge(i, j) != e[i][j] {
+					t.Errorf("%s: edge (%d, %d): want %t", name, i, j, e[i][j])
+				}
+				if e[i][j] {
+					out = append(out, j)
+				}
+				if e[j][i] {
+					in = append(in, j)
+				}
+			}
+			if a := slices.Sorted(g.OutEdges(i)); !slices.Equal(a, out) {
+				t.Errorf("%s: out-edges of %d: want %v, got %v", name, i, out, a)
+			}
+			if a := slices.Collect(g.InEdges(i)); !slices.Equal(a, in) {
+				t.Errorf("%s: in-edges of %d: want %v, got %v", name, i, in, a)
+			}
+		}
+	}
+}
+
+func TestTraversal(t *testing.T) {
+	//  0 → 1 → 3
+	//  ↓   ↓
+	//  2 → 4   5 → 0
+	edges := [][2]int{{0, 1}, {0, 2}, {1, 3}, {1, 4}, {2, 4}, {5, 0}}
+	graphs := map[string]Graph{
+		"AdjacencyMatrix": NewAdjacencyMatrix(6),
+		"AdjacencyLists":  NewAdjacencyLists(6),
+	}
+	for name, g := range graphs {
+		for _, e := range edges {
+			g.AddEdge(e[0], e[1])
+		}
+		if a, e := slices.Collect(BFS(g, 0)), []int{0, 1, 2, 3, 4}; !slices.Equal(a, e) {
+			t.Errorf("%s: BFS: want %v, got %v", name, e, a)
+		}
+		if a, e := slices.Collect(DFS(g, 0)), []int{0, 1, 3, 4, 2}; !slices.Equal(a, e) {
+			t.Errorf("%s: DFS: want %v, got %v", name, e, a)
+		}
+		if a, e := slices.Collect(BFS(g, 5)), []int{5, 0, 1, 2, 3, 4}; !slices.Equal(a, e) {
+			t.Errorf("%s: BFS: want %v, got %v", name, e, a)
+		}
+		if a := slices.Collect(DFS(g, 6)); len(a) != 0 {
+			t.Errorf("%s: DFS: want no vertices, got %v", name, a)
+		}
+		for i := range BFS(g, 0) {
+			if i == 1 {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,618 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The harness drives a list, stack or queue with a sequence
+// of operations and compares every result with a slice.
+// After each operation, it checks the contents and the
+// structural invariants of the data structure. Failing
+// sequences are shrunk to a minimal reproducer.
+
+// opKind is the kind of an operation.
+type opKind uint8
+
+const (
+	opGet opKind = iota
+	opSet
+	opAdd
+	opRemove
+	opAddFirst
+	opAddLast
+	opRemoveFirst
+	opRemoveLast
+	opPush
+	opPop
+	opEnqueue
+	opDequeue
+	opAddAll
+	opRemoveRange
+	opSlice
+	opReverse
+	opClear
+)
+
+var (
+	listOps  = []opKind{opGet, opSet, opAdd, opRemove}
+	dequeOps = []opKind{opAddFirst, opAddLast, opRemoveFirst, opRemoveLast}
+	stackOps = []opKind{opPush, opPop}
+	queueOps = []opKind{opEnqueue, opDequeue}
+	bulkOps  = []opKind{opAddAll, opRemoveRange, opSlice, opReverse, opClear}
+)
+
+// op is an operation with an index and a value.
+type op struct {
+	k opKind
+	i int
+	v int
+}
+
+// values returns the elements added by AddAll.
+func (o op) values() []int {
+	vs := make([]int, o.v%4)
+	for j := range vs {
+		vs[j] = o.v + j
+	}
+	return vs
+}
+
+// bounds returns the range of RemoveRange and Slice.
+func (o op) bounds() (int, int) { return o.i, o.i + o.v%5 - 1 }
+
+func (o op) String() string {
+	switch o.k {
+	case opGet:
+		return fmt.Sprintf("Get(%d)", o.i)
+	case opSet:
+		return fmt.Sprintf("Set(%d, %d)", o.i, o.v)
+	case opAdd:
+		return fmt.Sprintf("Add(%d, %d)", o.i, o.v)
+	case opRemove:
+		return fmt.Sprintf("Remove(%d)", o.i)
+	case opAddFirst:
+		return fmt.Sprintf("AddFirst(%d)", o.v)
+	case opAddLast:
+		return fmt.Sprintf("AddLast(%d)", o.v)
+	case opRemoveFirst:
+		return "RemoveFirst()"
+	case opRemoveLast:
+		return "RemoveLast()"
+	case opPush:
+		return fmt.Sprintf("Push(%d)", o.v)
+	case opPop:
+		return "Pop()"
+	case opEnqueue:
+		return fmt.Sprintf("Enqueue(%d)", o.v)
+	case opDequeue:
+		return "Dequeue()"
+	case opAddAll:
+		return fmt.Sprintf("AddAll(%d, %v)", o.i, o.values())
+	case opRemoveRange:
+		f, t := o.bounds()
+		return fmt.Sprintf("RemoveRange(%d, %d)", f, t)
+	case opSlice:
+		f, t := o.bounds()
+		return fmt.Sprintf("Slice(%d, %d)", f, t)
+	case opReverse:
+		return "Reverse()"
+	case opClear:
+		return "Clear()"
+	}
+	return fmt.Sprintf("op(%d)", o.k)
+}
+
+// model is the reference implementation. If c > 0,
+// it is a bounded queue with capacity c.
+type model struct {
+	s []int
+	c int
+}
+
+func (m *model) apply(o op) ([]int, bool) {
+	s := m.s
+	switch o.k {
+	case opGet, opSet, opRemove:
+		if o.i < 0 || o.i >= len(s) {
+			return nil, false
+		}
+		v := s[o.i]
+		if o.k == opSet {
+			s[o.i] = o.v
+		} else if o.k == opRemove {
+			m.s = slices.Delete(s, o.i, o.i+1)
+		}
+		return []int{v}, true
+	case opAdd:
+		if o.i < 0 || o.i > len(s) {
+			return nil, false
+		}
+		m.s = slices.Insert(s, o.i, o.v)
+	case opAddFirst:
+		m.s = slices.Insert(s, 0, o.v)
+	case opAddLast, opPush, opEnqueue:
+		if m.c > 0 && len(s) == m.c {
+			return nil, false
+		}
+		m.s = append(s, o.v)
+	case opRemoveFirst, opDequeue, opRemoveLast, opPop:
+		if len(s) == 0 {
+			return nil, false
+		}
+		i := 0
+		if o.k == opRemoveLast || o.k == opPop {
+			i = len(s) - 1
+		}
+		v := s[i]
+		m.s = slices.Delete(s, i, i+1)
+		return []int{v}, true
+	case opAddAll:
+		if o.i < 0 || o.i > len(s) {
+			return nil, false
+		}
+		m.s = slices.Insert(s, o.i, o.values()...)
+	case opRemoveRange, opSlice:
+		f, t := o.bounds()
+		if f < 0 || t > len(s) || f > t {
+			return nil, false
+		}
+		if o.k == opSlice {
+			return slices.Clone(s[f:t]), true
+		}
+		m.s = slices.Delete(s, f, t)
+	case opReverse:
+		slices.Reverse(s)
+	case opClear:
+		m.s = s[:0]
+	}
+	return nil, true
+}
+
+// one returns the result of an operation
+// which returns at most one element.
+func one(v int, ok bool) ([]int, bool) {
+	if !ok {
+		return nil, false
+	}
+	return []int{v}, true
+}
+
+// apply applies an operation to a data structure.
+func apply(c any, o op) ([]int, bool) {
+	switch o.k {
+	case opGet:
+		return one(c.(List[int]).Get(o.i))
+	case opSet:
+		return one(c.(List[int]).Set(o.i, o.v))
+	case opAdd:
+		return nil, c.(List[int]).Add(o.i, o.v)
+	case opRemove:
+		return one(c.(List[int]).Remove(o.i))
+	case opAddFirst:
+		c.(Deque[int]).AddFirst(o.v)
+	case opAddLast:
+		c.(Deque[int]).AddLast(o.v)
+	case opRemoveFirst:
+		return one(c.(Deque[int]).RemoveFirst())
+	case opRemoveLast:
+		return one(c.(Deque[int]).RemoveLast())
+	case opPush:
+		c.(Stack[int]).Push(o.v)
+	case opPop:
+		return one(c.(Stack[int]).Pop())
+	case opEnqueue:
+		switch c := c.(type) {
+		case *RingBuffer[int]:
+			return nil, c.Push(o.v)
+		case *BlockingQueue[int]:
+			return nil, c.TryEnqueue(o.v)
+		}
+		c.(Queue[int]).Enqueue(o.v)
+	case opDequeue:
+		switch c := c.(type) {
+		case *RingBuffer[int]:
+			return one(c.Pop())
+		case *BlockingQueue[int]:
+			return one(c.TryDequeue())
+		}
+		return one(c.(Queue[int]).Dequeue())
+	case opAddAll:
+		return nil, c.(bulkList[int]).AddAll(o.i, o.values()...)
+	case opRemoveRange:
+		return nil, c.(bulkList[int]).RemoveRange(o.bounds())
+	case opSlice:
+		return c.(bulkList[int]).Slice(o.bounds())
+	case opReverse:
+		c.(bulkList[int]).Reverse()
+	case opClear:
+		c.(bulkList[int]).Clear()
+	}
+	return nil, true
+}
+
+// elems returns the elements of a data structure.
+func elems(c any) []int {
+	switch c := c.(type) {
+	case *SyncList[int]:
+		return c.snapshot()
+	case *SyncStack[int]:
+		return c.snapshot()
+	case *SyncQueue[int]:
+		return c.snapshot()
+	case *SyncDeque[int]:
+		return c.snapshot()
+	case *BlockingQueue[int]:
+		return slices.Collect(c.all())
+	case *LockFreeQueue[int]:
+		return slices.Collect(c.all())
+	}
+	return collect(c.(interface{ All() iter.Seq2[int, int] }).All())
+}
+
+// bound returns the capacity of a bounded
+// queue and 0 for all other data structures.
+func bound(c any) int {
+	switch c := c.(type) {
+	case *RingBuffer[int]:
+		return c.Cap()
+	case *BlockingQueue[int]:
+		return c.Cap()
+	}
+	return 0
+}
+
+// target is a data structure driven by the harness.
+type target struct {
+	name  string
+	new   func() any
+	ops   []opKind
+	check func(c any) error // structural invariants
+}
+
+var targets = []target{
+	{"Array", func() any { return new(Array[int]) }, slices.Concat(listOps, bulkOps), nil},
+	{"ArrayQueue", func() any { return new(ArrayQueue[int]) }, queueOps, nil},
+	{"Dequeue", func() any { return new(Dequeue[int]) }, slices.Concat(listOps, dequeOps, bulkOps), nil},
+	{"DualDequeue", func() any { return new(DualDequeue[int]) }, slices.Concat(listOps, dequeOps, bulkOps), checkDualDequeue},
+	{"RootishStack", func() any { return new(RootishStack[int]) }, slices.Concat(listOps, bulkOps), checkRootishStack},
+	{"SList", func() any { return new(SList[int]) }, queueOps, nil},
+	{"DList", func() any { return new(DList[int]) }, slices.Concat(listOps, dequeOps, bulkOps), checkDList},
+	{"SEList", func() any { return new(SEList[int]) }, slices.Concat(listOps, dequeOps), nil},
+	{"SkiplistList", func() any { return new(SkiplistList[int]) }, listOps, nil},
+	{"ArrayStack", func() any { return new(ArrayStack[int]) }, stackOps, nil},
+	{"RingBuffer", func() any { return NewRingBuffer[int](8, OverflowReject) }, queueOps, nil},
+	{"BlockingQueue", func() any { return NewBlockingQueue[int](8) }, queueOps, nil},
+	{"LockFreeQueue", func() any { return new(LockFreeQueue[int]) }, queueOps, nil},
+	{"SyncList", func() any { return NewSyncList[int](new(Dequeue[int])) }, listOps, nil},
+	{"SyncStack", func() any { return NewSyncStack[int](new(ArrayStack[int])) }, stackOps, nil},
+	{"SyncQueue", func() any { return NewSyncQueue[int](new(ArrayQueue[int])) }, queueOps, nil},
+	{"SyncDeque", func() any { return NewSyncDeque[int](new(DList[int])) }, dequeOps, nil},
+	{"PersistentSList", func() any { return new(persistentSList) }, listOps, checkPersistent},
+	{"PersistentList", func() any { return new(persistentList) }, slices.Concat(listOps, dequeOps), checkPersistent},
+	{"PersistentDeque", func() any { return new(persistentDeque) }, dequeOps, checkPersistent},
+}
+
+// checkDualDequeue checks that neither stack has
+// more than three times the elements of the other.
+func checkDualDequeue(c any) error {
+	d := c.(*DualDequeue[int])
+	if f, b := d.f.Len(), d.b.Len(); f+b >= 2 && (3*f < b || 3*b < f) {
+		return fmt.Errorf("unbalanced: %d and %d elements", f, b)
+	}
+	return nil
+}
+
+// checkRootishStack checks that the blocks have sizes
+// 1, 2, ..., r and that there are neither too few nor
+// more than two unused blocks.
+func checkRootishStack(c any) error {
+	r := c.(*RootishStack[int])
+	l := r.b.Len()
+	for i := 0; i < l; i++ {
+		if b := r.block(i); len(b) != i+1 {
+			return fmt.Errorf("block %d: want size %d, got %d", i, i+1, len(b))
+		}
+	}
+	if l*(l+1)/2 < r.n || (l > 0 && (l-2)*(l-1)/2 >= r.n) {
+		return fmt.Errorf("%d blocks for %d elements", l, r.n)
+	}
+	return nil
+}
+
+// checkDList checks that the next and previous
+// pointers of all nodes, including the sentinel,
+// are consistent and that the list has n nodes.
+func checkDList(c any) error {
+	l := c.(*DList[int])
+	if l.r == nil {
+		return nil
+	}
+	n := 0
+	for x := l.r; ; x = x.n {
+		if x.n == nil || x.n.p != x {
+			return fmt.Errorf("node %d: inconsistent links", n)
+		}
+		if x.n == l.r {
+			break
+		}
+		if !l.owns(x.n) {
+			return fmt.Errorf("node %d: not in the list", n)
+		}
+		if n++; n > l.n {
+			return fmt.Errorf("more than %d nodes", l.n)
+		}
+	}
+	if n != l.n {
+		return fmt.Errorf("want %d nodes, got %d", l.n, n)
+	}
+	return nil
+}
+
+// checkPersistent checks that the version before
+// the last update has not changed.
+func checkPersistent(c any) error {
+	return c.(interface{ check() error }).check()
+}
+
+// persistent adapts a persistent data structure to the
+// harness. It keeps the version before the last update,
+// whose elements must not change.
+type persistent[P interface {
+	Len() int
+	All() iter.Seq2[int, int]
+}] struct {
+	cur, prev P
+	want      []int // elements of prev
+}
+
+func (p *persistent[P]) Len() int                 { return p.cur.Len() }
+func (p *persistent[P]) All() iter.Seq2[int, int] { return p.cur.All() }
+
+// update replaces the current version by v.
+func (p *persistent[P]) update(v P) {
+	p.prev, p.want, p.cur = p.cur, collect(p.cur.All()), v
+}
+
+func (p *persistent[P]) check() error {
+	if s := collect(p.prev.All()); !slices.Equal(s, p.want) {
+		return fmt.Errorf("previous version: want %v, got %v", p.want, s)
+	}
+	return nil
+}
+
+// persistentSList adapts a PersistentSList to List.
+type persistentSList struct {
+	persistent[PersistentSList[int]]
+}
+
+func (p *persistentSList) Get(i int) (int, bool) { return p.cur.Get(i) }
+
+func (p *persistentSList) Set(i, v int) (int, bool) {
+	l, v, ok := p.cur.Set(i, v)
+	p.update(l)
+	return v, ok
+}
+
+func (p *persistentSList) Add(i, v int) bool {
+	l, ok := p.cur.Add(i, v)
+	p.update(l)
+	return ok
+}
+
+func (p *persistentSList) Remove(i int) (int, bool) {
+	l, v, ok := p.cur.Remove(i)
+	p.update(l)
+	return v, ok
+}
+
+// persistentList adapts a PersistentList to List and Deque.
+type persistentList struct {
+	persistent[PersistentList[int]]
+}
+
+func (p *persistentList) Get(i int) (int, bool) { return p.cur.Get(i) }
+
+func (p *persistentList) Set(i, v int) (int, bool) {
+	l, v, ok := p.cur.Set(i, v)
+	p.update(l)
+	return v, ok
+}
+
+func (p *persistentList) Add(i, v int) bool {
+	l, ok := p.cur.Add(i, v)
+	p.update(l)
+	return ok
+}
+
+func (p *persistentList) Remove(i int) (int, bool) {
+	l, v, ok := p.cur.Remove(i)
+	p.update(l)
+	return v, ok
+}
+
+func (p *persistentList) AddFirst(v int) { p.update(p.cur.AddFirst(v)) }
+func (p *persistentList) AddLast(v int)  { p.update(p.cur.AddLast(v)) }
+
+func (p *persistentList) RemoveFirst() (int, bool) {
+	l, v, ok := p.cur.RemoveFirst()
+	p.update(l)
+	return v, ok
+}
+
+func (p *persistentList) RemoveLast() (int, bool) {
+	l, v, ok := p.cur.RemoveLast()
+	p.update(l)
+	return v, ok
+}
+
+// persistentDeque adapts a PersistentDeque to Deque.
+type persistentDeque struct {
+	persistent[PersistentDeque[int]]
+}
+
+func (p *persistentDeque) AddFirst(v int) { p.update(p.cur.AddFirst(v)) }
+func (p *persistentDeque) AddLast(v int)  { p.update(p.cur.AddLast(v)) }
+
+func (p *persistentDeque) RemoveFirst() (int, bool) {
+	d, v, ok := p.cur.RemoveFirst()
+	p.update(d)
+	return v, ok
+}
+
+func (p *persistentDeque) RemoveLast() (int, bool) {
+	d, v, ok := p.cur.RemoveLast()
+	p.update(d)
+	return v, ok
+}
+
+// run applies the operations to a new data structure and
+// the model. The indices of the operations are taken modulo
+// the length plus two, such that they are in [-1, len]. It
+// returns the operations with these indices and the first
+// mismatch or violated invariant.
+func run(tg target, ops []op) (trace []op, err error) {
+	c := tg.new()
+	m := model{c: bound(c)}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v: panic: %v", trace[len(trace)-1], r)
+		}
+	}()
+	for _, o := range ops {
+		k := len(m.s) + 2
+		o.i = (o.i%k+k)%k - 1
+		trace = append(trace, o)
+		wv, wok := m.apply(o)
+		v, ok := apply(c, o)
+		if ok != wok || (ok && !slices.Equal(v, wv)) {
+			return trace, fmt.Errorf("%v: want (%v, %t), got (%v, %t)", o, wv, wok, v, ok)
+		}
+		if l := c.(interface{ Len() int }).Len(); l != len(m.s) {
+			return trace, fmt.Errorf("%v: want length %d, got %d", o, len(m.s), l)
+		}
+		if s := elems(c); !slices.Equal(s, m.s) {
+			return trace, fmt.Errorf("%v: want %v, got %v", o, m.s, s)
+		}
+		if tg.check != nil {
+			if err := tg.check(c); err != nil {
+				return trace, fmt.Errorf("%v: %v", o, err)
+			}
+		}
+	}
+	return trace, nil
+}
+
+// shrink returns a subsequence of the failing
+// operations, from which no operation can be
+// removed without making it pass.
+func shrink(tg target, ops []op) []op {
+	for n := len(ops) / 2; n > 0; {
+		removed := false
+		for i := 0; i+n <= len(ops); {
+			s := slices.Delete(slices.Clone(ops), i, i+n)
+			if _, err := run(tg, s); err != nil {
+				ops, removed = s, true
+			} else {
+				i += n
+			}
+		}
+		if !removed || n > 1 {
+			n /= 2
+		}
+	}
+	return ops
+}
+
+// checkOps runs the operations and reports
+// a minimal reproducer if they fail.
+func checkOps(t *testing.T, tg target, ops []op) {
+	t.Helper()
+	if _, err := run(tg, ops); err == nil {
+		return
+	}
+	s := shrink(tg, ops)
+	trace, err := run(tg, s)
+	var b strings.Builder
+	for _, o := range trace {
+		fmt.Fprintf(&b, "\t%v\n", o)
+	}
+	t.Fatalf("%s: %v\nminimal reproducer (%d of %d operations):\n%s", tg.name, err, len(s), len(ops), b.String())
+}
+
+// randomOps returns n random operations.
+func randomOps(r *rand.Rand, tg target, n int) []op {
+	ops := make([]op, n)
+	for i := range ops {
+		ops[i] = op{k: tg.ops[r.Intn(len(tg.ops))], i: r.Intn(1 << 10), v: r.Intn(1000)}
+	}
+	return ops
+}
+
+// decodeOps decodes operations from three bytes each.
+func decodeOps(tg target, data []byte) []op {
+	var ops []op
+	for ; len(data) >= 3; data = data[3:] {
+		ops = append(ops, op{k: tg.ops[int(data[0])%len(tg.ops)], i: int(data[1]), v: int(data[2])})
+	}
+	return ops
+}
+
+func TestHarness(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, tg := range targets {
+		for k := 0; k < 50; k++ {
+			checkOps(t, tg, randomOps(r, tg, 200))
+		}
+	}
+}
+
+// faultyArray returns a wrong element at index 3.
+type faultyArray struct{ Array[int] }
+
+func (a *faultyArray) Get(i int) (int, bool) {
+	v, ok := a.Array.Get(i)
+	if i == 3 {
+		v++
+	}
+	return v, ok
+}
+
+func TestHarnessShrink(t *testing.T) {
+	tg := target{"faultyArray", func() any { return new(faultyArray) }, listOps, nil}
+	ops := randomOps(rand.New(rand.NewSource(1)), tg, 500)
+	if _, err := run(tg, ops); err == nil {
+		t.Fatalf("faulty array: error expected")
+	}
+	s := shrink(tg, ops)
+	if _, err := run(tg, s); err == nil {
+		t.Fatalf("shrunk sequence: error expected")
+	}
+	// at least four additions and a Get
+	if len(s) < 5 || len(s) > 10 {
+		t.Errorf("want between 5 and 10 operations, got %d", len(s))
+	}
+	for i := range s {
+		if _, err := run(tg, slices.Delete(slices.Clone(s), i, i+1)); err != nil {
+			t.Errorf("operation %d can be removed", i)
+		}
+	}
+}
+
+func FuzzHarness(f *testing.F) {
+	f.Add(uint8(0), []byte{2, 0, 1, 2, 0, 2, 0, 1, 0, 3, 0, 0})
+	f.Add(uint8(3), []byte{4, 0, 1, 5, 0, 2, 4, 0, 3, 6, 0, 0, 7, 0, 0})
+	f.Add(uint8(6), []byte{2, 0, 1, 2, 1, 2, 10, 0, 0, 3, 1, 0, 11, 0, 0})
+	f.Fuzz(func(t *testing.T, n uint8, data []byte) {
+		tg := targets[int(n)%len(targets)]
+		checkOps(t, tg, decodeOps(tg, data))
+	})
+}
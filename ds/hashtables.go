@@ -0,0 +1,397 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/rand"
+)
+
+// --- Hash functions -------
+
+// Hash computes the 64-bit hash of a key.
+// Hash tables use the d most significant
+// bits of the hash as index into a table
+// with 2^d slots.
+type Hash[K any] func(k K) uint64
+
+var hashSeed = maphash.MakeSeed()
+
+// HashCode returns a hash code of a
+// comparable key. The hash code is
+// randomized per process.
+func HashCode[K comparable](k K) uint64 { return maphash.Comparable(hashSeed, k) }
+
+// MultiplicativeHash returns a hash function which
+// multiplies the hash code of a key with a random
+// odd number, picked from the given seed.
+func MultiplicativeHash[K any](code func(K) uint64, seed int64) Hash[K] {
+	z := rand.New(rand.NewSource(seed)).Uint64() | 1
+	return func(k K) uint64 { return z * code(k) }
+}
+
+// TabulationHash returns a hash function which
+// combines random values, picked from the given
+// seed, for each byte of the hash code of a key.
+func TabulationHash[K any](code func(K) uint64, seed int64) Hash[K] {
+	var t [8][256]uint64
+	r := rand.New(rand.NewSource(seed))
+	for i := range t {
+		for j := range t[i] {
+			t[i][j] = r.Uint64()
+		}
+	}
+	return func(k K) uint64 {
+		x, h := code(k), uint64(0)
+		for i := range t {
+			h ^= t[i][byte(x>>(8*i))]
+		}
+		return h
+	}
+}
+
+// --- ChainedHashTable -------
+
+// entry represents a key-value
+// pair in a hash table.
+type entry[K, T any] struct {
+	k K // key
+	v T // value
+}
+
+// ChainedHashTable implements a hash table
+// which stores colliding keys in dynamic
+// arrays. The zero value is an empty hash
+// table which uses HashCode as hash function.
+type ChainedHashTable[K comparable, T any] struct {
+	t    []Array[entry[K, T]] // backing chains
+	n    int                  // number of elements
+	d    int                  // dimension, len(t) = 2^d
+	hash Hash[K]              // hash function
+}
+
+// NewChainedHashTable returns an empty hash
+// table which uses the given hash function.
+func NewChainedHashTable[K comparable, T any](h Hash[K]) *ChainedHashTable[K, T] {
+	return &ChainedHashTable[K, T]{hash: h}
+}
+
+// init lazily initializes the table
+// and the hash function.
+func (h *ChainedHashTable[K, T]) init() {
+	if h.hash == nil {
+		h.hash = HashCode[K]
+	}
+	if h.t == nil {
+		h.d = 1
+		h.t = make([]Array[entry[K, T]], 1<<h.d)
+	}
+}
+
+// chain returns the chain of the given key.
+func (h *ChainedHashTable[K, T]) chain(k K) *Array[entry[K, T]] {
+	return &h.t[h.hash(k)>>(64-h.d)]
+}
+
+// Len returns the number of
+// elements in the hash table.
+func (h *ChainedHashTable[K, T]) Len() int { return h.n }
+
+// LoadFactor returns the ratio of the number
+// of elements to the number of chains.
+func (h *ChainedHashTable[K, T]) LoadFactor() float64 {
+	if h.n == 0 {
+		return 0
+	}
+	return float64(h.n) / float64(len(h.t))
+}
+
+// Get returns the value of the given key.
+//
+// This operation has an expected time
+// complexity of O(1).
+func (h *ChainedHashTable[K, T]) Get(k K) (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	c := h.chain(k)
+	for i := 0; i < c.n; i++ {
+		if c.s[i].k == k {
+			return c.s[i].v, true
+		}
+	}
+	return *new(T), false
+}
+
+// Put sets the value of the given key and
+// returns the old one, if any. The hash table
+// is resized as needed.
+//
+// This operation has an amortized expected
+// time complexity of O(1).
+func (h *ChainedHashTable[K, T]) Put(k K, v T) (T, bool) {
+	h.init()
+	c := h.chain(k)
+	for i := 0; i < c.n; i++ {
+		if c.s[i].k == k {
+			t := c.s[i].v
+			c.s[i].v = v
+			return t, true
+		}
+	}
+	if h.n+1 > len(h.t) {
+		h.resize()
+		c = h.chain(k)
+	}
+	c.Add(c.Len(), entry[K, T]{k: k, v: v})
+	h.n++
+	return *new(T), false
+}
+
+// Remove removes the given key and returns
+// its value. The hash table is resized as
+// needed.
+//
+// This operation has an amortized expected
+// time complexity of O(1).
+func (h *ChainedHashTable[K, T]) Remove(k K) (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	c := h.chain(k)
+	for i := 0; i < c.n; i++ {
+		if c.s[i].k == k {
+			t := c.s[i].v
+			// the order within a chain is irrelevant
+			e, _ := c.Remove(c.Len() - 1)
+			if i < c.Len() {
+				c.Set(i, e)
+			}
+			h.n--
+			if 3*h.n < len(h.t) {
+				h.resize()
+			}
+			return t, true
+		}
+	}
+	return *new(T), false
+}
+
+// All returns an iterator over the keys and
+// values of the hash table, in no particular
+// order.
+//
+// This operation has a time complexity of O(n).
+func (h *ChainedHashTable[K, T]) All() iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		for i := range h.t {
+			c := &h.t[i]
+			for j := 0; j < c.n; j++ {
+				if !yield(c.s[j].k, c.s[j].v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *ChainedHashTable[K, T]) resize() {
+	t := h.t
+	h.d = dim(h.n + 1)
+	h.t = make([]Array[entry[K, T]], 1<<h.d)
+	for i := range t {
+		for j := 0; j < t[i].n; j++ {
+			c := h.chain(t[i].s[j].k)
+			c.Add(c.Len(), t[i].s[j])
+		}
+	}
+}
+
+// --- LinearHashTable -------
+
+// Slot states of a linear hash table.
+const (
+	slotFree    = iota // never used
+	slotUsed           // holds an element
+	slotDeleted        // tombstone of a removed element
+)
+
+// slot represents a slot
+// in a linear hash table.
+type slot[K, T any] struct {
+	e entry[K, T] // key and value
+	s uint8       // state
+}
+
+// LinearHashTable implements a hash table
+// using linear probing. Removed elements
+// leave tombstones, which are cleared on
+// resize. The zero value is an empty hash
+// table which uses HashCode as hash function.
+type LinearHashTable[K comparable, T any] struct {
+	t    []slot[K, T] // backing slots
+	n    int          // number of elements
+	q    int          // number of elements and tombstones
+	d    int          // dimension, len(t) = 2^d
+	hash Hash[K]      // hash function
+}
+
+// NewLinearHashTable returns an empty hash
+// table which uses the given hash function.
+func NewLinearHashTable[K comparable, T any](h Hash[K]) *LinearHashTable[K, T] {
+	return &LinearHashTable[K, T]{hash: h}
+}
+
+// init lazily initializes the table
+// and the hash function.
+func (h *LinearHashTable[K, T]) init() {
+	if h.hash == nil {
+		h.hash = HashCode[K]
+	}
+	if h.t == nil {
+		h.d = 1
+		h.t = make([]slot[K, T], 1<<h.d)
+	}
+}
+
+// find returns the index of the slot of the given
+// key, or the index of the free slot which ends
+// the probe sequence, and reports whether the
+// key was found.
+func (h *LinearHashTable[K, T]) find(k K) (int, bool) {
+	m := len(h.t) - 1
+	i := int(h.hash(k) >> (64 - h.d))
+	for ; h.t[i].s != slotFree; i = (i + 1) & m {
+		if h.t[i].s == slotUsed && h.t[i].e.k == k {
+			return i, true
+		}
+	}
+	return i, false
+}
+
+// Len returns the number of
+// elements in the hash table.
+func (h *LinearHashTable[K, T]) Len() int { return h.n }
+
+// LoadFactor returns the ratio of the
+// number of elements and tombstones to
+// the number of slots.
+func (h *LinearHashTable[K, T]) LoadFactor() float64 {
+	if h.q == 0 {
+		return 0
+	}
+	return float64(h.q) / float64(len(h.t))
+}
+
+// Get returns the value of the given key.
+//
+// This operation has an expected time
+// complexity of O(1).
+func (h *LinearHashTable[K, T]) Get(k K) (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	if i, ok := h.find(k); ok {
+		return h.t[i].e.v, true
+	}
+	return *new(T), false
+}
+
+// Put sets the value of the given key and
+// returns the old one, if any. The hash table
+// is resized as needed.
+//
+// This operation has an amortized expected
+// time complexity of O(1).
+func (h *LinearHashTable[K, T]) Put(k K, v T) (T, bool) {
+	h.init()
+	if i, ok := h.find(k); ok {
+		t := h.t[i].e.v
+		h.t[i].e.v = v
+		return t, true
+	}
+	if 2*(h.q+1) > len(h.t) {
+		h.resize()
+	}
+	// reuse the first tombstone
+	m := len(h.t) - 1
+	i := int(h.hash(k) >> (64 - h.d))
+	for h.t[i].s == slotUsed {
+		i = (i + 1) & m
+	}
+	if h.t[i].s == slotFree {
+		h.q++
+	}
+	h.t[i] = slot[K, T]{e: entry[K, T]{k: k, v: v}, s: slotUsed}
+	h.n++
+	return *new(T), false
+}
+
+// Remove removes the given key and returns
+// its value. The hash table is resized as
+// needed.
+//
+// This operation has an amortized expected
+// time complexity of O(1).
+func (h *LinearHashTable[K, T]) Remove(k K) (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	i, ok := h.find(k)
+	if !ok {
+		return *new(T), false
+	}
+	t := h.t[i].e.v
+	h.t[i] = slot[K, T]{s: slotDeleted}
+	h.n--
+	if 8*h.n < len(h.t) {
+		h.resize()
+	}
+	return t, true
+}
+
+// All returns an iterator over the keys and
+// values of the hash table, in no particular
+// order.
+//
+// This operation has a time complexity of O(n).
+func (h *LinearHashTable[K, T]) All() iter.Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		for i := range h.t {
+			if h.t[i].s == slotUsed && !yield(h.t[i].e.k, h.t[i].e.v) {
+				return
+			}
+		}
+	}
+}
+
+func (h *LinearHashTable[K, T]) resize() {
+	t := h.t
+	h.d = dim(3 * h.n)
+	h.t = make([]slot[K, T], 1<<h.d)
+	m := len(h.t) - 1
+	for _, s := range t {
+		if s.s != slotUsed {
+			continue
+		}
+		i := int(h.hash(s.e.k) >> (64 - h.d))
+		for h.t[i].s != slotFree {
+			i = (i + 1) & m
+		}
+		h.t[i] = s
+	}
+	h.q = h.n
+}
+
+// dim returns the smallest dimension d,
+// with d >= 1, such that 2^d >= n.
+func dim(n int) int {
+	d := 1
+	for 1<<d < n {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"iter"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+type hashTable[K comparable, T any] interface {
+	Len() int
+	LoadFactor() float64
+	Get(k K) (T, bool)
+	Put(k K, v T) (T, bool)
+	Remove(k K) (T, bool)
+	All() iter.Seq2[K, T]
+}
+
+func intCode(k int) uint64 { return uint64(k) }
+
+func hashTables() map[string]hashTable[int, int] {
+	return map[string]hashTable[int, int]{
+		"Chained":                  new(ChainedHashTable[int, int]),
+		"ChainedMultiplicative":    NewChainedHashTable[int, int](MultiplicativeHash(intCode, 1)),
+		"ChainedTabulation":        NewChainedHashTable[int, int](TabulationHash(intCode, 1)),
+		"Linear":                   new(LinearHashTable[int, int]),
+		"LinearMultiplicative":     NewLinearHashTable[int, int](MultiplicativeHash(intCode, 1)),
+		"LinearTabulation":         NewLinearHashTable[int, int](TabulationHash(intCode, 1)),
+		"LinearTabulationHashCode": NewLinearHashTable[int, int](TabulationHash(HashCode[int], 1)),
+	}
+}
+
+func TestHashTable(t *testing.T) {
+	const n = 5000
+	for name, h := range hashTables() {
+		if _, ok := h.Get(0); ok {
+			t.Errorf("%s: no element expected", name)
+		}
+		if _, ok := h.Remove(0); ok {
+			t.Errorf("%s: no element to remove expected", name)
+		}
+		if f := h.LoadFactor(); f != 0 {
+			t.Errorf("%s: want load factor %v, got %v", name, 0, f)
+		}
+
+		r := rand.New(rand.NewSource(1))
+		e := make(map[int]int)
+		for i := 0; i < n; i++ {
+			k := r.Intn(n / 2)
+			x, found := e[k]
+			if r.Intn(3) > 0 {
+				if v, ok := h.Put(k, i); ok != found || v != x {
+					t.Fatalf("%s: put %d: want (%d, %t), got (%d, %t)", name, k, x, found, v, ok)
+				}
+				e[k] = i
+			} else {
+				if v, ok := h.Remove(k); ok != found || v != x {
+					t.Fatalf("%s: remove %d: want (%d, %t), got (%d, %t)", name, k, x, found, v, ok)
+				}
+				delete(e, k)
+			}
+			if h.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), h.Len())
+			}
+			if f := h.LoadFactor(); f > 1 {
+				t.Fatalf("%s: load factor %v too high", name, f)
+			}
+		}
+
+		for k := 0; k < n/2; k++ {
+			x, found := e[k]
+			if v, ok := h.Get(k); ok != found || v != x {
+				t.Errorf("%s: get %d: want (%d, %t), got (%d, %t)", name, k, x, found, v, ok)
+			}
+		}
+		m := 0
+		for k, v := range h.All() {
+			if x, ok := e[k]; !ok || v != x {
+				t.Errorf("%s: unexpected element (%d, %d)", name, k, v)
+			}
+			m++
+		}
+		if m != len(e) {
+			t.Errorf("%s: want %d, got %d", name, len(e), m)
+		}
+	}
+}
+
+func TestLinearHashTableTombstones(t *testing.T) {
+	const n = 64
+	var h LinearHashTable[int, int]
+	for i := 0; i < n; i++ {
+		h.Put(i, i)
+	}
+	l := len(h.t)
+	for i := 0; i < n/2; i++ {
+		h.Remove(i)
+	}
+	if h.q != n || h.n != n/2 {
+		t.Errorf("want %d elements and %d tombstones, got %d and %d", n/2, n/2, h.n, h.q-h.n)
+	}
+	if len(h.t) != l {
+		t.Errorf("want %d slots, got %d", l, len(h.t))
+	}
+	for i := 0; i < n/2; i++ {
+		if _, ok := h.Get(i); ok {
+			t.Errorf("no element expected: %d", i)
+		}
+		if v, ok := h.Get(n/2 + i); !ok || v != n/2+i {
+			t.Errorf("want %d, got %d", n/2+i, v)
+		}
+	}
+	for i := n / 2; len(h.t) == l; i++ {
+		h.Remove(i)
+	}
+	if h.q != h.n {
+		t.Errorf("want no tombstones after resize, got %d", h.q-h.n)
+	}
+}
+
+func BenchmarkHashTablePut(b *testing.B) {
+	for name, h := range hashTables() {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				h.Put(i, i)
+			}
+		})
+	}
+	b.Run("map", func(b *testing.B) {
+		m := make(map[int]int)
+		for i := 0; i < b.N; i++ {
+			m[i] = i
+		}
+	})
+}
+
+func BenchmarkHashTableGet(b *testing.B) {
+	const n = 1 << 16
+	for name, h := range hashTables() {
+		for i := 0; i < n; i++ {
+			h.Put(i, i)
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				h.Get(i % n)
+			}
+		})
+	}
+	b.Run("map", func(b *testing.B) {
+		m := make(map[int]int)
+		for i := 0; i < n; i++ {
+			m[i] = i
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = m[i%n]
+		}
+	})
+}
+
+func BenchmarkHashTableString(b *testing.B) {
+	const n = 1 << 16
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	b.Run("Chained", func(b *testing.B) {
+		var h ChainedHashTable[string, int]
+		for i := 0; i < b.N; i++ {
+			h.Put(keys[i%n], i)
+		}
+	})
+	b.Run("Linear", func(b *testing.B) {
+		var h LinearHashTable[string, int]
+		for i := 0; i < b.N; i++ {
+			h.Put(keys[i%n], i)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := make(map[string]int)
+		for i := 0; i < b.N; i++ {
+			m[keys[i%n]] = i
+		}
+	})
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"iter"
+	"math/rand"
+)
+
+// --- BinaryHeap -------
+
+// BinaryHeap implements a priority queue
+// on top of a dynamic array, which stores
+// a complete binary tree in level order.
+// The elements are ordered by a comparison
+// function, which returns a negative number
+// if a < b, zero if a == b and a positive
+// number if a > b.
+type BinaryHeap[T any] struct {
+	a   Array[T]         // backing dynamic array
+	cmp func(a, b T) int // comparison function
+}
+
+// NewBinaryHeap returns an empty heap
+// which orders its elements by the
+// given comparison function.
+func NewBinaryHeap[T any](cmp func(a, b T) int) *BinaryHeap[T] {
+	return &BinaryHeap[T]{cmp: cmp}
+}
+
+// Len returns the number of
+// elements in the heap.
+func (h *BinaryHeap[T]) Len() int { return h.a.Len() }
+
+// Peek returns the smallest
+// element of the heap.
+//
+// This operation has a time complexity of O(1).
+func (h *BinaryHeap[T]) Peek() (T, bool) { return h.a.Get(0) }
+
+// Add adds an element to the heap.
+// The heap is resized as needed.
+//
+// This operation has an amortized time
+// complexity of O(log n).
+func (h *BinaryHeap[T]) Add(v T) {
+	h.a.Add(h.a.Len(), v)
+	// bubble up
+	s := h.a.s
+	for i := h.a.n - 1; i > 0; {
+		p := (i - 1) / 2
+		if h.cmp(s[i], s[p]) >= 0 {
+			break
+		}
+		s[i], s[p] = s[p], s[i]
+		i = p
+	}
+}
+
+// Remove removes and returns the smallest
+// element of the heap. The heap is resized
+// as needed.
+//
+// This operation has an amortized time
+// complexity of O(log n).
+func (h *BinaryHeap[T]) Remove() (T, bool) {
+	v, ok := h.a.Get(0)
+	if !ok {
+		return v, false
+	}
+	t, _ := h.a.Remove(h.a.Len() - 1)
+	if h.a.Len() > 0 {
+		h.a.s[0] = t
+		h.trickleDown(0)
+	}
+	return v, true
+}
+
+// trickleDown moves the element at
+// index i down until it is smaller
+// than or equal to its children.
+func (h *BinaryHeap[T]) trickleDown(i int) {
+	s, n := h.a.s, h.a.n
+	for {
+		j := i
+		if l := 2*i + 1; l < n && h.cmp(s[l], s[j]) < 0 {
+			j = l
+		}
+		if r := 2*i + 2; r < n && h.cmp(s[r], s[j]) < 0 {
+			j = r
+		}
+		if j == i {
+			return
+		}
+		s[i], s[j] = s[j], s[i]
+		i = j
+	}
+}
+
+// All returns an iterator over the
+// elements of the heap, in no particular
+// order.
+//
+// This operation has a time complexity of O(n).
+func (h *BinaryHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range h.a.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// --- MeldableHeap -------
+
+// MeldableHeap implements a priority queue
+// on top of a randomized binary tree, which
+// allows for merging two heaps efficiently.
+type MeldableHeap[T any] struct {
+	r   *tnode[T]        // root
+	n   int              // number of elements
+	cmp func(a, b T) int // comparison function
+	rnd *rand.Rand       // source for merge directions
+}
+
+// NewMeldableHeap returns an empty heap
+// which orders its elements by the
+// given comparison function.
+func NewMeldableHeap[T any](cmp func(a, b T) int) *MeldableHeap[T] {
+	return &MeldableHeap[T]{
+		cmp: cmp,
+		rnd: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed seeds the source for the directions
+// of merges, which makes the shape of the
+// heap deterministic.
+func (h *MeldableHeap[T]) Seed(seed int64) { h.rnd = rand.New(rand.NewSource(seed)) }
+
+// Len returns the number of
+// elements in the heap.
+func (h *MeldableHeap[T]) Len() int { return h.n }
+
+// Peek returns the smallest
+// element of the heap.
+//
+// This operation has a time complexity of O(1).
+func (h *MeldableHeap[T]) Peek() (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	return h.r.v, true
+}
+
+// Add adds an element to the heap.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (h *MeldableHeap[T]) Add(v T) {
+	h.r = h.merge(&tnode[T]{v: v}, h.r)
+	h.n++
+}
+
+// Remove removes and returns the
+// smallest element of the heap.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (h *MeldableHeap[T]) Remove() (T, bool) {
+	if h.n == 0 {
+		return *new(T), false
+	}
+	v := h.r.v
+	h.r = h.merge(h.r.l, h.r.r)
+	h.n--
+	return v, true
+}
+
+// Meld moves all elements of o into the
+// heap, which leaves o empty. Both heaps
+// must use the same comparison function.
+//
+// This operation has an expected time
+// complexity of O(log n + log m), where
+// m is the number of elements in o.
+func (h *MeldableHeap[T]) Meld(o *MeldableHeap[T]) {
+	if h == o {
+		return
+	}
+	h.r = h.merge(h.r, o.r)
+	h.n += o.n
+	o.r, o.n = nil, 0
+}
+
+// merge merges the heaps rooted at
+// u and w and returns the new root.
+func (h *MeldableHeap[T]) merge(u, w *tnode[T]) *tnode[T] {
+	if u == nil {
+		return w
+	}
+	if w == nil {
+		return u
+	}
+	if h.cmp(w.v, u.v) < 0 {
+		u, w = w, u
+	}
+	// merge w into a random subtree of u
+	if h.rnd.Intn(2) == 0 {
+		u.l = h.merge(u.l, w)
+	} else {
+		u.r = h.merge(u.r, w)
+	}
+	return u
+}
+
+// All returns an iterator over the
+// elements of the heap, in no particular
+// order.
+//
+// This operation has a time and space
+// complexity of O(n).
+func (h *MeldableHeap[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var s ArrayStack[*tnode[T]]
+		if h.r != nil {
+			s.Push(h.r)
+		}
+		for s.Len() > 0 {
+			u, _ := s.Pop()
+			if !yield(u.v) {
+				return
+			}
+			if u.l != nil {
+				s.Push(u.l)
+			}
+			if u.r != nil {
+				s.Push(u.r)
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	const n = 2000
+	m := NewMeldableHeap(cmp.Compare[int])
+	m.Seed(1)
+	queues := map[string]PriorityQueue[int]{
+		"BinaryHeap":   NewBinaryHeap(cmp.Compare[int]),
+		"MeldableHeap": m,
+	}
+	for name, q := range queues {
+		if _, ok := q.Peek(); ok {
+			t.Errorf("%s: no element in heap expected", name)
+		}
+		if _, ok := q.Remove(); ok {
+			t.Errorf("%s: no element in heap expected", name)
+		}
+
+		r := rand.New(rand.NewSource(1))
+		var e []int
+		for k := 0; k < n; k++ {
+			if r.Intn(3) > 0 || len(e) == 0 {
+				v := r.Intn(n)
+				q.Add(v)
+				i, _ := slices.BinarySearch(e, v)
+				e = slices.Insert(e, i, v)
+			} else {
+				v, ok := q.Remove()
+				if !ok || v != e[0] {
+					t.Fatalf("%s: want %d, got %d", name, e[0], v)
+				}
+				e = e[1:]
+			}
+			if q.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), q.Len())
+			}
+			if len(e) > 0 {
+				if v, ok := q.Peek(); !ok || v != e[0] {
+					t.Fatalf("%s: want %d, got %d", name, e[0], v)
+				}
+			}
+		}
+
+		var a []int
+		switch q := q.(type) {
+		case *BinaryHeap[int]:
+			checkBinaryHeap(t, q)
+			a = slices.Sorted(q.All())
+		case *MeldableHeap[int]:
+			checkMeldableHeap(t, q)
+			a = slices.Sorted(q.All())
+		}
+		if !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+
+		for _, x := range e {
+			if v, ok := q.Remove(); !ok || v != x {
+				t.Fatalf("%s: want %d, got %d", name, x, v)
+			}
+		}
+		if q.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, q.Len())
+		}
+	}
+}
+
+func TestBinaryHeapResize(t *testing.T) {
+	const mincap, maxcap, n = 1, 128, 65
+	h := NewBinaryHeap(cmp.Compare[int])
+	for i := n - 1; i >= 0; i-- {
+		h.Add(i)
+	}
+	if len(h.a.s) != maxcap {
+		t.Errorf("want %d, got %d", maxcap, len(h.a.s))
+	}
+	for i := 0; i < n; i++ {
+		if v, _ := h.Remove(); v != i {
+			t.Errorf("want %d, got %d", i, v)
+		}
+	}
+	if len(h.a.s) != mincap {
+		t.Errorf("want %d, got %d", mincap, len(h.a.s))
+	}
+}
+
+func TestMeldableHeapMeld(t *testing.T) {
+	const n = 500
+	a := NewMeldableHeap(cmp.Compare[int])
+	b := NewMeldableHeap(cmp.Compare[int])
+	a.Seed(1)
+	b.Seed(2)
+	for i := 0; i < n; i++ {
+		a.Add(2 * i)
+		b.Add(2*i + 1)
+	}
+	a.Meld(b)
+	a.Meld(a)
+	if a.Len() != 2*n || b.Len() != 0 {
+		t.Errorf("want %d and %d, got %d and %d", 2*n, 0, a.Len(), b.Len())
+	}
+	if _, ok := b.Peek(); ok {
+		t.Errorf("no element in heap expected")
+	}
+	checkMeldableHeap(t, a)
+	for i := 0; i < 2*n; i++ {
+		if v, ok := a.Remove(); !ok || v != i {
+			t.Fatalf("want %d, got %d", i, v)
+		}
+	}
+}
+
+// checkBinaryHeap checks that no element
+// is smaller than its parent.
+func checkBinaryHeap(t *testing.T, h *BinaryHeap[int]) {
+	t.Helper()
+	for i := 1; i < h.a.n; i++ {
+		if h.a.s[i] < h.a.s[(i-1)/2] {
+			t.Fatalf("heap property violated at index %d", i)
+		}
+	}
+}
+
+// checkMeldableHeap checks that no element
+// is smaller than its parent and that the
+// heap holds n elements.
+func checkMeldableHeap(t *testing.T, h *MeldableHeap[int]) {
+	t.Helper()
+	var walk func(u *tnode[int]) int
+	walk = func(u *tnode[int]) int {
+		if u == nil {
+			return 0
+		}
+		if (u.l != nil && u.l.v < u.v) || (u.r != nil && u.r.v < u.v) {
+			t.Fatalf("heap property violated at %d", u.v)
+		}
+		return 1 + walk(u.l) + walk(u.r)
+	}
+	if n := walk(h.r); n != h.n {
+		t.Fatalf("want %d nodes, got %d", h.n, n)
+	}
+}
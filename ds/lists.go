@@ -4,28 +4,30 @@
 
 package ds
 
+import "iter"
+
 // --- SList -------
 
 // snode represents a node
 // in a singly-linked list.
-type snode struct {
-	n *snode // next pointer
-	v V      // value
+type snode[T any] struct {
+	n *snode[T] // next pointer
+	v T         // value
 }
 
 // SList is a singly-linked list with
 // a head and tail pointer.
 // It can be used as stack or queue.
-type SList struct {
-	h, t *snode // head and tail pointer
-	n    int    // number of elements
+type SList[T any] struct {
+	h, t *snode[T] // head and tail pointer
+	n    int       // number of elements
 }
 
 // Push pushes a value onto the head of the list.
 //
 // This operation has a time complexity of O(1).
-func (l *SList) Push(v V) {
-	l.h = &snode{v: v, n: l.h}
+func (l *SList[T]) Push(v T) {
+	l.h = &snode[T]{v: v, n: l.h}
 	if l.n == 0 {
 		l.t = l.h
 	}
@@ -36,9 +38,9 @@ func (l *SList) Push(v V) {
 // head of the list.
 //
 // This operation has a time complexity of O(1).
-func (l *SList) Pop() (V, bool) {
+func (l *SList[T]) Pop() (T, bool) {
 	if l.n == 0 {
-		return nil, false
+		return *new(T), false
 	}
 	v := l.h.v
 	l.h = l.h.n
@@ -52,8 +54,8 @@ func (l *SList) Pop() (V, bool) {
 // Enqueue adds an element to the tail of the list.
 //
 // This operation has a time complexity of O(1).
-func (l *SList) Enqueue(v V) {
-	n := &snode{v: v}
+func (l *SList[T]) Enqueue(v T) {
+	n := &snode[T]{v: v}
 	if l.n == 0 {
 		l.h = n
 	} else {
@@ -67,30 +69,169 @@ func (l *SList) Enqueue(v V) {
 // the list and is equivalent to Pop.
 //
 // This operation has a time complexity of O(1).
-func (l *SList) Dequeue() (V, bool) { return l.Pop() }
+func (l *SList[T]) Dequeue() (T, bool) { return l.Pop() }
 
 // Len returns the number of elements in the list.
-func (l *SList) Len() int { return l.n }
+func (l *SList[T]) Len() int { return l.n }
+
+// All returns an iterator over the indices and
+// elements of the list, from the head to the tail.
+//
+// This operation has a time complexity of O(n).
+func (l *SList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for n := l.h; n != nil; n = n.n {
+			if !yield(i, n.v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Backward returns an iterator over the indices and
+// elements of the list, from the tail to the head.
+//
+// This operation has a time and space
+// complexity of O(n).
+func (l *SList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		s := make([]*snode[T], 0, l.n)
+		for n := l.h; n != nil; n = n.n {
+			s = append(s, n)
+		}
+		for i := len(s) - 1; i >= 0; i-- {
+			if !yield(i, s[i].v) {
+				return
+			}
+		}
+	}
+}
+
+// Cursor returns a cursor positioned
+// before the head of the list.
+func (l *SList[T]) Cursor() *SListCursor[T] { return &SListCursor[T]{l: l} }
+
+// SListCursor walks a singly-linked list from
+// the head to the tail and allows for removal
+// of the current element. The list must not be
+// modified other than through the cursor while
+// the cursor is in use.
+type SListCursor[T any] struct {
+	l    *SList[T] // list
+	p, c *snode[T] // previous and current node
+}
+
+// Next advances the cursor to the next element
+// and reports whether there is such an element.
+//
+// This operation has a time complexity of O(1).
+func (c *SListCursor[T]) Next() bool {
+	if c.c != nil {
+		c.p = c.c
+	}
+	if c.p == nil {
+		c.c = c.l.h
+	} else {
+		c.c = c.p.n
+	}
+	return c.c != nil
+}
+
+// Value returns the current element.
+func (c *SListCursor[T]) Value() (T, bool) {
+	if c.c == nil {
+		return *new(T), false
+	}
+	return c.c.v, true
+}
+
+// Set sets the current element
+// and returns the old one.
+func (c *SListCursor[T]) Set(v T) (T, bool) {
+	if c.c == nil {
+		return *new(T), false
+	}
+	t := c.c.v
+	c.c.v = v
+	return t, true
+}
+
+// Remove removes and returns the current element.
+// Afterwards, the cursor is positioned between the
+// neighbours of the removed element and Next
+// advances to its successor.
+//
+// This operation has a time complexity of O(1).
+func (c *SListCursor[T]) Remove() (T, bool) {
+	if c.c == nil {
+		return *new(T), false
+	}
+	n := c.c
+	if c.p == nil {
+		c.l.h = n.n
+	} else {
+		c.p.n = n.n
+	}
+	if c.l.t == n {
+		c.l.t = c.p
+	}
+	c.l.n--
+	c.c = nil
+	return n.v, true
+}
 
 // --- DList -------
 
 // dnode represents a node
 // in a doubly-linked list.
-type dnode struct {
-	n *dnode // next pointer
-	p *dnode // previous pointer
-	v V      // value
+type dnode[T any] struct {
+	n *dnode[T]  // next pointer
+	p *dnode[T]  // previous pointer
+	o *downer[T] // owner, nil if removed
+	v T          // value
+}
+
+// downer identifies the list which owns a node. The owners
+// form a disjoint-set forest: Splice forwards the owner of
+// one list to that of another, such that the ownership of
+// all nodes is transferred at once. Only the root of a tree
+// refers to a list, and only as long as the list owns the
+// nodes of the tree.
+type downer[T any] struct {
+	l *DList[T]  // list, nil if not a root or dropped
+	f *downer[T] // forwarding pointer, nil for a root
+	r int        // rank
+}
+
+// root returns the root of the tree of
+// the owner and halves the path to it.
+func (o *downer[T]) root() *downer[T] {
+	for o.f != nil {
+		if o.f.f != nil {
+			o.f = o.f.f
+		}
+		o = o.f
+	}
+	return o
 }
 
 // DList represents a doubly-linked list.
-type DList struct {
-	r *dnode // sentinel node, the root
-	n int    // number of elements
+type DList[T any] struct {
+	r *dnode[T]  // sentinel node, the root
+	o *downer[T] // owner of the nodes, a root
+	n int        // number of elements
+}
+
+// owns reports whether the node is in the list.
+func (l *DList[T]) owns(n *dnode[T]) bool {
+	return n.o != nil && n.o.root().l == l
 }
 
 // get returns the node at the
 // given index or nil if not found.
-func (l *DList) get(i int) *dnode {
+func (l *DList[T]) get(i int) *dnode[T] {
 	if i < l.n/2 {
 		n := l.r.n
 		for j := 0; j < i; j++ {
@@ -105,8 +246,137 @@ func (l *DList) get(i int) *dnode {
 	return n
 }
 
+// init lazily initializes the sentinel node.
+func (l *DList[T]) init() {
+	if l.r == nil {
+		l.r = new(dnode[T])
+		l.r.n = l.r
+		l.r.p = l.r
+		l.o = &downer[T]{l: l}
+	}
+}
+
 // Len returns the number of elements in the list.
-func (l *DList) Len() int { return l.n }
+func (l *DList[T]) Len() int { return l.n }
+
+// All returns an iterator over the indices and
+// elements of the list, in order.
+//
+// This operation has a time complexity of O(n).
+func (l *DList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		i := 0
+		for n := l.r.n; n != l.r; n = n.n {
+			if !yield(i, n.v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Backward returns an iterator over the indices
+// and elements of the list, in reverse order.
+//
+// This operation has a time complexity of O(n).
+func (l *DList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		i := l.n - 1
+		for n := l.r.p; n != l.r; n = n.p {
+			if !yield(i, n.v) {
+				return
+			}
+			i--
+		}
+	}
+}
+
+// Cursor returns a cursor positioned before
+// the first and after the last element.
+func (l *DList[T]) Cursor() *DListCursor[T] {
+	l.init()
+	return &DListCursor[T]{l: l, c: l.r}
+}
+
+// DListCursor walks a doubly-linked list in
+// both directions and allows for removal of
+// the current element. The list must not be
+// modified other than through the cursor while
+// the cursor is in use.
+type DListCursor[T any] struct {
+	l    *DList[T] // list
+	c    *dnode[T] // current node
+	p, n *dnode[T] // neighbours of the removed node
+	rm   bool      // current node removed
+}
+
+// Next advances the cursor to the next element
+// and reports whether there is such an element.
+// After the last element, the cursor wraps around.
+//
+// This operation has a time complexity of O(1).
+func (c *DListCursor[T]) Next() bool {
+	if c.rm {
+		c.c, c.p, c.n, c.rm = c.n, nil, nil, false
+	} else {
+		c.c = c.c.n
+	}
+	return c.c != c.l.r
+}
+
+// Prev moves the cursor to the previous element
+// and reports whether there is such an element.
+// Before the first element, the cursor wraps around.
+//
+// This operation has a time complexity of O(1).
+func (c *DListCursor[T]) Prev() bool {
+	if c.rm {
+		c.c, c.p, c.n, c.rm = c.p, nil, nil, false
+	} else {
+		c.c = c.c.p
+	}
+	return c.c != c.l.r
+}
+
+// Value returns the current element.
+func (c *DListCursor[T]) Value() (T, bool) {
+	if c.c == c.l.r || c.rm {
+		return *new(T), false
+	}
+	return c.c.v, true
+}
+
+// Set sets the current element
+// and returns the old one.
+func (c *DListCursor[T]) Set(v T) (T, bool) {
+	if c.c == c.l.r || c.rm {
+		return *new(T), false
+	}
+	t := c.c.v
+	c.c.v = v
+	return t, true
+}
+
+// Remove removes and returns the current element.
+// Afterwards, the cursor is positioned between the
+// neighbours of the removed element and Next and
+// Prev move to its successor and predecessor.
+//
+// This operation has a time complexity of O(1).
+func (c *DListCursor[T]) Remove() (T, bool) {
+	if c.c == c.l.r || c.rm {
+		return *new(T), false
+	}
+	n := c.c
+	unlink(n)
+	c.p, c.n = n.p, n.n
+	n.n, n.p, n.o = nil, nil, nil
+	c.l.n--
+	c.rm = true
+	return n.v, true
+}
 
 // Add adds an element to the list at the
 // given index and reports whether it was
@@ -114,25 +384,12 @@ func (l *DList) Len() int { return l.n }
 //
 // This operation has a time complexity
 // of O(min{i, n-i}).
-func (l *DList) Add(i int, v V) bool {
+func (l *DList[T]) Add(i int, v T) bool {
 	if i < 0 || i > l.n {
 		return false
 	}
-	// lazy initialization
-	if l.r == nil {
-		l.r = new(dnode)
-		l.r.n = l.r
-		l.r.p = l.r
-	}
-	p := l.get(i)
-	n := &dnode{
-		v: v,
-		p: p.p,
-		n: p,
-	}
-	n.n.p = n
-	n.p.n = n
-	l.n++
+	l.init()
+	l.insert(v, l.get(i))
 	return true
 }
 
@@ -142,15 +399,16 @@ func (l *DList) Add(i int, v V) bool {
 //
 // This operation has a time complexity
 // of O(min{i, n-i}).
-func (l *DList) Remove(i int) (V, bool) {
+func (l *DList[T]) Remove(i int) (T, bool) {
 	if i < 0 || i > l.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	n := l.get(i)
 	n.p.n = n.n
 	n.n.p = n.p
 	n.n = nil
 	n.p = nil
+	n.o = nil
 	l.n--
 	return n.v, true
 }
@@ -159,9 +417,9 @@ func (l *DList) Remove(i int) (V, bool) {
 //
 // This operation has a time complexity
 // of O(min{i, n-i}).
-func (l *DList) Get(i int) (V, bool) {
+func (l *DList[T]) Get(i int) (T, bool) {
 	if i < 0 || i > l.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	return l.get(i).v, true
 }
@@ -171,12 +429,810 @@ func (l *DList) Get(i int) (V, bool) {
 //
 // This operation has a time complexity
 // of O(min{i, n-i}).
-func (l *DList) Set(i int, v V) (V, bool) {
+func (l *DList[T]) Set(i int, v T) (T, bool) {
 	if i < 0 || i > l.n-1 {
-		return nil, false
+		return *new(T), false
 	}
 	n := l.get(i)
 	t := n.v
 	n.v = v
 	return t, true
 }
+
+// AddAll adds the given elements to the list
+// at the given index and reports whether it
+// was successful or not.
+//
+// This operation has a time complexity of
+// O(min{i, n-i}+k), where k is the number
+// of added elements.
+func (l *DList[T]) AddAll(i int, vs ...T) bool {
+	if i < 0 || i > l.n {
+		return false
+	}
+	l.init()
+	w := l.get(i)
+	for _, v := range vs {
+		n := &dnode[T]{v: v, p: w.p, n: w, o: l.o}
+		n.p.n = n
+		w.p = n
+	}
+	l.n += len(vs)
+	return true
+}
+
+// RemoveRange removes the elements of the
+// list at the indices [f, t) and reports
+// whether the operation was successful
+// or not.
+//
+// This operation has a time complexity
+// of O(min{f, n-f}+t-f).
+func (l *DList[T]) RemoveRange(f, t int) bool {
+	if f < 0 || t > l.n || f > t {
+		return false
+	}
+	if f == t {
+		return true
+	}
+	n := l.get(f)
+	p := n.p
+	for j := f; j < t; j++ {
+		w := n.n
+		n.n, n.p, n.o = nil, nil, nil
+		n = w
+	}
+	p.n, n.p = n, p
+	l.n -= t - f
+	return true
+}
+
+// Slice returns a copy of the elements
+// of the list at the indices [f, t).
+//
+// This operation has a time complexity
+// of O(min{f, n-f}+t-f).
+func (l *DList[T]) Slice(f, t int) ([]T, bool) {
+	if f < 0 || t > l.n || f > t {
+		return nil, false
+	}
+	s := make([]T, t-f)
+	if len(s) > 0 {
+		n := l.get(f)
+		for j := range s {
+			s[j], n = n.v, n.n
+		}
+	}
+	return s, true
+}
+
+// Reverse reverses the order of
+// the elements of the list.
+//
+// This operation has a time complexity of O(n).
+func (l *DList[T]) Reverse() {
+	l.init()
+	for n := l.r; ; {
+		n.n, n.p = n.p, n.n
+		if n = n.p; n == l.r {
+			return
+		}
+	}
+}
+
+// Clear removes all elements
+// of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Clear() {
+	l.init()
+	if l.n > 0 {
+		// drop the owner of the removed nodes
+		l.o.l = nil
+		l.o = &downer[T]{l: l}
+	}
+	l.r.n, l.r.p = l.r, l.r
+	l.n = 0
+}
+
+// AddFirst adds an element to the
+// front of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) AddFirst(v T) { l.Add(0, v) }
+
+// AddLast adds an element to the
+// back of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) AddLast(v T) { l.Add(l.n, v) }
+
+// RemoveFirst removes and returns the
+// element at the front of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) RemoveFirst() (T, bool) { return l.Remove(0) }
+
+// RemoveLast removes and returns the
+// element at the back of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) RemoveLast() (T, bool) { return l.Remove(l.n - 1) }
+
+// Element is a handle to an element of a doubly-linked
+// list. It stays valid until the element is removed,
+// also if it is moved to another list by Splice or
+// SplitAt. Like the value of a removed element, the
+// handle remains readable. Methods of a list ignore
+// handles of removed elements and of other lists;
+// this check takes amortized O(α(n)) time, where α
+// is the inverse Ackermann function, which is
+// effectively constant.
+type Element[T any] dnode[T]
+
+// Value returns the element.
+func (e *Element[T]) Value() T { return e.v }
+
+// Set sets the element and returns the old one.
+func (e *Element[T]) Set(v T) T {
+	t := e.v
+	e.v = v
+	return t
+}
+
+// node returns the node of the element.
+func (e *Element[T]) node() *dnode[T] { return (*dnode[T])(e) }
+
+// elem returns the element of the
+// node or nil if it is the sentinel.
+func (l *DList[T]) elem(n *dnode[T]) *Element[T] {
+	if n == l.r {
+		return nil
+	}
+	return (*Element[T])(n)
+}
+
+// Front returns the first element
+// or nil if the list is empty.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Front() *Element[T] {
+	if l.n == 0 {
+		return nil
+	}
+	return l.elem(l.r.n)
+}
+
+// Back returns the last element
+// or nil if the list is empty.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Back() *Element[T] {
+	if l.n == 0 {
+		return nil
+	}
+	return l.elem(l.r.p)
+}
+
+// Next returns the element after e or nil if
+// e is the last element or not in the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Next(e *Element[T]) *Element[T] {
+	if !l.owns(e.node()) {
+		return nil
+	}
+	return l.elem(e.n)
+}
+
+// Prev returns the element before e or nil if
+// e is the first element or not in the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Prev(e *Element[T]) *Element[T] {
+	if !l.owns(e.node()) {
+		return nil
+	}
+	return l.elem(e.p)
+}
+
+// insert inserts a new node with
+// the given value before the node w.
+func (l *DList[T]) insert(v T, w *dnode[T]) *Element[T] {
+	n := &dnode[T]{v: v, p: w.p, n: w, o: l.o}
+	n.p.n = n
+	w.p = n
+	l.n++
+	return (*Element[T])(n)
+}
+
+// link links the node n before the node w.
+func link[T any](n, w *dnode[T]) {
+	n.p, n.n = w.p, w
+	n.p.n = n
+	w.p = n
+}
+
+// unlink unlinks the node n from its neighbours.
+func unlink[T any](n *dnode[T]) {
+	n.p.n = n.n
+	n.n.p = n.p
+}
+
+// PushFront adds an element to the front
+// of the list and returns its handle.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) PushFront(v T) *Element[T] {
+	l.init()
+	return l.insert(v, l.r.n)
+}
+
+// PushBack adds an element to the back
+// of the list and returns its handle.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) PushBack(v T) *Element[T] {
+	l.init()
+	return l.insert(v, l.r)
+}
+
+// InsertBefore adds an element before the element
+// mark and returns its handle, or nil if mark is
+// not in the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) InsertBefore(v T, mark *Element[T]) *Element[T] {
+	if !l.owns(mark.node()) {
+		return nil
+	}
+	return l.insert(v, mark.node())
+}
+
+// InsertAfter adds an element after the element
+// mark and returns its handle, or nil if mark is
+// not in the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) InsertAfter(v T, mark *Element[T]) *Element[T] {
+	if !l.owns(mark.node()) {
+		return nil
+	}
+	return l.insert(v, mark.n)
+}
+
+// RemoveElement removes the element e from the list
+// and returns its value. It reports false if e is not
+// in the list, e.g. because it has been removed.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) RemoveElement(e *Element[T]) (T, bool) {
+	if !l.owns(e.node()) {
+		return *new(T), false
+	}
+	unlink(e.node())
+	e.n, e.p, e.o = nil, nil, nil
+	l.n--
+	return e.v, true
+}
+
+// MoveToFront moves the element e to the front
+// of the list. It does nothing if e is not in
+// the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) MoveToFront(e *Element[T]) {
+	if n := e.node(); l.owns(n) && l.r.n != n {
+		unlink(n)
+		link(n, l.r.n)
+	}
+}
+
+// MoveToBack moves the element e to the back
+// of the list. It does nothing if e is not in
+// the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) MoveToBack(e *Element[T]) {
+	if n := e.node(); l.owns(n) && l.r.p != n {
+		unlink(n)
+		link(n, l.r)
+	}
+}
+
+// Splice moves all elements of the other list
+// to the back of the list. Afterwards, the other
+// list is empty and the handles of its elements
+// refer to elements of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *DList[T]) Splice(other *DList[T]) {
+	if other == l || other.n == 0 {
+		return
+	}
+	l.init()
+	// union the owners by rank
+	x, y := l.o, other.o
+	if x.r < y.r {
+		x, y = y, x
+	}
+	if x.r == y.r {
+		x.r++
+	}
+	y.f, y.l = x, nil
+	x.l, l.o = l, x
+	other.o = &downer[T]{l: other}
+
+	f, b := other.r.n, other.r.p
+	f.p, b.n = l.r.p, l.r
+	l.r.p.n, l.r.p = f, b
+	l.n += other.n
+	other.r.n, other.r.p = other.r, other.r
+	other.n = 0
+}
+
+// SplitAt removes the elements at the indices
+// [i, n) and returns them as a new list. The
+// handles of these elements refer to elements
+// of the new list.
+//
+// This operation has a time complexity
+// of O(min{i, n-i}).
+func (l *DList[T]) SplitAt(i int) (*DList[T], bool) {
+	if i < 0 || i > l.n {
+		return nil, false
+	}
+	o := new(DList[T])
+	o.init()
+	if i == l.n {
+		return o, true
+	}
+	f, b := l.get(i), l.r.p
+	// give the shorter part a new owner
+	if i < l.n-i {
+		w := &downer[T]{l: l}
+		for n := l.r.n; n != f; n = n.n {
+			n.o = w
+		}
+		l.o.l, o.o, l.o = o, l.o, w
+	} else {
+		for n := f; n != l.r; n = n.n {
+			n.o = o.o
+		}
+	}
+	f.p.n, l.r.p = l.r, f.p
+	o.r.n, o.r.p = f, b
+	f.p, b.n = o.r, o.r
+	o.n, l.n = l.n-i, i
+	return o, true
+}
+
+// --- SEList -------
+
+// senode represents a node in a
+// space-efficient linked list.
+type senode[T any] struct {
+	n *senode[T] // next pointer
+	p *senode[T] // previous pointer
+	d bdeque[T]  // block of elements
+}
+
+// SEList is a space-efficient doubly-linked
+// list which stores a block of b-1 to b+1
+// elements in each node. The zero value is
+// an empty list with a block size of 16.
+type SEList[T any] struct {
+	r *senode[T] // sentinel node, the root
+	n int        // number of elements
+	b int        // block size
+}
+
+// NewSEList returns an empty list with the
+// given block size, which is at least 2.
+func NewSEList[T any](b int) *SEList[T] { return &SEList[T]{b: max(b, 2)} }
+
+// init lazily initializes the sentinel node.
+func (l *SEList[T]) init() {
+	if l.r == nil {
+		if l.b == 0 {
+			l.b = 16
+		}
+		l.r = new(senode[T])
+		l.r.n = l.r
+		l.r.p = l.r
+	}
+}
+
+// locate returns the node and the offset
+// within its block of the given index.
+func (l *SEList[T]) locate(i int) (*senode[T], int) {
+	if i < l.n/2 {
+		u := l.r.n
+		for i >= u.d.n {
+			i -= u.d.n
+			u = u.n
+		}
+		return u, i
+	}
+	u, j := l.r, l.n
+	for i < j {
+		u = u.p
+		j -= u.d.n
+	}
+	return u, i - j
+}
+
+// Len returns the number of elements in the list.
+func (l *SEList[T]) Len() int { return l.n }
+
+// Get returns the element at the given index.
+//
+// This operation has a time complexity
+// of O(1 + min{i, n-i}/b).
+func (l *SEList[T]) Get(i int) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	u, j := l.locate(i)
+	return u.d.get(j), true
+}
+
+// Set sets the element at the given index
+// and returns the old one.
+//
+// This operation has a time complexity
+// of O(1 + min{i, n-i}/b).
+func (l *SEList[T]) Set(i int, v T) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	u, j := l.locate(i)
+	return u.d.set(j, v), true
+}
+
+// Add adds an element to the list at the
+// given index and reports whether it was
+// successful or not.
+//
+// This operation has an amortized time
+// complexity of O(b + min{i, n-i}/b).
+func (l *SEList[T]) Add(i int, v T) bool {
+	if i < 0 || i > l.n {
+		return false
+	}
+	l.init()
+	if i == l.n {
+		u := l.r.p
+		if u == l.r || u.d.n == l.b+1 {
+			u = l.addBefore(l.r)
+		}
+		u.d.add(u.d.n, v)
+		l.n++
+		return true
+	}
+	x, j := l.locate(i)
+	// find a node with space within the next b nodes
+	u, r := x, 0
+	for r < l.b && u != l.r && u.d.n == l.b+1 {
+		u = u.n
+		r++
+	}
+	if r == l.b {
+		l.spread(x)
+		u = x
+	}
+	if u == l.r {
+		u = l.addBefore(u)
+	}
+	// shift elements towards the node with space
+	for u != x {
+		u.d.add(0, u.p.d.remove(u.p.d.n-1))
+		u = u.p
+	}
+	u.d.add(j, v)
+	l.n++
+	return true
+}
+
+// Remove removes the element of the list at the
+// given index and reports whether the operation was
+// successful or not.
+//
+// This operation has an amortized time
+// complexity of O(b + min{i, n-i}/b).
+func (l *SEList[T]) Remove(i int) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	u, j := l.locate(i)
+	v, _, _ := l.remove(u, j)
+	return v, true
+}
+
+// remove removes the element at the given offset
+// of the given node and returns it together with
+// the location of its successor.
+func (l *SEList[T]) remove(x *senode[T], j int) (T, *senode[T], int) {
+	v := x.d.get(j)
+	// find a node with surplus within the next b nodes
+	u, r := x, 0
+	for r < l.b && u != l.r && u.d.n == l.b-1 {
+		u = u.n
+		r++
+	}
+	if r == l.b {
+		l.gather(x)
+	}
+	x.d.remove(j)
+	// borrow elements from the following nodes
+	u = x
+	for u.d.n < l.b-1 && u.n != l.r {
+		u.d.add(u.d.n, u.n.d.remove(0))
+		u = u.n
+	}
+	if u.d.n == 0 {
+		l.unlink(u)
+	}
+	l.n--
+	if j < x.d.n {
+		return v, x, j
+	}
+	return v, x.n, 0
+}
+
+// spread turns b full nodes,
+// starting at u, into b+1 nodes.
+func (l *SEList[T]) spread(u *senode[T]) {
+	w := u
+	for k := 0; k < l.b; k++ {
+		w = w.n
+	}
+	w = l.addBefore(w)
+	for w != u {
+		for w.d.n < l.b {
+			w.d.add(0, w.p.d.remove(w.p.d.n-1))
+		}
+		w = w.p
+	}
+}
+
+// gather turns b nodes with b-1 elements,
+// starting at u, into b-1 nodes.
+func (l *SEList[T]) gather(u *senode[T]) {
+	w := u
+	for k := 0; k < l.b-1; k++ {
+		for w.d.n < l.b {
+			w.d.add(w.d.n, w.n.d.remove(0))
+		}
+		w = w.n
+	}
+	l.unlink(w)
+}
+
+// addBefore adds and returns a
+// new node before the given node.
+func (l *SEList[T]) addBefore(w *senode[T]) *senode[T] {
+	u := &senode[T]{
+		n: w,
+		p: w.p,
+		d: bdeque[T]{s: make([]T, l.b+1)},
+	}
+	u.n.p = u
+	u.p.n = u
+	return u
+}
+
+// unlink removes the given node from the list,
+// but leaves the pointers of the node intact.
+func (l *SEList[T]) unlink(u *senode[T]) {
+	u.p.n = u.n
+	u.n.p = u.p
+}
+
+// AddFirst adds an element to the
+// front of the list.
+//
+// This operation has an amortized time
+// complexity of O(b).
+func (l *SEList[T]) AddFirst(v T) { l.Add(0, v) }
+
+// AddLast adds an element to the
+// back of the list.
+//
+// This operation has a time complexity of O(1).
+func (l *SEList[T]) AddLast(v T) { l.Add(l.n, v) }
+
+// RemoveFirst removes and returns the
+// element at the front of the list.
+//
+// This operation has an amortized time
+// complexity of O(b).
+func (l *SEList[T]) RemoveFirst() (T, bool) { return l.Remove(0) }
+
+// RemoveLast removes and returns the
+// element at the back of the list.
+//
+// This operation has an amortized time
+// complexity of O(b).
+func (l *SEList[T]) RemoveLast() (T, bool) { return l.Remove(l.n - 1) }
+
+// All returns an iterator over the indices and
+// elements of the list, in order.
+//
+// This operation has a time complexity of O(n).
+func (l *SEList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		i := 0
+		for u := l.r.n; u != l.r; u = u.n {
+			for j := 0; j < u.d.n; j++ {
+				if !yield(i, u.d.get(j)) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indices
+// and elements of the list, in reverse order.
+//
+// This operation has a time complexity of O(n).
+func (l *SEList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		i := l.n - 1
+		for u := l.r.p; u != l.r; u = u.p {
+			for j := u.d.n - 1; j >= 0; j-- {
+				if !yield(i, u.d.get(j)) {
+					return
+				}
+				i--
+			}
+		}
+	}
+}
+
+// Cursor returns a cursor positioned before
+// the first and after the last element.
+func (l *SEList[T]) Cursor() *SEListCursor[T] {
+	l.init()
+	return &SEListCursor[T]{l: l, u: l.r}
+}
+
+// SEListCursor walks a space-efficient linked
+// list in both directions and allows for removal
+// of the current element. The list must not be
+// modified other than through the cursor while
+// the cursor is in use.
+type SEListCursor[T any] struct {
+	l  *SEList[T] // list
+	u  *senode[T] // current node
+	j  int        // offset within the current node
+	rm bool       // current element removed
+}
+
+// Next advances the cursor to the next element
+// and reports whether there is such an element.
+// After the last element, the cursor wraps around.
+//
+// This operation has a time complexity of O(1).
+func (c *SEListCursor[T]) Next() bool {
+	if c.rm {
+		// the cursor already is at the successor
+		c.rm = false
+		return c.u != c.l.r
+	}
+	if c.u == c.l.r {
+		c.u, c.j = c.u.n, 0
+	} else if c.j++; c.j >= c.u.d.n {
+		c.u, c.j = c.u.n, 0
+	}
+	return c.u != c.l.r
+}
+
+// Prev moves the cursor to the previous element
+// and reports whether there is such an element.
+// Before the first element, the cursor wraps around.
+//
+// This operation has a time complexity of O(1).
+func (c *SEListCursor[T]) Prev() bool {
+	c.rm = false
+	if c.u == c.l.r {
+		c.u = c.u.p
+		c.j = c.u.d.n - 1
+	} else if c.j--; c.j < 0 {
+		c.u = c.u.p
+		c.j = c.u.d.n - 1
+	}
+	return c.u != c.l.r
+}
+
+// Value returns the current element.
+func (c *SEListCursor[T]) Value() (T, bool) {
+	if c.u == c.l.r || c.rm {
+		return *new(T), false
+	}
+	return c.u.d.get(c.j), true
+}
+
+// Set sets the current element
+// and returns the old one.
+func (c *SEListCursor[T]) Set(v T) (T, bool) {
+	if c.u == c.l.r || c.rm {
+		return *new(T), false
+	}
+	return c.u.d.set(c.j, v), true
+}
+
+// Remove removes and returns the current element.
+// Afterwards, the cursor is positioned between the
+// neighbours of the removed element and Next and
+// Prev move to its successor and predecessor.
+//
+// This operation has an amortized time
+// complexity of O(b).
+func (c *SEListCursor[T]) Remove() (T, bool) {
+	if c.u == c.l.r || c.rm {
+		return *new(T), false
+	}
+	var v T
+	v, c.u, c.j = c.l.remove(c.u, c.j)
+	c.rm = true
+	return v, true
+}
+
+// bdeque is a bounded dequeue
+// which stores the block of
+// elements of an SEList node.
+type bdeque[T any] struct {
+	s []T // backing slice
+	r int // read offset
+	n int // number of elements
+}
+
+func (d *bdeque[T]) get(i int) T { return d.s[(d.r+i)%len(d.s)] }
+
+func (d *bdeque[T]) set(i int, v T) T {
+	t := d.s[(d.r+i)%len(d.s)]
+	d.s[(d.r+i)%len(d.s)] = v
+	return t
+}
+
+func (d *bdeque[T]) add(i int, v T) {
+	if i < d.n/2 {
+		// shift left one position
+		d.r = (d.r + len(d.s) - 1) % len(d.s)
+		for j := 0; j < i; j++ {
+			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j+1)%len(d.s)]
+		}
+	} else {
+		// shift right one position
+		for j := d.n; j > i; j-- {
+			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j-1)%len(d.s)]
+		}
+	}
+	d.s[(d.r+i)%len(d.s)] = v
+	d.n++
+}
+
+func (d *bdeque[T]) remove(i int) T {
+	t := d.s[(d.r+i)%len(d.s)]
+	if i < d.n/2 {
+		// shift right one position
+		for j := i; j > 0; j-- {
+			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j-1)%len(d.s)]
+		}
+		d.s[d.r] = *new(T)
+		d.r = (d.r + 1) % len(d.s)
+	} else {
+		// shift left one position
+		for j := i; j < d.n-1; j++ {
+			d.s[(d.r+j)%len(d.s)] = d.s[(d.r+j+1)%len(d.s)]
+		}
+		d.s[(d.r+d.n-1)%len(d.s)] = *new(T)
+	}
+	d.n--
+	return t
+}
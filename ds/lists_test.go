@@ -4,11 +4,15 @@
 
 package ds
 
-import "testing"
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
 
 func TestSListStack(t *testing.T) {
 	const n = 65
-	var l SList
+	var l SList[int]
 
 	for i := 0; i < n; i++ {
 		l.Push(i)
@@ -38,7 +42,7 @@ func TestSListStack(t *testing.T) {
 
 func TestSListQueue(t *testing.T) {
 	const n = 65
-	var l SList
+	var l SList[int]
 
 	for i := 0; i < n; i++ {
 		l.Enqueue(i)
@@ -74,7 +78,7 @@ func TestSListQueue(t *testing.T) {
 
 func TestDList(t *testing.T) {
 	const n = 65
-	var l DList
+	var l DList[int]
 
 	if _, ok := l.Get(0); ok {
 		t.Errorf("no element at index 0")
@@ -112,7 +116,7 @@ func TestDList(t *testing.T) {
 			t.Errorf("not found: %d", i)
 			continue
 		}
-		l.Set(i, v.(int)*v.(int))
+		l.Set(i, v*v)
 	}
 
 	for i := n - 1; i >= 0; i -= 2 {
@@ -139,3 +143,451 @@ func TestDList(t *testing.T) {
 		t.Errorf("want %d, got %d", n, l.Len())
 	}
 }
+
+func TestSListCursor(t *testing.T) {
+	const n = 65
+	var l SList[int]
+
+	c := l.Cursor()
+	if c.Next() {
+		t.Errorf("no element in list expected")
+	}
+	if _, ok := c.Remove(); ok {
+		t.Errorf("no element to remove expected")
+	}
+
+	for i := 0; i < n; i++ {
+		l.Enqueue(i)
+	}
+	for c = l.Cursor(); c.Next(); {
+		v, ok := c.Value()
+		if !ok {
+			t.Errorf("no current element")
+			continue
+		}
+		if v%2 == 0 {
+			if r, ok := c.Remove(); !ok || r != v {
+				t.Errorf("want %d, got %d", v, r)
+			}
+			if _, ok := c.Remove(); ok {
+				t.Errorf("no element to remove expected")
+			}
+		} else {
+			c.Set(v * v)
+		}
+	}
+	if l.Len() != n/2 {
+		t.Errorf("want %d, got %d", n/2, l.Len())
+	}
+	for i, v := range l.All() {
+		if e := (2*i + 1) * (2*i + 1); v != e {
+			t.Errorf("want %d, got %d", e, v)
+		}
+	}
+
+	l.Enqueue(n)
+	for i := 0; i < n/2; i++ {
+		l.Dequeue()
+	}
+	if v, ok := l.Dequeue(); !ok || v != n {
+		t.Errorf("want %d, got %d", n, v)
+	}
+}
+
+func TestDListCursor(t *testing.T) {
+	const n = 65
+	var l DList[int]
+
+	c := l.Cursor()
+	if c.Next() || c.Prev() {
+		t.Errorf("no element in list expected")
+	}
+	if _, ok := c.Remove(); ok {
+		t.Errorf("no element to remove expected")
+	}
+
+	for i := 0; i < n; i++ {
+		l.AddLast(i)
+	}
+	for c = l.Cursor(); c.Prev(); {
+		v, ok := c.Value()
+		if !ok {
+			t.Errorf("no current element")
+			continue
+		}
+		if v%2 == 0 {
+			if r, ok := c.Remove(); !ok || r != v {
+				t.Errorf("want %d, got %d", v, r)
+			}
+			if _, ok := c.Value(); ok {
+				t.Errorf("no current element expected")
+			}
+			if _, ok := c.Remove(); ok {
+				t.Errorf("no element to remove expected")
+			}
+		} else {
+			c.Set(v * v)
+		}
+	}
+	if l.Len() != n/2 {
+		t.Errorf("want %d, got %d", n/2, l.Len())
+	}
+	for i, v := range l.All() {
+		if e := (2*i + 1) * (2*i + 1); v != e {
+			t.Errorf("want %d, got %d", e, v)
+		}
+	}
+
+	c = l.Cursor()
+	c.Next()
+	c.Remove()
+	if !c.Next() {
+		t.Errorf("element expected")
+	}
+	if v, _ := c.Value(); v != 9 {
+		t.Errorf("want %d, got %d", 9, v)
+	}
+	if c.Prev() {
+		t.Errorf("no previous element expected")
+	}
+}
+
+func TestDListElements(t *testing.T) {
+	var l DList[int]
+	if l.Front() != nil || l.Back() != nil {
+		t.Errorf("no element in list expected")
+	}
+
+	// es are the handles of the elements, in order
+	var es []*Element[int]
+	check := func() {
+		t.Helper()
+		if err := checkDList(&l); err != nil {
+			t.Fatal(err)
+		}
+		if l.Len() != len(es) {
+			t.Fatalf("want %d, got %d", len(es), l.Len())
+		}
+		i := 0
+		for e := l.Front(); e != nil; e = l.Next(e) {
+			if e != es[i] {
+				t.Fatalf("index %d: want %d, got %d", i, es[i].Value(), e.Value())
+			}
+			if v, _ := l.Get(i); v != e.Value() {
+				t.Fatalf("index %d: want %d, got %d", i, e.Value(), v)
+			}
+			i++
+		}
+		if len(es) > 0 && (l.Back() != es[len(es)-1] || l.Prev(l.Front()) != nil) {
+			t.Fatalf("invalid front or back")
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 2000; k++ {
+		if len(es) == 0 {
+			es = append(es, l.PushBack(k))
+			continue
+		}
+		i := r.Intn(len(es))
+		switch r.Intn(8) {
+		case 0:
+			es = slices.Insert(es, 0, l.PushFront(k))
+		case 1:
+			es = append(es, l.PushBack(k))
+		case 2:
+			es = slices.Insert(es, i, l.InsertBefore(k, es[i]))
+		case 3:
+			es = slices.Insert(es, i+1, l.InsertAfter(k, es[i]))
+		case 4:
+			e := es[i]
+			if v, ok := l.RemoveElement(e); !ok || v != e.Value() {
+				t.Fatalf("want %d, got %d", e.Value(), v)
+			}
+			if _, ok := l.RemoveElement(e); ok {
+				t.Fatalf("element removed twice")
+			}
+			es = slices.Delete(es, i, i+1)
+		case 5:
+			e := es[i]
+			l.MoveToFront(e)
+			es = slices.Insert(slices.Delete(es, i, i+1), 0, e)
+		case 6:
+			e := es[i]
+			l.MoveToBack(e)
+			es = append(slices.Delete(es, i, i+1), e)
+		case 7:
+			if v := es[i].Set(k); v == k {
+				t.Fatalf("old value expected")
+			}
+		}
+		check()
+	}
+
+	// the handles remain valid after splitting and splicing
+	n := len(es)
+	o, ok := l.SplitAt(n / 3)
+	if !ok {
+		t.Fatalf("cannot split at %d", n/3)
+	}
+	if err := checkDList(o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Len() != n-n/3 || o.Front() != es[n/3] || o.Back() != es[n-1] {
+		t.Fatalf("invalid split list")
+	}
+	moved := es[n/3:]
+	es = es[:n/3]
+	check()
+	o.MoveToFront(moved[len(moved)-1])
+	moved = append(moved[len(moved)-1:], moved[:len(moved)-1]...)
+	l.Splice(o)
+	es = append(es, moved...)
+	check()
+	if o.Len() != 0 || o.Front() != nil {
+		t.Errorf("no element in list expected")
+	}
+	if err := checkDList(o); err != nil {
+		t.Fatal(err)
+	}
+	if o.PushBack(1); o.Len() != 1 || o.Front().Value() != 1 {
+		t.Errorf("want %d, got %d", 1, o.Len())
+	}
+	if _, ok := l.SplitAt(l.Len() + 1); ok {
+		t.Errorf("split at %d: want false, got true", l.Len()+1)
+	}
+	if s, _ := l.SplitAt(l.Len()); s.Len() != 0 {
+		t.Errorf("want %d, got %d", 0, s.Len())
+	}
+	l.Splice(&l)
+	check()
+}
+
+func TestDListStaleElements(t *testing.T) {
+	var l, o DList[int]
+	stale := func(e *Element[int]) {
+		t.Helper()
+		n := l.Len()
+		if _, ok := l.RemoveElement(e); ok {
+			t.Errorf("element %d: not in the list", e.Value())
+		}
+		if l.InsertBefore(0, e) != nil || l.InsertAfter(0, e) != nil {
+			t.Errorf("element %d: cannot insert next to it", e.Value())
+		}
+		if l.Next(e) != nil || l.Prev(e) != nil {
+			t.Errorf("element %d: no neighbours expected", e.Value())
+		}
+		l.MoveToFront(e)
+		l.MoveToBack(e)
+		if err := checkDList(&l); err != nil {
+			t.Fatal(err)
+		}
+		if l.Len() != n {
+			t.Errorf("want %d, got %d", n, l.Len())
+		}
+	}
+
+	// removed by Clear
+	e := l.PushBack(1)
+	l.PushBack(2)
+	l.Clear()
+	stale(e)
+	if e.Value() != 1 {
+		t.Errorf("want %d, got %d", 1, e.Value())
+	}
+
+	// removed by index and by range
+	e = l.PushBack(1)
+	f := l.PushBack(2)
+	l.PushBack(3)
+	l.Remove(0)
+	l.RemoveRange(0, 1)
+	stale(e)
+	stale(f)
+
+	// removed by a cursor, which still
+	// moves to the neighbours afterwards
+	e = l.PushFront(4)
+	c := l.Cursor()
+	c.Next()
+	c.Remove()
+	stale(e)
+	if !c.Next() {
+		t.Fatalf("element expected")
+	}
+	if v, _ := c.Value(); v != 3 {
+		t.Errorf("want %d, got %d", 3, v)
+	}
+	c.Remove()
+	if c.Prev() {
+		t.Errorf("no element expected")
+	}
+
+	// in another list
+	l.PushBack(5)
+	stale(o.PushBack(6))
+	if err := checkDList(&o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Len() != 1 {
+		t.Errorf("want %d, got %d", 1, o.Len())
+	}
+
+	// the ownership moves with Splice and
+	// SplitAt, and is dropped by Clear
+	var es []*Element[int]
+	o.Clear()
+	for i := 0; i < 10; i++ {
+		es = append(es, o.PushBack(i))
+	}
+	l.Clear()
+	l.Splice(&o)
+	for _, i := range []int{2, 7} {
+		s, _ := l.SplitAt(i)
+		for j, e := range es {
+			if in := l.Next(e) != nil || l.Back() == e; in != (j < i) {
+				t.Errorf("split at %d: element %d: want %t, got %t", i, j, j < i, in)
+			}
+			if in := s.Next(e) != nil || s.Back() == e; in != (j >= i) {
+				t.Errorf("split at %d: element %d: want %t, got %t", i, j, j >= i, in)
+			}
+		}
+		l.Splice(s)
+	}
+	s, _ := l.SplitAt(5)
+	l.Clear()
+	for j, e := range es {
+		if _, ok := l.RemoveElement(e); ok {
+			t.Errorf("element %d: not in the list", j)
+		}
+		if (s.Prev(e) != nil || s.Front() == e) != (j >= 5) {
+			t.Errorf("element %d: want %t", j, j >= 5)
+		}
+	}
+}
+
+func TestSEList(t *testing.T) {
+	const n = 65
+	var l SEList[int]
+
+	if _, ok := l.Get(0); ok {
+		t.Errorf("no element at index 0")
+	}
+	if _, ok := l.Set(0, 1); ok {
+		t.Errorf("no element to set at index 0")
+	}
+	if _, ok := l.Remove(0); ok {
+		t.Errorf("no element to remove at index 0")
+	}
+	if ok := l.Add(1, 1); ok {
+		t.Errorf("cannot add at index 1")
+	}
+
+	for i := 0; i < n; i++ {
+		if ok := l.Add(i, i); !ok {
+			t.Errorf("cannot add: %d", i)
+		}
+	}
+	if l.b != 16 {
+		t.Errorf("want %d, got %d", 16, l.b)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := l.Get(i); !ok || v != i {
+			t.Errorf("want %d, got %d", i, v)
+		}
+	}
+
+	for _, b := range []int{1, 2, 3, 5} {
+		l := NewSEList[int](b)
+		r := rand.New(rand.NewSource(int64(b)))
+		var e []int
+		for k := 0; k < 2000; k++ {
+			switch i := r.Intn(len(e) + 1); {
+			case r.Intn(3) > 0 || len(e) == 0:
+				l.Add(i, k)
+				e = append(e[:i], append([]int{k}, e[i:]...)...)
+			case i < len(e):
+				v, ok := l.Remove(i)
+				if !ok || v != e[i] {
+					t.Fatalf("b=%d: want %d, got %d", b, e[i], v)
+				}
+				e = append(e[:i], e[i+1:]...)
+			}
+			checkSEList(t, l)
+		}
+		for i, x := range e {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Fatalf("b=%d: want %d, got %d", b, x, v)
+			}
+		}
+	}
+}
+
+func TestSEListCursor(t *testing.T) {
+	const n = 65
+	l := NewSEList[int](3)
+
+	for i := 0; i < n; i++ {
+		l.AddLast(i)
+	}
+	for c := l.Cursor(); c.Next(); {
+		v, ok := c.Value()
+		if !ok {
+			t.Errorf("no current element")
+			continue
+		}
+		if v%2 == 0 {
+			if r, ok := c.Remove(); !ok || r != v {
+				t.Errorf("want %d, got %d", v, r)
+			}
+			if _, ok := c.Remove(); ok {
+				t.Errorf("no element to remove expected")
+			}
+		} else {
+			c.Set(v * v)
+		}
+		checkSEList(t, l)
+	}
+	if l.Len() != n/2 {
+		t.Errorf("want %d, got %d", n/2, l.Len())
+	}
+	for i, v := range l.All() {
+		if e := (2*i + 1) * (2*i + 1); v != e {
+			t.Errorf("want %d, got %d", e, v)
+		}
+	}
+
+	c := l.Cursor()
+	for i := n/2 - 1; c.Prev(); i-- {
+		if e := (2*i + 1) * (2*i + 1); i%2 == 0 {
+			if v, ok := c.Remove(); !ok || v != e {
+				t.Errorf("want %d, got %d", e, v)
+			}
+		} else if v, _ := c.Value(); v != e {
+			t.Errorf("want %d, got %d", e, v)
+		}
+	}
+	if l.Len() != n/4 {
+		t.Errorf("want %d, got %d", n/4, l.Len())
+	}
+	checkSEList(t, l)
+}
+
+// checkSEList checks that all blocks, except
+// the last one, have between b-1 and b+1 elements.
+func checkSEList(t *testing.T, l *SEList[int]) {
+	t.Helper()
+	n := 0
+	for u := l.r.n; u != l.r; u = u.n {
+		if u.n.p != u || u.p.n != u {
+			t.Fatalf("broken links")
+		}
+		if u.d.n > l.b+1 || u.d.n == 0 || (u.n != l.r && u.d.n < l.b-1) {
+			t.Fatalf("block size %d out of bounds (b=%d)", u.d.n, l.b)
+		}
+		n += u.d.n
+	}
+	if n != l.n {
+		t.Fatalf("want %d, got %d", l.n, n)
+	}
+}
@@ -0,0 +1,814 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"iter"
+	"sync"
+)
+
+// The persistent data structures are values which
+// never change. Every modification returns a new
+// version, which shares most of its structure with
+// the old one. Hence, a snapshot is a copy of the
+// value, and all versions are safe for concurrent
+// use. The zero values are empty.
+
+// --- PersistentSList -------
+
+// PersistentSList is a persistent
+// singly-linked list, which can be
+// used as stack.
+type PersistentSList[T any] struct {
+	h *snode[T] // head pointer
+	n int       // number of elements
+}
+
+// Len returns the number
+// of elements in the list.
+func (l PersistentSList[T]) Len() int { return l.n }
+
+// Push returns a new version of the list with
+// the given element added to the head.
+//
+// This operation has a time complexity of O(1).
+func (l PersistentSList[T]) Push(v T) PersistentSList[T] {
+	return PersistentSList[T]{h: &snode[T]{n: l.h, v: v}, n: l.n + 1}
+}
+
+// Pop returns a new version of the list without
+// its head and the removed element.
+//
+// This operation has a time complexity of O(1).
+func (l PersistentSList[T]) Pop() (PersistentSList[T], T, bool) {
+	if l.n == 0 {
+		return l, *new(T), false
+	}
+	return PersistentSList[T]{h: l.h.n, n: l.n - 1}, l.h.v, true
+}
+
+// Peek returns the element at
+// the head of the list.
+//
+// This operation has a time complexity of O(1).
+func (l PersistentSList[T]) Peek() (T, bool) {
+	if l.n == 0 {
+		return *new(T), false
+	}
+	return l.h.v, true
+}
+
+// Get returns the element at the
+// given index.
+//
+// This operation has a time complexity of O(i).
+func (l PersistentSList[T]) Get(i int) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	u := l.h
+	for ; i > 0; i-- {
+		u = u.n
+	}
+	return u.v, true
+}
+
+// Set returns a new version of the list with
+// the element at the given index replaced
+// and the old element.
+//
+// This operation has a time complexity of O(i).
+func (l PersistentSList[T]) Set(i int, v T) (PersistentSList[T], T, bool) {
+	t, ok := l.Get(i)
+	if !ok {
+		return l, t, false
+	}
+	return l.splice(i, 1, v), t, true
+}
+
+// Add returns a new version of the list with the
+// given element added at the given index and
+// reports whether it was successful or not.
+//
+// This operation has a time complexity of O(i).
+func (l PersistentSList[T]) Add(i int, v T) (PersistentSList[T], bool) {
+	if i < 0 || i > l.n {
+		return l, false
+	}
+	return l.splice(i, 0, v), true
+}
+
+// Remove returns a new version of the list without
+// the element at the given index and the removed
+// element.
+//
+// This operation has a time complexity of O(i).
+func (l PersistentSList[T]) Remove(i int) (PersistentSList[T], T, bool) {
+	t, ok := l.Get(i)
+	if !ok {
+		return l, t, false
+	}
+	return l.splice(i, 1), t, true
+}
+
+// All returns an iterator over the indices and
+// elements of the list, from the head to the tail.
+//
+// This operation has a time complexity of O(n).
+func (l PersistentSList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for u := l.h; u != nil; u = u.n {
+			if !yield(i, u.v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// splice returns a new version of the list in
+// which the k elements starting at the index i
+// are replaced by vs. The first i nodes are
+// copied and the rest is shared.
+func (l PersistentSList[T]) splice(i, k int, vs ...T) PersistentSList[T] {
+	n := l.n - k + len(vs)
+	p := make([]T, i)
+	u := l.h
+	for j := range p {
+		p[j], u = u.v, u.n
+	}
+	for ; k > 0; k-- {
+		u = u.n
+	}
+	for j := len(vs) - 1; j >= 0; j-- {
+		u = &snode[T]{n: u, v: vs[j]}
+	}
+	for j := len(p) - 1; j >= 0; j-- {
+		u = &snode[T]{n: u, v: p[j]}
+	}
+	return PersistentSList[T]{h: u, n: n}
+}
+
+// --- PersistentList -------
+
+// ftnode represents a node in a finger
+// tree, which is either a leaf with a
+// value or has two or three children.
+type ftnode[T any] struct {
+	c []*ftnode[T] // children, nil for a leaf
+	n int          // number of elements
+	v T            // value of a leaf
+}
+
+func leaf[T any](v T) *ftnode[T] { return &ftnode[T]{n: 1, v: v} }
+
+func branch[T any](c ...*ftnode[T]) *ftnode[T] {
+	return &ftnode[T]{c: c, n: weight(c)}
+}
+
+// weight returns the number of
+// elements in the given nodes.
+func weight[T any](ds []*ftnode[T]) int {
+	n := 0
+	for _, d := range ds {
+		n += d.n
+	}
+	return n
+}
+
+// ftree represents a 2-3 finger tree, as described
+// by Hinze and Paterson. The prefix and the suffix
+// hold one to four nodes each and the middle tree
+// holds the nodes of the next level. A tree with a
+// single node has only a prefix, the empty tree is
+// nil. Trees and nodes are never modified.
+type ftree[T any] struct {
+	pr, sf []*ftnode[T] // prefix and suffix
+	m      *ftree[T]    // middle tree
+	n      int          // number of elements
+}
+
+func (t *ftree[T]) len() int {
+	if t == nil {
+		return 0
+	}
+	return t.n
+}
+
+func deep[T any](pr []*ftnode[T], m *ftree[T], sf []*ftnode[T]) *ftree[T] {
+	return &ftree[T]{pr: pr, m: m, sf: sf, n: weight(pr) + m.len() + weight(sf)}
+}
+
+func pushFront[T any](t *ftree[T], x *ftnode[T]) *ftree[T] {
+	switch {
+	case t == nil:
+		return &ftree[T]{pr: []*ftnode[T]{x}, n: x.n}
+	case len(t.sf) == 0:
+		return deep([]*ftnode[T]{x}, nil, t.pr)
+	case len(t.pr) == 4:
+		m := pushFront(t.m, branch(t.pr[1], t.pr[2], t.pr[3]))
+		return deep([]*ftnode[T]{x, t.pr[0]}, m, t.sf)
+	}
+	return deep(append([]*ftnode[T]{x}, t.pr...), t.m, t.sf)
+}
+
+func pushBack[T any](t *ftree[T], x *ftnode[T]) *ftree[T] {
+	switch {
+	case t == nil:
+		return &ftree[T]{pr: []*ftnode[T]{x}, n: x.n}
+	case len(t.sf) == 0:
+		return deep(t.pr, nil, []*ftnode[T]{x})
+	case len(t.sf) == 4:
+		m := pushBack(t.m, branch(t.sf[0], t.sf[1], t.sf[2]))
+		return deep(t.pr, m, []*ftnode[T]{t.sf[3], x})
+	}
+	// the full slice expression forces a copy
+	return deep(t.pr, t.m, append(t.sf[:len(t.sf):len(t.sf)], x))
+}
+
+// viewFront returns the first node
+// of a non-empty tree and the rest.
+func viewFront[T any](t *ftree[T]) (*ftnode[T], *ftree[T]) {
+	if len(t.sf) == 0 {
+		return t.pr[0], nil
+	}
+	return t.pr[0], deepL(t.pr[1:], t.m, t.sf)
+}
+
+// viewBack returns the last node of
+// a non-empty tree and the rest.
+func viewBack[T any](t *ftree[T]) (*ftnode[T], *ftree[T]) {
+	if len(t.sf) == 0 {
+		return t.pr[0], nil
+	}
+	return t.sf[len(t.sf)-1], deepR(t.pr, t.m, t.sf[:len(t.sf)-1])
+}
+
+// deepL is like deep, but the prefix may be empty.
+func deepL[T any](pr []*ftnode[T], m *ftree[T], sf []*ftnode[T]) *ftree[T] {
+	if len(pr) > 0 {
+		return deep(pr, m, sf)
+	}
+	if m == nil {
+		return digits(sf)
+	}
+	x, m := viewFront(m)
+	return deep(x.c, m, sf)
+}
+
+// deepR is like deep, but the suffix may be empty.
+func deepR[T any](pr []*ftnode[T], m *ftree[T], sf []*ftnode[T]) *ftree[T] {
+	if len(sf) > 0 {
+		return deep(pr, m, sf)
+	}
+	if m == nil {
+		return digits(pr)
+	}
+	x, m := viewBack(m)
+	return deep(pr, m, x.c)
+}
+
+// digits returns a tree with the given nodes.
+func digits[T any](ds []*ftnode[T]) *ftree[T] {
+	var t *ftree[T]
+	for _, d := range ds {
+		t = pushBack(t, d)
+	}
+	return t
+}
+
+// concat returns the concatenation of a,
+// the nodes ts and b.
+func concat[T any](a *ftree[T], ts []*ftnode[T], b *ftree[T]) *ftree[T] {
+	switch {
+	case a == nil:
+		for i := len(ts) - 1; i >= 0; i-- {
+			b = pushFront(b, ts[i])
+		}
+		return b
+	case b == nil:
+		for _, x := range ts {
+			a = pushBack(a, x)
+		}
+		return a
+	case len(a.sf) == 0:
+		return pushFront(concat(nil, ts, b), a.pr[0])
+	case len(b.sf) == 0:
+		return pushBack(concat(a, ts, nil), b.pr[0])
+	}
+	ns := make([]*ftnode[T], 0, len(a.sf)+len(ts)+len(b.pr))
+	ns = append(append(append(ns, a.sf...), ts...), b.pr...)
+	return deep(a.pr, concat(a.m, branches(ns), b.m), b.sf)
+}
+
+// branches groups at least two nodes
+// into nodes with two or three children.
+func branches[T any](ns []*ftnode[T]) []*ftnode[T] {
+	var bs []*ftnode[T]
+	for len(ns) > 4 {
+		bs = append(bs, branch(ns[:3:3]...))
+		ns = ns[3:]
+	}
+	switch len(ns) {
+	case 4:
+		return append(bs, branch(ns[:2:2]...), branch(ns[2:]...))
+	default:
+		return append(bs, branch(ns...))
+	}
+}
+
+// split splits a tree at the index i, with
+// 0 <= i < t.len(), into the trees before
+// and after the node which contains the
+// element at i.
+func split[T any](t *ftree[T], i int) (*ftree[T], *ftnode[T], *ftree[T]) {
+	if len(t.sf) == 0 {
+		return nil, t.pr[0], nil
+	}
+	if i < weight(t.pr) {
+		l, x, r := splitDigits(t.pr, i)
+		return digits(l), x, deepL(r, t.m, t.sf)
+	}
+	i -= weight(t.pr)
+	if i < t.m.len() {
+		ml, xs, mr := split(t.m, i)
+		l, x, r := splitDigits(xs.c, i-ml.len())
+		return deepR(t.pr, ml, l), x, deepL(r, mr, t.sf)
+	}
+	l, x, r := splitDigits(t.sf, i-t.m.len())
+	return deepR(t.pr, t.m, l), x, digits(r)
+}
+
+// splitDigits splits the nodes at the
+// node which contains the index i.
+func splitDigits[T any](ds []*ftnode[T], i int) ([]*ftnode[T], *ftnode[T], []*ftnode[T]) {
+	for k, d := range ds {
+		if i < d.n {
+			return ds[:k:k], d, ds[k+1:]
+		}
+		i -= d.n
+	}
+	panic("ds: index out of range")
+}
+
+// lookup returns the element at the
+// index i of a node list.
+func lookup[T any](ds []*ftnode[T], i int) T {
+	for {
+		for _, d := range ds {
+			if i < d.n {
+				if d.c == nil {
+					return d.v
+				}
+				ds = d.c
+				break
+			}
+			i -= d.n
+		}
+	}
+}
+
+// update returns a copy of the node list with
+// the element at the index i replaced by v.
+func update[T any](ds []*ftnode[T], i int, v T) []*ftnode[T] {
+	c := make([]*ftnode[T], len(ds))
+	copy(c, ds)
+	for k, d := range c {
+		if i < d.n {
+			if d.c == nil {
+				c[k] = leaf(v)
+			} else {
+				c[k] = &ftnode[T]{c: update(d.c, i, v), n: d.n}
+			}
+			return c
+		}
+		i -= d.n
+	}
+	panic("ds: index out of range")
+}
+
+// PersistentList is a persistent list with
+// efficient access to all indices, based on
+// a 2-3 finger tree. Adding and removing at
+// both ends takes amortized constant time
+// as long as old versions are not modified
+// repeatedly; all other operations take
+// logarithmic time.
+type PersistentList[T any] struct {
+	t *ftree[T]
+}
+
+// Len returns the number
+// of elements in the list.
+func (l PersistentList[T]) Len() int { return l.t.len() }
+
+// Get returns the element at the
+// given index.
+//
+// This operation has a time complexity
+// of O(log min{i, n-i}).
+func (l PersistentList[T]) Get(i int) (T, bool) {
+	if i < 0 || i > l.Len()-1 {
+		return *new(T), false
+	}
+	for t := l.t; ; t = t.m {
+		if w := weight(t.pr); i < w {
+			return lookup(t.pr, i), true
+		}
+		i -= weight(t.pr)
+		if i >= t.m.len() {
+			return lookup(t.sf, i-t.m.len()), true
+		}
+	}
+}
+
+// Set returns a new version of the list with
+// the element at the given index replaced and
+// the old element.
+//
+// This operation has a time complexity
+// of O(log n).
+func (l PersistentList[T]) Set(i int, v T) (PersistentList[T], T, bool) {
+	old, ok := l.Get(i)
+	if !ok {
+		return l, old, false
+	}
+	var f func(t *ftree[T], i int) *ftree[T]
+	f = func(t *ftree[T], i int) *ftree[T] {
+		u := *t
+		if w := weight(t.pr); i < w {
+			u.pr = update(t.pr, i, v)
+		} else if i -= w; i < t.m.len() {
+			u.m = f(t.m, i)
+		} else {
+			u.sf = update(t.sf, i-t.m.len(), v)
+		}
+		return &u
+	}
+	return PersistentList[T]{f(l.t, i)}, old, true
+}
+
+// Add returns a new version of the list with the
+// given element added at the given index and
+// reports whether it was successful or not.
+//
+// This operation has a time complexity
+// of O(log n).
+func (l PersistentList[T]) Add(i int, v T) (PersistentList[T], bool) {
+	switch {
+	case i < 0 || i > l.Len():
+		return l, false
+	case i == 0:
+		return l.AddFirst(v), true
+	case i == l.Len():
+		return l.AddLast(v), true
+	}
+	a, x, b := split(l.t, i)
+	return PersistentList[T]{concat(pushBack(a, leaf(v)), nil, pushFront(b, x))}, true
+}
+
+// Remove returns a new version of the list
+// without the element at the given index
+// and the removed element.
+//
+// This operation has a time complexity
+// of O(log n).
+func (l PersistentList[T]) Remove(i int) (PersistentList[T], T, bool) {
+	if i < 0 || i > l.Len()-1 {
+		return l, *new(T), false
+	}
+	a, x, b := split(l.t, i)
+	return PersistentList[T]{concat(a, nil, b)}, x.v, true
+}
+
+// AddFirst returns a new version of the list
+// with the given element added to the front.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (l PersistentList[T]) AddFirst(v T) PersistentList[T] {
+	return PersistentList[T]{pushFront(l.t, leaf(v))}
+}
+
+// AddLast returns a new version of the list
+// with the given element added to the back.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (l PersistentList[T]) AddLast(v T) PersistentList[T] {
+	return PersistentList[T]{pushBack(l.t, leaf(v))}
+}
+
+// RemoveFirst returns a new version of the
+// list without its first element and the
+// removed element.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (l PersistentList[T]) RemoveFirst() (PersistentList[T], T, bool) {
+	if l.t == nil {
+		return l, *new(T), false
+	}
+	x, t := viewFront(l.t)
+	return PersistentList[T]{t}, x.v, true
+}
+
+// RemoveLast returns a new version of the
+// list without its last element and the
+// removed element.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (l PersistentList[T]) RemoveLast() (PersistentList[T], T, bool) {
+	if l.t == nil {
+		return l, *new(T), false
+	}
+	x, t := viewBack(l.t)
+	return PersistentList[T]{t}, x.v, true
+}
+
+// Concat returns the concatenation
+// of the list and the given one.
+//
+// This operation has a time complexity
+// of O(log min{n, m}), where m is the
+// length of the given list.
+func (l PersistentList[T]) Concat(o PersistentList[T]) PersistentList[T] {
+	return PersistentList[T]{concat(l.t, nil, o.t)}
+}
+
+// All returns an iterator over the indices
+// and elements of the list, in order.
+//
+// This operation has a time complexity of O(n).
+func (l PersistentList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		var node func(d *ftnode[T]) bool
+		node = func(d *ftnode[T]) bool {
+			if d.c == nil {
+				i++
+				return yield(i-1, d.v)
+			}
+			for _, c := range d.c {
+				if !node(c) {
+					return false
+				}
+			}
+			return true
+		}
+		for t := l.t; t != nil; t = t.m {
+			for _, d := range t.pr {
+				if !node(d) {
+					return
+				}
+			}
+		}
+		// the suffixes follow in reverse order of the levels
+		var sfs [][]*ftnode[T]
+		for t := l.t; t != nil; t = t.m {
+			sfs = append(sfs, t.sf)
+		}
+		for k := len(sfs) - 1; k >= 0; k-- {
+			for _, d := range sfs[k] {
+				if !node(d) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// --- PersistentDeque -------
+
+// stream is a lazily evaluated list,
+// which is computed at most once.
+// The nil stream is empty.
+type stream[T any] struct {
+	once sync.Once
+	f    func() *scell[T] // suspended computation
+	c    *scell[T]        // result of f
+}
+
+// scell represents a cell of a stream.
+type scell[T any] struct {
+	v T          // value
+	n *stream[T] // rest of the stream
+}
+
+// force evaluates the stream
+// and returns its first cell.
+func (s *stream[T]) force() *scell[T] {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if s.f != nil {
+			s.c, s.f = s.f(), nil
+		}
+	})
+	return s.c
+}
+
+func cons[T any](v T, s *stream[T]) *stream[T] {
+	return &stream[T]{c: &scell[T]{v: v, n: s}}
+}
+
+// take returns a stream of the
+// first n elements of s.
+func take[T any](n int, s *stream[T]) *stream[T] {
+	return &stream[T]{f: func() *scell[T] {
+		c := s.force()
+		if n == 0 || c == nil {
+			return nil
+		}
+		return &scell[T]{v: c.v, n: take(n-1, c.n)}
+	}}
+}
+
+// drop returns a stream without
+// the first n elements of s.
+func drop[T any](n int, s *stream[T]) *stream[T] {
+	return &stream[T]{f: func() *scell[T] {
+		t := s
+		for k := 0; k < n; k++ {
+			t = t.force().n
+		}
+		return t.force()
+	}}
+}
+
+// appendStream returns a stream of the
+// elements of s followed by those of t.
+func appendStream[T any](s, t *stream[T]) *stream[T] {
+	return &stream[T]{f: func() *scell[T] {
+		c := s.force()
+		if c == nil {
+			return t.force()
+		}
+		return &scell[T]{v: c.v, n: appendStream(c.n, t)}
+	}}
+}
+
+// reverseStream returns a stream of
+// the elements of s in reverse.
+func reverseStream[T any](s *stream[T]) *stream[T] {
+	return &stream[T]{f: func() *scell[T] {
+		var r *stream[T]
+		for c := s.force(); c != nil; c = c.n.force() {
+			r = cons(c.v, r)
+		}
+		return r.force()
+	}}
+}
+
+// PersistentDeque is a persistent deque, the
+// banker's deque described by Okasaki. It keeps
+// the elements in a front and a reversed rear
+// stream, which are balanced lazily such that
+// neither holds more than three times as many
+// elements as the other.
+type PersistentDeque[T any] struct {
+	f, r   *stream[T] // front and rear stream
+	lf, lr int        // lengths of the streams
+}
+
+// Len returns the number of
+// elements in the deque.
+func (d PersistentDeque[T]) Len() int { return d.lf + d.lr }
+
+// AddFirst returns a new version of the deque
+// with the given element added to the front.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) AddFirst(v T) PersistentDeque[T] {
+	d.f, d.lf = cons(v, d.f), d.lf+1
+	return d.balance()
+}
+
+// AddLast returns a new version of the deque
+// with the given element added to the back.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) AddLast(v T) PersistentDeque[T] {
+	d.r, d.lr = cons(v, d.r), d.lr+1
+	return d.balance()
+}
+
+// RemoveFirst returns a new version of the
+// deque without its first element and the
+// removed element.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) RemoveFirst() (PersistentDeque[T], T, bool) {
+	if d.lf == 0 {
+		// the rear holds at most one element
+		if d.lr == 0 {
+			return d, *new(T), false
+		}
+		return PersistentDeque[T]{}, d.r.force().v, true
+	}
+	c := d.f.force()
+	d.f, d.lf = c.n, d.lf-1
+	return d.balance(), c.v, true
+}
+
+// RemoveLast returns a new version of the
+// deque without its last element and the
+// removed element.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) RemoveLast() (PersistentDeque[T], T, bool) {
+	if d.lr == 0 {
+		// the front holds at most one element
+		if d.lf == 0 {
+			return d, *new(T), false
+		}
+		return PersistentDeque[T]{}, d.f.force().v, true
+	}
+	c := d.r.force()
+	d.r, d.lr = c.n, d.lr-1
+	return d.balance(), c.v, true
+}
+
+// First returns the element at
+// the front of the deque.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) First() (T, bool) {
+	switch {
+	case d.lf > 0:
+		return d.f.force().v, true
+	case d.lr > 0:
+		return d.r.force().v, true
+	}
+	return *new(T), false
+}
+
+// Last returns the element at
+// the back of the deque.
+//
+// This operation has an amortized time
+// complexity of O(1).
+func (d PersistentDeque[T]) Last() (T, bool) {
+	switch {
+	case d.lr > 0:
+		return d.r.force().v, true
+	case d.lf > 0:
+		return d.f.force().v, true
+	}
+	return *new(T), false
+}
+
+// All returns an iterator over the indices
+// and elements of the deque, in order.
+//
+// This operation has a time complexity of O(n).
+func (d PersistentDeque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for c := d.f.force(); c != nil; c = c.n.force() {
+			if !yield(i, c.v) {
+				return
+			}
+			i++
+		}
+		s := make([]T, 0, d.lr)
+		for c := d.r.force(); c != nil; c = c.n.force() {
+			s = append(s, c.v)
+		}
+		for j := len(s) - 1; j >= 0; j-- {
+			if !yield(i, s[j]) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// balance moves elements from the longer to
+// the shorter stream if one holds more than
+// three times as many elements as the other.
+func (d PersistentDeque[T]) balance() PersistentDeque[T] {
+	const c = 3
+	n := d.lf + d.lr
+	switch {
+	case d.lf > c*d.lr+1:
+		i := n / 2
+		d.f, d.r = take(i, d.f), appendStream(d.r, reverseStream(drop(i, d.f)))
+		d.lf, d.lr = i, n-i
+	case d.lr > c*d.lf+1:
+		i := n / 2
+		d.r, d.f = take(i, d.r), appendStream(d.f, reverseStream(drop(i, d.r)))
+		d.lr, d.lf = i, n-i
+	}
+	return d
+}
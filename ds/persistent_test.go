@@ -0,0 +1,332 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// collect returns the elements
+// of an iterator in order.
+func collect[T any](all func(yield func(int, T) bool)) []T {
+	var s []T
+	for _, v := range all {
+		s = append(s, v)
+	}
+	return s
+}
+
+func TestPersistentSList(t *testing.T) {
+	var l PersistentSList[int]
+	if _, _, ok := l.Pop(); ok {
+		t.Errorf("no element in list expected")
+	}
+	if _, ok := l.Add(1, 0); ok {
+		t.Errorf("add at index 1 of empty list: want false, got true")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var (
+		versions []PersistentSList[int]
+		models   [][]int
+		e        []int
+	)
+	for k := 0; k < 2000; k++ {
+		switch i := r.Intn(len(e) + 1); r.Intn(5) {
+		case 0:
+			l = l.Push(k)
+			e = slices.Insert(e, 0, k)
+		case 1, 2:
+			var ok bool
+			if l, ok = l.Add(i, k); !ok {
+				t.Fatalf("cannot add at %d", i)
+			}
+			e = slices.Insert(e, i, k)
+		case 3:
+			if i == len(e) {
+				continue
+			}
+			var v int
+			l, v, _ = l.Set(i, k)
+			if v != e[i] {
+				t.Fatalf("set %d: want %d, got %d", i, e[i], v)
+			}
+			e = slices.Clone(e)
+			e[i] = k
+		case 4:
+			if i == len(e) {
+				continue
+			}
+			var v int
+			l, v, _ = l.Remove(i)
+			if v != e[i] {
+				t.Fatalf("remove %d: want %d, got %d", i, e[i], v)
+			}
+			e = slices.Delete(slices.Clone(e), i, i+1)
+		}
+		if l.Len() != len(e) {
+			t.Fatalf("want %d, got %d", len(e), l.Len())
+		}
+		if k%100 == 0 {
+			versions, models = append(versions, l), append(models, slices.Clone(e))
+		}
+	}
+	for j, l := range versions {
+		if a := collect(l.All()); !slices.Equal(a, models[j]) {
+			t.Fatalf("version %d: want %v, got %v", j, models[j], a)
+		}
+		for i, x := range models[j] {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Fatalf("version %d: index %d: want %d, got %d", j, i, x, v)
+			}
+		}
+	}
+	if v, ok := l.Peek(); ok && v != e[0] {
+		t.Errorf("want %d, got %d", e[0], v)
+	}
+}
+
+func TestPersistentList(t *testing.T) {
+	var l PersistentList[int]
+	if _, _, ok := l.RemoveFirst(); ok {
+		t.Errorf("no element in list expected")
+	}
+	if _, _, ok := l.RemoveLast(); ok {
+		t.Errorf("no element in list expected")
+	}
+	if _, ok := l.Add(1, 0); ok {
+		t.Errorf("add at index 1 of empty list: want false, got true")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var (
+		versions []PersistentList[int]
+		models   [][]int
+		e        []int
+	)
+	for k := 0; k < 5000; k++ {
+		// grow at first, then keep the size
+		switch i := r.Intn(len(e) + 1); r.Intn(8) {
+		case 0:
+			l = l.AddFirst(k)
+			e = slices.Insert(slices.Clone(e), 0, k)
+		case 1:
+			l = l.AddLast(k)
+			e = append(slices.Clip(e), k)
+		case 2:
+			var ok bool
+			if l, ok = l.Add(i, k); !ok {
+				t.Fatalf("cannot add at %d", i)
+			}
+			e = slices.Insert(slices.Clone(e), i, k)
+		case 3:
+			if i == len(e) {
+				continue
+			}
+			var v int
+			l, v, _ = l.Set(i, k)
+			if v != e[i] {
+				t.Fatalf("set %d: want %d, got %d", i, e[i], v)
+			}
+			e = slices.Clone(e)
+			e[i] = k
+		case 4:
+			if i == len(e) || k < 2000 {
+				continue
+			}
+			var v int
+			l, v, _ = l.Remove(i)
+			if v != e[i] {
+				t.Fatalf("remove %d: want %d, got %d", i, e[i], v)
+			}
+			e = slices.Delete(slices.Clone(e), i, i+1)
+		case 5:
+			if len(e) == 0 || k < 2000 {
+				continue
+			}
+			var v int
+			l, v, _ = l.RemoveFirst()
+			if v != e[0] {
+				t.Fatalf("remove first: want %d, got %d", e[0], v)
+			}
+			e = e[1:]
+		case 6:
+			if len(e) == 0 || k < 2000 {
+				continue
+			}
+			var v int
+			l, v, _ = l.RemoveLast()
+			if v != e[len(e)-1] {
+				t.Fatalf("remove last: want %d, got %d", e[len(e)-1], v)
+			}
+			e = e[:len(e)-1]
+		case 7:
+			// concatenate with an older version
+			if len(versions) == 0 {
+				continue
+			}
+			j := r.Intn(len(versions))
+			if len(e)+len(models[j]) > 3000 {
+				continue
+			}
+			l = l.Concat(versions[j])
+			e = append(slices.Clip(e), models[j]...)
+		}
+		if l.Len() != len(e) {
+			t.Fatalf("want %d, got %d", len(e), l.Len())
+		}
+		if k%250 == 0 {
+			checkFingerTree(t, l.t)
+			versions, models = append(versions, l), append(models, slices.Clone(e))
+		}
+	}
+	checkFingerTree(t, l.t)
+	versions, models = append(versions, l), append(models, e)
+	for j, l := range versions {
+		if a := collect(l.All()); !slices.Equal(a, models[j]) {
+			t.Fatalf("version %d: want %v, got %v", j, models[j], a)
+		}
+		for i, x := range models[j] {
+			if v, ok := l.Get(i); !ok || v != x {
+				t.Fatalf("version %d: index %d: want %d, got %d", j, i, x, v)
+			}
+		}
+	}
+}
+
+func TestPersistentDeque(t *testing.T) {
+	var d PersistentDeque[int]
+	if _, _, ok := d.RemoveFirst(); ok {
+		t.Errorf("no element in deque expected")
+	}
+	if _, _, ok := d.RemoveLast(); ok {
+		t.Errorf("no element in deque expected")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var (
+		versions []PersistentDeque[int]
+		models   [][]int
+		e        []int
+	)
+	for k := 0; k < 5000; k++ {
+		switch r.Intn(5) {
+		case 0, 1:
+			d = d.AddFirst(k)
+			e = slices.Insert(slices.Clone(e), 0, k)
+		case 2:
+			d = d.AddLast(k)
+			e = append(slices.Clip(e), k)
+		case 3:
+			var v int
+			var ok bool
+			if d, v, ok = d.RemoveFirst(); ok != (len(e) > 0) {
+				t.Fatalf("remove first: want %t, got %t", len(e) > 0, ok)
+			}
+			if ok {
+				if v != e[0] {
+					t.Fatalf("remove first: want %d, got %d", e[0], v)
+				}
+				e = e[1:]
+			}
+		case 4:
+			var v int
+			var ok bool
+			if d, v, ok = d.RemoveLast(); ok != (len(e) > 0) {
+				t.Fatalf("remove last: want %t, got %t", len(e) > 0, ok)
+			}
+			if ok {
+				if v != e[len(e)-1] {
+					t.Fatalf("remove last: want %d, got %d", e[len(e)-1], v)
+				}
+				e = e[:len(e)-1]
+			}
+		}
+		if d.Len() != len(e) {
+			t.Fatalf("want %d, got %d", len(e), d.Len())
+		}
+		if d.lf > 3*d.lr+1 || d.lr > 3*d.lf+1 {
+			t.Fatalf("unbalanced: %d and %d", d.lf, d.lr)
+		}
+		if len(e) > 0 {
+			if v, _ := d.First(); v != e[0] {
+				t.Fatalf("first: want %d, got %d", e[0], v)
+			}
+			if v, _ := d.Last(); v != e[len(e)-1] {
+				t.Fatalf("last: want %d, got %d", e[len(e)-1], v)
+			}
+		}
+		if k%250 == 0 {
+			versions, models = append(versions, d), append(models, slices.Clone(e))
+		}
+	}
+
+	// old versions are forced concurrently
+	var wg sync.WaitGroup
+	for j := range versions {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			if a := collect(versions[j].All()); !slices.Equal(a, models[j]) {
+				t.Errorf("version %d: want %v, got %v", j, models[j], a)
+			}
+			if j > 0 {
+				if a := collect(versions[j-1].All()); !slices.Equal(a, models[j-1]) {
+					t.Errorf("version %d: want %v, got %v", j-1, models[j-1], a)
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+}
+
+// checkFingerTree checks the number of nodes in
+// the prefixes and suffixes, the number of
+// children and the cached sizes, and that all
+// leaves are at the same depth.
+func checkFingerTree[T any](t *testing.T, f *ftree[T]) {
+	t.Helper()
+	var node func(d *ftnode[T], h int) int
+	node = func(d *ftnode[T], h int) int {
+		if h == 0 {
+			if d.c != nil || d.n != 1 {
+				t.Fatalf("want a leaf, got %d children", len(d.c))
+			}
+			return 1
+		}
+		if len(d.c) < 2 || len(d.c) > 3 {
+			t.Fatalf("want 2 or 3 children, got %d", len(d.c))
+		}
+		n := 0
+		for _, c := range d.c {
+			n += node(c, h-1)
+		}
+		if n != d.n {
+			t.Fatalf("want size %d, got %d", n, d.n)
+		}
+		return n
+	}
+	var tree func(f *ftree[T], h int) int
+	tree = func(f *ftree[T], h int) int {
+		if f == nil {
+			return 0
+		}
+		if len(f.pr) < 1 || len(f.pr) > 4 || len(f.sf) > 4 || (len(f.sf) == 0 && (len(f.pr) != 1 || f.m != nil)) {
+			t.Fatalf("level %d: invalid digits of length %d and %d", h, len(f.pr), len(f.sf))
+		}
+		n := tree(f.m, h+1)
+		for _, d := range append(slices.Clip(f.pr), f.sf...) {
+			n += node(d, h)
+		}
+		if n != f.n {
+			t.Fatalf("level %d: want size %d, got %d", h, n, f.n)
+		}
+		return n
+	}
+	tree(f, 0)
+}
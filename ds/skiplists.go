@@ -0,0 +1,387 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"iter"
+	"math/bits"
+	"math/rand"
+)
+
+// --- SkiplistSSet -------
+
+// sknode represents a node in a skiplist.
+type sknode[T any] struct {
+	n []*sknode[T] // next pointers, one per level
+	l []int        // lengths of the edges, one per level
+	v T            // value
+}
+
+// SkiplistSSet implements a sorted set on
+// top of a skiplist. The elements are ordered
+// by a comparison function, which returns a
+// negative number if a < b, zero if a == b
+// and a positive number if a > b.
+type SkiplistSSet[T any] struct {
+	r   sknode[T]        // sentinel node, the root
+	h   int              // height of the skiplist
+	n   int              // number of elements
+	cmp func(a, b T) int // comparison function
+	rnd *rand.Rand       // source for node heights
+	s   []*sknode[T]     // search path, one node per level
+}
+
+// NewSkiplistSSet returns an empty sorted set
+// which orders its elements by the given
+// comparison function.
+func NewSkiplistSSet[T any](cmp func(a, b T) int) *SkiplistSSet[T] {
+	return &SkiplistSSet[T]{
+		r:   sknode[T]{n: make([]*sknode[T], 1)},
+		cmp: cmp,
+		rnd: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed seeds the source for the heights of the
+// nodes, which makes the shape of the skiplist
+// deterministic.
+func (s *SkiplistSSet[T]) Seed(seed int64) { s.rnd = rand.New(rand.NewSource(seed)) }
+
+// Len returns the number of elements in the set.
+func (s *SkiplistSSet[T]) Len() int { return s.n }
+
+// pred returns the node which precedes
+// the position of v on the bottom level.
+func (s *SkiplistSSet[T]) pred(v T) *sknode[T] {
+	u := &s.r
+	for r := s.h; r >= 0; r-- {
+		for u.n[r] != nil && s.cmp(u.n[r].v, v) < 0 {
+			u = u.n[r]
+		}
+	}
+	return u
+}
+
+// Find returns the smallest element
+// which is greater than or equal to
+// v and reports whether it exists.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (s *SkiplistSSet[T]) Find(v T) (T, bool) {
+	u := s.pred(v).n[0]
+	if u == nil {
+		return *new(T), false
+	}
+	return u.v, true
+}
+
+// Add adds an element to the set and
+// reports whether it was added, i.e. it
+// was not already contained in the set.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (s *SkiplistSSet[T]) Add(v T) bool {
+	for len(s.s) < len(s.r.n) {
+		s.s = append(s.s, nil)
+	}
+	u := &s.r
+	for r := s.h; r >= 0; r-- {
+		for u.n[r] != nil && s.cmp(u.n[r].v, v) < 0 {
+			u = u.n[r]
+		}
+		if u.n[r] != nil && s.cmp(u.n[r].v, v) == 0 {
+			return false
+		}
+		s.s[r] = u
+	}
+	w := &sknode[T]{v: v, n: make([]*sknode[T], height(s.rnd)+1)}
+	for s.h < len(w.n)-1 {
+		s.h++
+		if s.h == len(s.r.n) {
+			s.r.n = append(s.r.n, nil)
+			s.s = append(s.s, nil)
+		}
+		s.s[s.h] = &s.r
+	}
+	for r := range w.n {
+		w.n[r] = s.s[r].n[r]
+		s.s[r].n[r] = w
+	}
+	s.n++
+	return true
+}
+
+// Remove removes an element from the set
+// and reports whether it was removed, i.e.
+// it was contained in the set.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (s *SkiplistSSet[T]) Remove(v T) bool {
+	removed := false
+	u := &s.r
+	for r := s.h; r >= 0; r-- {
+		for u.n[r] != nil && s.cmp(u.n[r].v, v) < 0 {
+			u = u.n[r]
+		}
+		if u.n[r] != nil && s.cmp(u.n[r].v, v) == 0 {
+			removed = true
+			u.n[r] = u.n[r].n[r]
+			if u == &s.r && u.n[r] == nil && s.h > 0 {
+				s.h--
+			}
+		}
+	}
+	if removed {
+		s.n--
+	}
+	return removed
+}
+
+// Min returns the smallest element of the set.
+//
+// This operation has a time complexity of O(1).
+func (s *SkiplistSSet[T]) Min() (T, bool) {
+	if s.n == 0 {
+		return *new(T), false
+	}
+	return s.r.n[0].v, true
+}
+
+// Max returns the largest element of the set.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (s *SkiplistSSet[T]) Max() (T, bool) {
+	if s.n == 0 {
+		return *new(T), false
+	}
+	u := &s.r
+	for r := s.h; r >= 0; r-- {
+		for u.n[r] != nil {
+			u = u.n[r]
+		}
+	}
+	return u.v, true
+}
+
+// All returns an iterator over the
+// elements of the set, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (s *SkiplistSSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for u := s.r.n[0]; u != nil; u = u.n[0] {
+			if !yield(u.v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the
+// elements of the set, in descending order.
+//
+// This operation has a time and space
+// complexity of O(n).
+func (s *SkiplistSSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		a := make([]*sknode[T], 0, s.n)
+		for u := s.r.n[0]; u != nil; u = u.n[0] {
+			a = append(a, u)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			if !yield(a[i].v) {
+				return
+			}
+		}
+	}
+}
+
+// --- SkiplistList -------
+
+// SkiplistList implements a list on top of
+// a skiplist, whose edges store the number
+// of elements they skip.
+type SkiplistList[T any] struct {
+	r   sknode[T]  // sentinel node, the root
+	h   int        // height of the skiplist
+	n   int        // number of elements
+	rnd *rand.Rand // source for node heights
+}
+
+// Seed seeds the source for the heights of the
+// nodes, which makes the shape of the skiplist
+// deterministic.
+func (l *SkiplistList[T]) Seed(seed int64) { l.rnd = rand.New(rand.NewSource(seed)) }
+
+// init lazily initializes the sentinel
+// node and the source for node heights.
+func (l *SkiplistList[T]) init() {
+	if l.r.n == nil {
+		l.r.n = make([]*sknode[T], 1)
+		l.r.l = make([]int, 1)
+	}
+	if l.rnd == nil {
+		l.rnd = rand.New(rand.NewSource(rand.Int63()))
+	}
+}
+
+// Len returns the number of elements in the list.
+func (l *SkiplistList[T]) Len() int { return l.n }
+
+// pred returns the node which precedes
+// the given index on the bottom level.
+func (l *SkiplistList[T]) pred(i int) *sknode[T] {
+	u, j := &l.r, -1
+	for r := l.h; r >= 0; r-- {
+		for u.n[r] != nil && j+u.l[r] < i {
+			j += u.l[r]
+			u = u.n[r]
+		}
+	}
+	return u
+}
+
+// Get returns the element at the given index.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (l *SkiplistList[T]) Get(i int) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	return l.pred(i).n[0].v, true
+}
+
+// Set sets the element at the given index
+// and returns the old one.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (l *SkiplistList[T]) Set(i int, v T) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	u := l.pred(i).n[0]
+	t := u.v
+	u.v = v
+	return t, true
+}
+
+// Add adds an element to the list at the
+// given index and reports whether it was
+// successful or not.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (l *SkiplistList[T]) Add(i int, v T) bool {
+	if i < 0 || i > l.n {
+		return false
+	}
+	l.init()
+	k := height(l.rnd)
+	w := &sknode[T]{
+		v: v,
+		n: make([]*sknode[T], k+1),
+		l: make([]int, k+1),
+	}
+	for len(l.r.n) <= k {
+		l.r.n = append(l.r.n, nil)
+		l.r.l = append(l.r.l, 0)
+	}
+	l.h = max(l.h, k)
+	u, j := &l.r, -1
+	for r := l.h; r >= 0; r-- {
+		for u.n[r] != nil && j+u.l[r] < i {
+			j += u.l[r]
+			u = u.n[r]
+		}
+		// the edge skips the new node
+		u.l[r]++
+		if r <= k {
+			w.n[r] = u.n[r]
+			u.n[r] = w
+			w.l[r] = u.l[r] - (i - j)
+			u.l[r] = i - j
+		}
+	}
+	l.n++
+	return true
+}
+
+// Remove removes the element of the list at the
+// given index and reports whether the operation was
+// successful or not.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (l *SkiplistList[T]) Remove(i int) (T, bool) {
+	if i < 0 || i > l.n-1 {
+		return *new(T), false
+	}
+	var v T
+	u, j := &l.r, -1
+	for r := l.h; r >= 0; r-- {
+		for u.n[r] != nil && j+u.l[r] < i {
+			j += u.l[r]
+			u = u.n[r]
+		}
+		u.l[r]--
+		if w := u.n[r]; w != nil && j+u.l[r]+1 == i {
+			v = w.v
+			u.l[r] += w.l[r]
+			u.n[r] = w.n[r]
+			if u == &l.r && u.n[r] == nil && l.h > 0 {
+				l.h--
+			}
+		}
+	}
+	l.n--
+	return v, true
+}
+
+// All returns an iterator over the indices and
+// elements of the list, in order.
+//
+// This operation has a time complexity of O(n).
+func (l *SkiplistList[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		i := 0
+		for u := l.r.n[0]; u != nil; u = u.n[0] {
+			if !yield(i, u.v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Backward returns an iterator over the indices
+// and elements of the list, in reverse order.
+//
+// This operation has a time and space
+// complexity of O(n).
+func (l *SkiplistList[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		l.init()
+		a := make([]*sknode[T], 0, l.n)
+		for u := l.r.n[0]; u != nil; u = u.n[0] {
+			a = append(a, u)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			if !yield(i, a[i].v) {
+				return
+			}
+		}
+	}
+}
+
+// height returns a random height, which
+// is i with a probability of 1/2^(i+1).
+func height(r *rand.Rand) int { return bits.TrailingZeros64(^r.Uint64()) }
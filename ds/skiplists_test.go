@@ -0,0 +1,153 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSkiplistSSet(t *testing.T) {
+	const n = 2000
+	s := NewSkiplistSSet(cmp.Compare[int])
+	s.Seed(1)
+
+	if _, ok := s.Find(0); ok {
+		t.Errorf("no element in set expected")
+	}
+	if _, ok := s.Min(); ok {
+		t.Errorf("no element in set expected")
+	}
+	if _, ok := s.Max(); ok {
+		t.Errorf("no element in set expected")
+	}
+	if ok := s.Remove(0); ok {
+		t.Errorf("no element to remove expected")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var e []int
+	for k := 0; k < n; k++ {
+		v := r.Intn(n / 2)
+		i, found := slices.BinarySearch(e, v)
+		if r.Intn(3) > 0 {
+			if ok := s.Add(v); ok == found {
+				t.Fatalf("add %d: want %t, got %t", v, !found, ok)
+			}
+			if !found {
+				e = slices.Insert(e, i, v)
+			}
+		} else {
+			if ok := s.Remove(v); ok != found {
+				t.Fatalf("remove %d: want %t, got %t", v, found, ok)
+			}
+			if found {
+				e = slices.Delete(e, i, i+1)
+			}
+		}
+		if s.Len() != len(e) {
+			t.Fatalf("want %d, got %d", len(e), s.Len())
+		}
+	}
+
+	for v := -1; v <= n/2; v++ {
+		i, _ := slices.BinarySearch(e, v)
+		f, ok := s.Find(v)
+		if i == len(e) {
+			if ok {
+				t.Errorf("find %d: no element expected, got %d", v, f)
+			}
+		} else if !ok || f != e[i] {
+			t.Errorf("find %d: want %d, got %d", v, e[i], f)
+		}
+	}
+	if v, _ := s.Min(); v != e[0] {
+		t.Errorf("want %d, got %d", e[0], v)
+	}
+	if v, _ := s.Max(); v != e[len(e)-1] {
+		t.Errorf("want %d, got %d", e[len(e)-1], v)
+	}
+	if a := slices.Collect(s.All()); !slices.Equal(a, e) {
+		t.Errorf("want %v, got %v", e, a)
+	}
+	a := slices.Collect(s.Backward())
+	slices.Reverse(a)
+	if !slices.Equal(a, e) {
+		t.Errorf("want %v, got %v", e, a)
+	}
+}
+
+func TestSkiplistSeed(t *testing.T) {
+	const n = 100
+	a := NewSkiplistSSet(cmp.Compare[int])
+	b := NewSkiplistSSet(cmp.Compare[int])
+	a.Seed(42)
+	b.Seed(42)
+	for i := 0; i < n; i++ {
+		a.Add(i)
+		b.Add(i)
+	}
+	for u, w := a.r.n[0], b.r.n[0]; u != nil; u, w = u.n[0], w.n[0] {
+		if len(u.n) != len(w.n) {
+			t.Fatalf("want height %d, got %d", len(u.n), len(w.n))
+		}
+	}
+}
+
+func TestSkiplistList(t *testing.T) {
+	const n = 2000
+	var l SkiplistList[int]
+	l.Seed(1)
+
+	if _, ok := l.Get(0); ok {
+		t.Errorf("no element at index 0")
+	}
+	if _, ok := l.Set(0, 1); ok {
+		t.Errorf("no element to set at index 0")
+	}
+	if _, ok := l.Remove(0); ok {
+		t.Errorf("no element to remove at index 0")
+	}
+	if ok := l.Add(1, 1); ok {
+		t.Errorf("cannot add at index 1")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	var e []int
+	for k := 0; k < n; k++ {
+		switch i := r.Intn(len(e) + 1); {
+		case r.Intn(3) > 0 || len(e) == 0:
+			if ok := l.Add(i, k); !ok {
+				t.Fatalf("cannot add: %d", i)
+			}
+			e = slices.Insert(e, i, k)
+		case i < len(e):
+			v, ok := l.Remove(i)
+			if !ok || v != e[i] {
+				t.Fatalf("want %d, got %d", e[i], v)
+			}
+			e = slices.Delete(e, i, i+1)
+		}
+		if l.Len() != len(e) {
+			t.Fatalf("want %d, got %d", len(e), l.Len())
+		}
+	}
+	for i, x := range e {
+		if v, ok := l.Set(i, -x); !ok || v != x {
+			t.Errorf("want %d, got %d", x, v)
+		}
+		if v, ok := l.Get(i); !ok || v != -x {
+			t.Errorf("want %d, got %d", -x, v)
+		}
+	}
+	for l.Len() > 0 {
+		l.Remove(l.Len() / 2)
+	}
+	if l.h != 0 {
+		t.Errorf("want height %d, got %d", 0, l.h)
+	}
+}
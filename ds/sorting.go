@@ -0,0 +1,262 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import "math/rand"
+
+// Sortable is a sequence of elements, which can
+// be accessed by index. All lists are sortable.
+//
+// The time complexities of the sorting algorithms
+// assume that Get and Set run in O(1), as they do
+// for Array, Dequeue, DualDequeue and RootishStack.
+type Sortable[T any] interface {
+	// Len returns the number of elements.
+	Len() int
+	// Get returns the element at the given index.
+	Get(i int) (T, bool)
+	// Set sets the element at the given
+	// index and returns the old one.
+	Set(i int, v T) (T, bool)
+}
+
+// counter returns a comparison function
+// which counts its calls in c.
+func counter[T any](cmp func(a, b T) int, c *int) func(a, b T) int {
+	return func(a, b T) int {
+		*c++
+		return cmp(a, b)
+	}
+}
+
+func swap[T any](l Sortable[T], i, j int) {
+	v, _ := l.Get(i)
+	w, _ := l.Set(j, v)
+	l.Set(i, w)
+}
+
+// --- MergeSort -------
+
+// MergeSort sorts the list in ascending order and
+// returns the number of comparisons. The sort is
+// stable. The elements are ordered by a comparison
+// function, which returns a negative number if
+// a < b, zero if a == b and a positive number
+// if a > b.
+//
+// This operation has a time complexity of
+// O(n log n) and a space complexity of O(n).
+func MergeSort[T any](l Sortable[T], cmp func(a, b T) int) int {
+	c := 0
+	cmp = counter(cmp, &c)
+	s := make([]T, l.Len())
+	for i := range s {
+		s[i], _ = l.Get(i)
+	}
+	mergeSort(s, make([]T, len(s)), cmp)
+	for i, v := range s {
+		l.Set(i, v)
+	}
+	return c
+}
+
+// mergeSort sorts s, using t as buffer.
+func mergeSort[T any](s, t []T, cmp func(a, b T) int) {
+	if len(s) <= 1 {
+		return
+	}
+	m := len(s) / 2
+	mergeSort(s[:m], t[:m], cmp)
+	mergeSort(s[m:], t[m:], cmp)
+	copy(t, s)
+	i, j := 0, m
+	for k := range s {
+		if j == len(s) || (i < m && cmp(t[i], t[j]) <= 0) {
+			s[k] = t[i]
+			i++
+		} else {
+			s[k] = t[j]
+			j++
+		}
+	}
+}
+
+// --- QuickSort -------
+
+// QuickSort sorts the list in ascending order
+// and returns the number of comparisons. The
+// sort is not stable. The elements are ordered
+// by a comparison function, which returns a
+// negative number if a < b, zero if a == b
+// and a positive number if a > b. The pivots
+// are chosen by r, or by a randomly seeded
+// source if r is nil.
+//
+// This operation has an expected time
+// complexity of O(n log n).
+func QuickSort[T any](l Sortable[T], cmp func(a, b T) int, r *rand.Rand) int {
+	if r == nil {
+		r = rand.New(rand.NewSource(rand.Int63()))
+	}
+	c := 0
+	quickSort(l, 0, l.Len(), counter(cmp, &c), r)
+	return c
+}
+
+// quickSort sorts the n elements
+// starting at the index i.
+func quickSort[T any](l Sortable[T], i, n int, cmp func(a, b T) int, r *rand.Rand) {
+	if n <= 1 {
+		return
+	}
+	x, _ := l.Get(i + r.Intn(n))
+	// l[i..p] < x, l[p+1..j-1] == x, l[q..i+n-1] > x
+	p, j, q := i-1, i, i+n
+	for j < q {
+		v, _ := l.Get(j)
+		switch c := cmp(v, x); {
+		case c < 0:
+			p++
+			swap(l, j, p)
+			j++
+		case c > 0:
+			q--
+			swap(l, j, q)
+		default:
+			j++
+		}
+	}
+	quickSort(l, i, p-i+1, cmp, r)
+	quickSort(l, q, n-(q-i), cmp, r)
+}
+
+// --- HeapSort -------
+
+// HeapSort sorts the list in ascending order
+// and returns the number of comparisons. The
+// sort is not stable. The elements are ordered
+// by a comparison function, which returns a
+// negative number if a < b, zero if a == b
+// and a positive number if a > b.
+//
+// This operation has a time complexity
+// of O(n log n).
+func HeapSort[T any](l Sortable[T], cmp func(a, b T) int) int {
+	c := 0
+	cmp = counter(cmp, &c)
+	n := l.Len()
+	// build a max-heap and move its root to the back
+	for i := n/2 - 1; i >= 0; i-- {
+		siftDown(l, i, n, cmp)
+	}
+	for m := n - 1; m > 0; m-- {
+		swap(l, 0, m)
+		siftDown(l, 0, m, cmp)
+	}
+	return c
+}
+
+// siftDown moves the element at index i down
+// the max-heap formed by the first n elements
+// until it is greater than or equal to its
+// children.
+func siftDown[T any](l Sortable[T], i, n int, cmp func(a, b T) int) {
+	v, _ := l.Get(i)
+	for {
+		j, w := i, v
+		if k := 2*i + 1; k < n {
+			if u, _ := l.Get(k); cmp(u, w) > 0 {
+				j, w = k, u
+			}
+		}
+		if k := 2*i + 2; k < n {
+			if u, _ := l.Get(k); cmp(u, w) > 0 {
+				j, w = k, u
+			}
+		}
+		if j == i {
+			return
+		}
+		l.Set(i, w)
+		l.Set(j, v)
+		i = j
+	}
+}
+
+// --- CountingSort -------
+
+// CountingSort sorts the list in ascending order
+// of the keys of its elements, which must be in
+// [0, k), and reports whether it was successful,
+// i.e. all keys were in range. The sort is stable
+// and does not compare elements.
+//
+// This operation has a time and space
+// complexity of O(n + k).
+func CountingSort[T any](l Sortable[T], k int, key func(T) int) bool {
+	n := l.Len()
+	s := make([]T, n)
+	c := make([]int, max(k, 0))
+	for i := range s {
+		s[i], _ = l.Get(i)
+		j := key(s[i])
+		if j < 0 || j >= k {
+			return false
+		}
+		c[j]++
+	}
+	// c[j] is the index after the elements with key j
+	for j := 1; j < k; j++ {
+		c[j] += c[j-1]
+	}
+	for i := n - 1; i >= 0; i-- {
+		j := key(s[i])
+		c[j]--
+		l.Set(c[j], s[i])
+	}
+	return true
+}
+
+// --- RadixSort -------
+
+// RadixSort sorts the list in ascending order of
+// the keys of its elements by counting sorts on
+// the bytes of the keys. The sort is stable and
+// does not compare elements.
+//
+// This operation has a time complexity of O(n)
+// and a space complexity of O(n).
+func RadixSort[T any](l Sortable[T], key func(T) uint64) {
+	n := l.Len()
+	if n <= 1 {
+		return
+	}
+	s, t := make([]T, n), make([]T, n)
+	for i := range s {
+		s[i], _ = l.Get(i)
+	}
+	for d := 0; d < 64; d += 8 {
+		var c [256]int
+		for _, v := range s {
+			c[byte(key(v)>>d)]++
+		}
+		if c[byte(key(s[0])>>d)] == n {
+			// all elements have the same digit
+			continue
+		}
+		for j := 1; j < len(c); j++ {
+			c[j] += c[j-1]
+		}
+		for i := n - 1; i >= 0; i-- {
+			j := byte(key(s[i]) >> d)
+			c[j]--
+			t[c[j]] = s[i]
+		}
+		s, t = t, s
+	}
+	for i, v := range s {
+		l.Set(i, v)
+	}
+}
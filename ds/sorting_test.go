@@ -0,0 +1,170 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// item is an element with a key, whose
+// position reveals whether a sort is stable.
+type item struct {
+	k, pos int
+}
+
+func compareItems(a, b item) int { return cmp.Compare(a.k, b.k) }
+
+func TestSort(t *testing.T) {
+	sorts := map[string]struct {
+		sort   func(l List[item]) int
+		stable bool
+	}{
+		"MergeSort": {func(l List[item]) int { return MergeSort(l, compareItems) }, true},
+		"QuickSort": {func(l List[item]) int { return QuickSort(l, compareItems, rand.New(rand.NewSource(1))) }, false},
+		"HeapSort":  {func(l List[item]) int { return HeapSort(l, compareItems) }, false},
+		"CountingSort": {func(l List[item]) int {
+			if !CountingSort(l, 100, func(v item) int { return v.k }) {
+				t.Fatalf("CountingSort: keys out of range")
+			}
+			return 0
+		}, true},
+		"RadixSort": {func(l List[item]) int {
+			RadixSort(l, func(v item) uint64 { return uint64(v.k) })
+			return 0
+		}, true},
+	}
+	lists := map[string]func() List[item]{
+		"Array":        func() List[item] { return new(Array[item]) },
+		"Dequeue":      func() List[item] { return new(Dequeue[item]) },
+		"DualDequeue":  func() List[item] { return new(DualDequeue[item]) },
+		"RootishStack": func() List[item] { return new(RootishStack[item]) },
+		"DList":        func() List[item] { return new(DList[item]) },
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 7, 100, 1000} {
+		e := make([]item, n)
+		for i := range e {
+			e[i] = item{k: r.Intn(100), pos: i}
+		}
+		want := slices.Clone(e)
+		slices.SortStableFunc(want, compareItems)
+		for sname, s := range sorts {
+			for lname, newList := range lists {
+				l := newList()
+				for i, v := range e {
+					l.Add(i, v)
+				}
+				c := s.sort(l)
+				if l.Len() != n {
+					t.Fatalf("%s on %s: want %d, got %d", sname, lname, n, l.Len())
+				}
+				for i := range want {
+					v, _ := l.Get(i)
+					if v.k != want[i].k || (s.stable && v != want[i]) {
+						t.Fatalf("%s on %s: index %d: want %v, got %v", sname, lname, i, want[i], v)
+					}
+				}
+				if m := 3 * float64(n) * math.Log2(float64(n)+1); float64(c) > m {
+					t.Errorf("%s on %s: want at most %.0f comparisons, got %d", sname, lname, m, c)
+				}
+			}
+		}
+	}
+}
+
+func TestMergeSortComparisons(t *testing.T) {
+	const n = 1024
+	var a Array[int]
+	for i := 0; i < n; i++ {
+		a.Add(i, n-i)
+	}
+	// merge sort needs at most n log n - n + 1 comparisons
+	if c := MergeSort[int](&a, cmp.Compare[int]); c > n*10-n+1 {
+		t.Errorf("want at most %d comparisons, got %d", n*10-n+1, c)
+	}
+	for i := 0; i < n; i++ {
+		if v, _ := a.Get(i); v != i+1 {
+			t.Fatalf("want %d, got %d", i+1, v)
+		}
+	}
+}
+
+// ints is a slice, which is sortable but not a list.
+type ints []int
+
+func (s ints) Len() int              { return len(s) }
+func (s ints) Get(i int) (int, bool) { return s[i], true }
+
+func (s ints) Set(i, v int) (int, bool) {
+	w := s[i]
+	s[i] = v
+	return w, true
+}
+
+func TestQuickSortSeed(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	e := make([]int, 1000)
+	for i := range e {
+		e[i] = r.Intn(100)
+	}
+	// the same seed chooses the same pivots
+	a, b, c := ints(slices.Clone(e)), ints(slices.Clone(e)), ints(slices.Clone(e))
+	ca := QuickSort[int](a, cmp.Compare[int], rand.New(rand.NewSource(2)))
+	cb := QuickSort[int](b, cmp.Compare[int], rand.New(rand.NewSource(2)))
+	if ca != cb {
+		t.Errorf("want %d, got %d", ca, cb)
+	}
+	QuickSort[int](c, cmp.Compare[int], nil)
+	slices.Sort(e)
+	for _, s := range []ints{a, b, c} {
+		if !slices.Equal(s, e) {
+			t.Fatalf("want %v, got %v", e, s)
+		}
+	}
+}
+
+func TestCountingSortRange(t *testing.T) {
+	var a Array[int]
+	for i, v := range []int{3, 1, 5, 2} {
+		a.Add(i, v)
+	}
+	if CountingSort[int](&a, 5, func(v int) int { return v }) {
+		t.Errorf("want false for keys out of range, got true")
+	}
+	for i, v := range []int{3, 1, 5, 2} {
+		if w, _ := a.Get(i); w != v {
+			t.Errorf("index %d: want %d, got %d", i, v, w)
+		}
+	}
+	if !CountingSort[int](&a, 6, func(v int) int { return v }) {
+		t.Errorf("want true, got false")
+	}
+	for i, v := range []int{1, 2, 3, 5} {
+		if w, _ := a.Get(i); w != v {
+			t.Errorf("index %d: want %d, got %d", i, v, w)
+		}
+	}
+}
+
+func TestRadixSortLargeKeys(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var a Array[uint64]
+	e := make([]uint64, 1000)
+	for i := range e {
+		e[i] = r.Uint64()
+		a.Add(i, e[i])
+	}
+	RadixSort[uint64](&a, func(v uint64) uint64 { return v })
+	slices.Sort(e)
+	for i, v := range e {
+		if w, _ := a.Get(i); w != v {
+			t.Fatalf("index %d: want %d, got %d", i, v, w)
+		}
+	}
+}
@@ -0,0 +1,826 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"iter"
+	"math"
+	"math/rand"
+)
+
+// --- Binary search trees -------
+
+// tnode represents a node in
+// a binary search tree.
+type tnode[T any] struct {
+	l, r, p *tnode[T] // left, right child and parent
+	v       T         // value
+	c       int       // priority in treaps, colour in red-black trees
+}
+
+// bst implements the operations
+// shared by binary search trees.
+type bst[T any] struct {
+	r   *tnode[T]        // root
+	z   *tnode[T]        // sentinel for missing nodes, nil except in red-black trees
+	n   int              // number of elements
+	cmp func(a, b T) int // comparison function
+}
+
+// Len returns the number of elements in the tree.
+func (b *bst[T]) Len() int { return b.n }
+
+// last returns the node of v, or the last
+// node on the search path for v, which is
+// z if the tree is empty.
+func (b *bst[T]) last(v T) *tnode[T] {
+	w, p := b.r, b.z
+	for w != b.z {
+		p = w
+		switch c := b.cmp(v, w.v); {
+		case c < 0:
+			w = w.l
+		case c > 0:
+			w = w.r
+		default:
+			return w
+		}
+	}
+	return p
+}
+
+// Find returns the smallest element
+// which is greater than or equal to
+// v and reports whether it exists.
+//
+// This operation has a time complexity
+// of O(h), where h is the height of the
+// tree.
+func (b *bst[T]) Find(v T) (T, bool) {
+	w, z := b.r, b.z
+	for w != b.z {
+		switch c := b.cmp(v, w.v); {
+		case c < 0:
+			z = w
+			w = w.l
+		case c > 0:
+			w = w.r
+		default:
+			return w.v, true
+		}
+	}
+	if z == b.z {
+		return *new(T), false
+	}
+	return z.v, true
+}
+
+// Min returns the smallest element of the tree.
+//
+// This operation has a time complexity
+// of O(h), where h is the height of the
+// tree.
+func (b *bst[T]) Min() (T, bool) {
+	if b.n == 0 {
+		return *new(T), false
+	}
+	return b.leftmost(b.r).v, true
+}
+
+// Max returns the largest element of the tree.
+//
+// This operation has a time complexity
+// of O(h), where h is the height of the
+// tree.
+func (b *bst[T]) Max() (T, bool) {
+	if b.n == 0 {
+		return *new(T), false
+	}
+	return b.rightmost(b.r).v, true
+}
+
+// All returns an iterator over the
+// elements of the tree, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (b *bst[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if b.n == 0 {
+			return
+		}
+		for u := b.leftmost(b.r); u != b.z; u = b.next(u) {
+			if !yield(u.v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the
+// elements of the tree, in descending order.
+//
+// This operation has a time complexity of O(n).
+func (b *bst[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if b.n == 0 {
+			return
+		}
+		for u := b.rightmost(b.r); u != b.z; u = b.prev(u) {
+			if !yield(u.v) {
+				return
+			}
+		}
+	}
+}
+
+func (b *bst[T]) leftmost(u *tnode[T]) *tnode[T] {
+	for u.l != b.z {
+		u = u.l
+	}
+	return u
+}
+
+func (b *bst[T]) rightmost(u *tnode[T]) *tnode[T] {
+	for u.r != b.z {
+		u = u.r
+	}
+	return u
+}
+
+// next returns the in-order successor of u.
+func (b *bst[T]) next(u *tnode[T]) *tnode[T] {
+	if u.r != b.z {
+		return b.leftmost(u.r)
+	}
+	for u.p != b.z && u == u.p.r {
+		u = u.p
+	}
+	return u.p
+}
+
+// prev returns the in-order predecessor of u.
+func (b *bst[T]) prev(u *tnode[T]) *tnode[T] {
+	if u.l != b.z {
+		return b.rightmost(u.l)
+	}
+	for u.p != b.z && u == u.p.l {
+		u = u.p
+	}
+	return u.p
+}
+
+// size returns the number of nodes
+// in the subtree rooted at u.
+func (b *bst[T]) size(u *tnode[T]) int {
+	if u == b.z {
+		return 0
+	}
+	return 1 + b.size(u.l) + b.size(u.r)
+}
+
+// newNode returns a new leaf with the given value.
+func (b *bst[T]) newNode(v T) *tnode[T] {
+	return &tnode[T]{v: v, l: b.z, r: b.z, p: b.z}
+}
+
+// add adds the node u as a leaf and reports
+// whether it was added, i.e. its value was
+// not already contained in the tree.
+func (b *bst[T]) add(u *tnode[T]) bool {
+	p := b.last(u.v)
+	if p == b.z {
+		b.r = u
+	} else {
+		switch c := b.cmp(u.v, p.v); {
+		case c < 0:
+			p.l = u
+		case c > 0:
+			p.r = u
+		default:
+			return false
+		}
+	}
+	u.p = p
+	b.n++
+	return true
+}
+
+// splice removes the node u,
+// which has at most one child.
+func (b *bst[T]) splice(u *tnode[T]) {
+	var s, p *tnode[T]
+	if u.l != b.z {
+		s = u.l
+	} else {
+		s = u.r
+	}
+	if u == b.r {
+		b.r = s
+		p = b.z
+	} else {
+		p = u.p
+		if p.l == u {
+			p.l = s
+		} else {
+			p.r = s
+		}
+	}
+	if s != b.z {
+		s.p = p
+	}
+	b.n--
+}
+
+// remove removes the node u. If u has two
+// children, its value is replaced by the
+// value of its successor, which is removed
+// instead.
+func (b *bst[T]) remove(u *tnode[T]) {
+	if u.l == b.z || u.r == b.z {
+		b.splice(u)
+		return
+	}
+	w := b.leftmost(u.r)
+	u.v = w.v
+	b.splice(w)
+}
+
+func (b *bst[T]) rotateLeft(u *tnode[T]) {
+	w := u.r
+	w.p = u.p
+	if w.p != b.z {
+		if w.p.l == u {
+			w.p.l = w
+		} else {
+			w.p.r = w
+		}
+	}
+	u.r = w.l
+	if u.r != b.z {
+		u.r.p = u
+	}
+	u.p = w
+	w.l = u
+	if u == b.r {
+		b.r = w
+		b.r.p = b.z
+	}
+}
+
+func (b *bst[T]) rotateRight(u *tnode[T]) {
+	w := u.l
+	w.p = u.p
+	if w.p != b.z {
+		if w.p.l == u {
+			w.p.l = w
+		} else {
+			w.p.r = w
+		}
+	}
+	u.l = w.r
+	if u.l != b.z {
+		u.l.p = u
+	}
+	u.p = w
+	w.r = u
+	if u == b.r {
+		b.r = w
+		b.r.p = b.z
+	}
+}
+
+// --- BinarySearchTree -------
+
+// BinarySearchTree implements a sorted
+// set on top of an unbalanced binary
+// search tree. The elements are ordered
+// by a comparison function, which returns
+// a negative number if a < b, zero if
+// a == b and a positive number if a > b.
+type BinarySearchTree[T any] struct {
+	bst[T]
+}
+
+// NewBinarySearchTree returns an empty tree
+// which orders its elements by the given
+// comparison function.
+func NewBinarySearchTree[T any](cmp func(a, b T) int) *BinarySearchTree[T] {
+	return &BinarySearchTree[T]{bst[T]{cmp: cmp}}
+}
+
+// Add adds an element to the tree and
+// reports whether it was added, i.e. it
+// was not already contained in the tree.
+//
+// This operation has a time complexity
+// of O(h), where h is the height of the
+// tree.
+func (t *BinarySearchTree[T]) Add(v T) bool { return t.add(t.newNode(v)) }
+
+// Remove removes an element from the tree
+// and reports whether it was removed, i.e.
+// it was contained in the tree.
+//
+// This operation has a time complexity
+// of O(h), where h is the height of the
+// tree.
+func (t *BinarySearchTree[T]) Remove(v T) bool {
+	u := t.last(v)
+	if u == t.z || t.cmp(v, u.v) != 0 {
+		return false
+	}
+	t.remove(u)
+	return true
+}
+
+// --- Treap -------
+
+// Treap implements a sorted set on top
+// of a binary search tree, whose nodes
+// have random priorities and form a heap
+// with respect to them.
+type Treap[T any] struct {
+	bst[T]
+	rnd *rand.Rand // source for priorities
+}
+
+// NewTreap returns an empty treap
+// which orders its elements by the
+// given comparison function.
+func NewTreap[T any](cmp func(a, b T) int) *Treap[T] {
+	return &Treap[T]{
+		bst: bst[T]{cmp: cmp},
+		rnd: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Seed seeds the source for the priorities
+// of the nodes, which makes the shape of
+// the treap deterministic.
+func (t *Treap[T]) Seed(seed int64) { t.rnd = rand.New(rand.NewSource(seed)) }
+
+// Add adds an element to the treap and
+// reports whether it was added, i.e. it
+// was not already contained in the treap.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (t *Treap[T]) Add(v T) bool {
+	u := t.newNode(v)
+	u.c = t.rnd.Int()
+	if !t.add(u) {
+		return false
+	}
+	t.bubbleUp(u)
+	return true
+}
+
+// Remove removes an element from the treap
+// and reports whether it was removed, i.e.
+// it was contained in the treap.
+//
+// This operation has an expected time
+// complexity of O(log n).
+func (t *Treap[T]) Remove(v T) bool {
+	u := t.last(v)
+	if u == t.z || t.cmp(v, u.v) != 0 {
+		return false
+	}
+	t.trickleDown(u)
+	t.splice(u)
+	return true
+}
+
+// bubbleUp rotates u up until its
+// priority is not smaller than the
+// priority of its parent.
+func (t *Treap[T]) bubbleUp(u *tnode[T]) {
+	for u.p != t.z && u.p.c > u.c {
+		if u.p.r == u {
+			t.rotateLeft(u.p)
+		} else {
+			t.rotateRight(u.p)
+		}
+	}
+}
+
+// trickleDown rotates u down until it is a leaf.
+func (t *Treap[T]) trickleDown(u *tnode[T]) {
+	for u.l != t.z || u.r != t.z {
+		switch {
+		case u.l == t.z:
+			t.rotateLeft(u)
+		case u.r == t.z:
+			t.rotateRight(u)
+		case u.l.c < u.r.c:
+			t.rotateRight(u)
+		default:
+			t.rotateLeft(u)
+		}
+	}
+}
+
+// split removes all elements which are
+// smaller than or equal to v and returns
+// them as a new treap.
+//
+// This operation has an expected time
+// complexity of O(n).
+func (t *Treap[T]) split(v T) *Treap[T] {
+	// add a node after v with the smallest priority,
+	// which becomes the root and separates the elements
+	s := t.newNode(*new(T))
+	s.c = math.MinInt
+	if t.r == t.z {
+		t.r = s
+	} else {
+		w, p := t.r, t.z
+		for w != t.z {
+			p = w
+			if t.cmp(v, p.v) < 0 {
+				w = w.l
+			} else {
+				w = w.r
+			}
+		}
+		if t.cmp(v, p.v) < 0 {
+			p.l = s
+		} else {
+			p.r = s
+		}
+		s.p = p
+		t.bubbleUp(s)
+	}
+	o := &Treap[T]{bst: bst[T]{r: s.l, cmp: t.cmp}, rnd: t.rnd}
+	t.r = s.r
+	if o.r != t.z {
+		o.r.p = t.z
+	}
+	if t.r != t.z {
+		t.r.p = t.z
+	}
+	o.n = o.size(o.r)
+	t.n -= o.n
+	return o
+}
+
+// absorb moves all elements of o, which must
+// be smaller than the elements of the treap,
+// into the treap, which leaves o empty.
+//
+// This operation has an expected time
+// complexity of O(log n + log m), where
+// m is the number of elements in o.
+func (t *Treap[T]) absorb(o *Treap[T]) {
+	s := t.newNode(*new(T))
+	s.l, s.r = o.r, t.r
+	if s.l != t.z {
+		s.l.p = s
+	}
+	if s.r != t.z {
+		s.r.p = s
+	}
+	t.r = s
+	t.n += o.n + 1
+	o.r, o.n = t.z, 0
+	t.trickleDown(s)
+	t.splice(s)
+}
+
+// --- ScapegoatTree -------
+
+// ScapegoatTree implements a sorted set on
+// top of a binary search tree, which is
+// partially rebuilt whenever a node is
+// too deep.
+type ScapegoatTree[T any] struct {
+	bst[T]
+	q int // upper bound on the number of elements
+}
+
+// NewScapegoatTree returns an empty tree
+// which orders its elements by the given
+// comparison function.
+func NewScapegoatTree[T any](cmp func(a, b T) int) *ScapegoatTree[T] {
+	return &ScapegoatTree[T]{bst: bst[T]{cmp: cmp}}
+}
+
+// Add adds an element to the tree and
+// reports whether it was added, i.e. it
+// was not already contained in the tree.
+//
+// This operation has an amortized time
+// complexity of O(log n).
+func (t *ScapegoatTree[T]) Add(v T) bool {
+	u := t.newNode(v)
+	d := t.addWithDepth(u)
+	if d < 0 {
+		return false
+	}
+	if d > log32(t.q) {
+		// find the scapegoat
+		w := u.p
+		for 3*t.size(w) <= 2*t.size(w.p) {
+			w = w.p
+		}
+		t.rebuild(w.p)
+	}
+	return true
+}
+
+// addWithDepth adds the node u as a leaf
+// and returns its depth, or -1 if its value
+// was already contained in the tree.
+func (t *ScapegoatTree[T]) addWithDepth(u *tnode[T]) int {
+	w := t.r
+	if w == nil {
+		t.r = u
+		t.n++
+		t.q++
+		return 0
+	}
+	d := 0
+	for {
+		switch c := t.cmp(u.v, w.v); {
+		case c < 0:
+			if w.l == nil {
+				w.l = u
+				u.p = w
+				t.n++
+				t.q++
+				return d + 1
+			}
+			w = w.l
+		case c > 0:
+			if w.r == nil {
+				w.r = u
+				u.p = w
+				t.n++
+				t.q++
+				return d + 1
+			}
+			w = w.r
+		default:
+			return -1
+		}
+		d++
+	}
+}
+
+// Remove removes an element from the tree
+// and reports whether it was removed, i.e.
+// it was contained in the tree.
+//
+// This operation has an amortized time
+// complexity of O(log n).
+func (t *ScapegoatTree[T]) Remove(v T) bool {
+	u := t.last(v)
+	if u == nil || t.cmp(v, u.v) != 0 {
+		return false
+	}
+	t.remove(u)
+	if 2*t.n < t.q {
+		if t.r != nil {
+			t.rebuild(t.r)
+		}
+		t.q = t.n
+	}
+	return true
+}
+
+// rebuild turns the subtree rooted
+// at u into a perfectly balanced one.
+func (t *ScapegoatTree[T]) rebuild(u *tnode[T]) {
+	a := make([]*tnode[T], 0, t.size(u))
+	a = t.pack(u, a)
+	p := u.p
+	w := t.build(a)
+	w.p = p
+	switch {
+	case p == nil:
+		t.r = w
+	case p.r == u:
+		p.r = w
+	default:
+		p.l = w
+	}
+}
+
+// pack appends the nodes of the subtree
+// rooted at u to a, in ascending order.
+func (t *ScapegoatTree[T]) pack(u *tnode[T], a []*tnode[T]) []*tnode[T] {
+	if u == nil {
+		return a
+	}
+	a = t.pack(u.l, a)
+	a = append(a, u)
+	return t.pack(u.r, a)
+}
+
+// build builds a perfectly balanced tree
+// from the nodes in a and returns its root.
+func (t *ScapegoatTree[T]) build(a []*tnode[T]) *tnode[T] {
+	if len(a) == 0 {
+		return nil
+	}
+	m := len(a) / 2
+	u := a[m]
+	u.l = t.build(a[:m])
+	if u.l != nil {
+		u.l.p = u
+	}
+	u.r = t.build(a[m+1:])
+	if u.r != nil {
+		u.r.p = u
+	}
+	return u
+}
+
+// log32 returns the logarithm of q to base 3/2.
+func log32(q int) int {
+	return int(math.Log(float64(q)) / math.Log(1.5))
+}
+
+// --- RedBlackTree -------
+
+// Colours of the nodes in a red-black tree.
+const (
+	red   = 0
+	black = 1
+)
+
+// RedBlackTree implements a sorted set on
+// top of a left-leaning red-black tree.
+type RedBlackTree[T any] struct {
+	bst[T]
+}
+
+// NewRedBlackTree returns an empty tree
+// which orders its elements by the given
+// comparison function.
+func NewRedBlackTree[T any](cmp func(a, b T) int) *RedBlackTree[T] {
+	z := &tnode[T]{c: black}
+	return &RedBlackTree[T]{bst[T]{r: z, z: z, cmp: cmp}}
+}
+
+// Add adds an element to the tree and
+// reports whether it was added, i.e. it
+// was not already contained in the tree.
+//
+// This operation has a time complexity
+// of O(log n).
+func (t *RedBlackTree[T]) Add(v T) bool {
+	u := t.newNode(v)
+	u.c = red
+	if !t.add(u) {
+		return false
+	}
+	t.addFixup(u)
+	return true
+}
+
+func (t *RedBlackTree[T]) addFixup(u *tnode[T]) {
+	for u.c == red {
+		if u == t.r {
+			u.c = black
+			return
+		}
+		w := u.p
+		if w.l.c == black {
+			// ensure left-leaning
+			t.flipLeft(w)
+			u = w
+			w = u.p
+		}
+		if w.c == black {
+			// no red-red edge
+			return
+		}
+		g := w.p
+		if g.r.c == black {
+			t.flipRight(g)
+			return
+		}
+		t.pushBlack(g)
+		u = g
+	}
+}
+
+// Remove removes an element from the tree
+// and reports whether it was removed, i.e.
+// it was contained in the tree.
+//
+// This operation has a time complexity
+// of O(log n).
+func (t *RedBlackTree[T]) Remove(v T) bool {
+	u := t.last(v)
+	if u == t.z || t.cmp(v, u.v) != 0 {
+		return false
+	}
+	w := u.r
+	if w == t.z {
+		w = u
+		u = w.l
+	} else {
+		w = t.leftmost(w)
+		u.v = w.v
+		u = w.r
+	}
+	t.splice(w)
+	u.c += w.c
+	u.p = w.p
+	t.removeFixup(u)
+	return true
+}
+
+func (t *RedBlackTree[T]) removeFixup(u *tnode[T]) {
+	for u.c > black {
+		switch {
+		case u == t.r:
+			u.c = black
+		case u.p.l.c == red:
+			t.flipRight(u.p)
+		case u == u.p.l:
+			u = t.removeFixupLeft(u)
+		default:
+			u = t.removeFixupRight(u)
+		}
+	}
+	if u != t.r {
+		// restore left-leaning property
+		if w := u.p; w.r.c == red && w.l.c == black {
+			t.flipLeft(w)
+		}
+	}
+	// the sentinel may have served as u
+	t.z.c, t.z.p = black, nil
+}
+
+// removeFixupLeft removes the double black
+// node u, which is the left child of its
+// parent.
+func (t *RedBlackTree[T]) removeFixupLeft(u *tnode[T]) *tnode[T] {
+	w := u.p
+	v := w.r
+	t.pullBlack(w)
+	t.flipLeft(w)
+	q := w.r
+	if q.c == red {
+		t.rotateLeft(w)
+		t.flipRight(v)
+		t.pushBlack(q)
+		if v.r.c == red {
+			t.flipLeft(v)
+		}
+		return q
+	}
+	return v
+}
+
+// removeFixupRight removes the double black
+// node u, which is the right child of its
+// parent.
+func (t *RedBlackTree[T]) removeFixupRight(u *tnode[T]) *tnode[T] {
+	w := u.p
+	v := w.l
+	t.pullBlack(w)
+	t.flipRight(w)
+	q := w.l
+	if q.c == red {
+		t.rotateRight(w)
+		t.flipLeft(v)
+		t.pushBlack(q)
+		return q
+	}
+	if v.l.c == red {
+		t.pushBlack(v)
+		return v
+	}
+	// ensure left-leaning
+	t.flipLeft(v)
+	return w
+}
+
+func (t *RedBlackTree[T]) pushBlack(u *tnode[T]) {
+	u.c--
+	u.l.c++
+	u.r.c++
+}
+
+func (t *RedBlackTree[T]) pullBlack(u *tnode[T]) {
+	u.c++
+	u.l.c--
+	u.r.c--
+}
+
+func (t *RedBlackTree[T]) flipLeft(u *tnode[T]) {
+	u.c, u.r.c = u.r.c, u.c
+	t.rotateLeft(u)
+}
+
+func (t *RedBlackTree[T]) flipRight(u *tnode[T]) {
+	u.c, u.l.c = u.l.c, u.c
+	t.rotateRight(u)
+}
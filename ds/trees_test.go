@@ -0,0 +1,257 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSSet(t *testing.T) {
+	const n = 3000
+	bst := NewBinarySearchTree(cmp.Compare[int])
+	treap := NewTreap(cmp.Compare[int])
+	treap.Seed(1)
+	sg := NewScapegoatTree(cmp.Compare[int])
+	rb := NewRedBlackTree(cmp.Compare[int])
+	sl := NewSkiplistSSet(cmp.Compare[int])
+	sl.Seed(1)
+
+	sets := map[string]struct {
+		s     SSet[int]
+		check func(t *testing.T)
+	}{
+		"BinarySearchTree": {bst, func(t *testing.T) { checkBST(t, &bst.bst) }},
+		"Treap":            {treap, func(t *testing.T) { checkTreap(t, treap) }},
+		"ScapegoatTree":    {sg, func(t *testing.T) { checkScapegoatTree(t, sg) }},
+		"RedBlackTree":     {rb, func(t *testing.T) { checkRedBlackTree(t, rb) }},
+		"SkiplistSSet":     {sl, func(t *testing.T) {}},
+	}
+	for name, x := range sets {
+		s := x.s
+		if _, ok := s.Find(0); ok {
+			t.Errorf("%s: no element in set expected", name)
+		}
+		if _, ok := s.Min(); ok {
+			t.Errorf("%s: no element in set expected", name)
+		}
+		if _, ok := s.Max(); ok {
+			t.Errorf("%s: no element in set expected", name)
+		}
+		if ok := s.Remove(0); ok {
+			t.Errorf("%s: no element to remove expected", name)
+		}
+
+		r := rand.New(rand.NewSource(1))
+		var e []int
+		for k := 0; k < n; k++ {
+			v := r.Intn(n / 2)
+			i, found := slices.BinarySearch(e, v)
+			// grow first, then shrink to trigger rebuilds
+			if (k < n/2 && r.Intn(4) > 0) || (k >= n/2 && r.Intn(4) == 0) {
+				if ok := s.Add(v); ok == found {
+					t.Fatalf("%s: add %d: want %t, got %t", name, v, !found, ok)
+				}
+				if !found {
+					e = slices.Insert(e, i, v)
+				}
+			} else {
+				if ok := s.Remove(v); ok != found {
+					t.Fatalf("%s: remove %d: want %t, got %t", name, v, found, ok)
+				}
+				if found {
+					e = slices.Delete(e, i, i+1)
+				}
+			}
+			if s.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), s.Len())
+			}
+			x.check(t)
+		}
+
+		for v := -1; v <= n/2; v++ {
+			i, _ := slices.BinarySearch(e, v)
+			f, ok := s.Find(v)
+			if i == len(e) {
+				if ok {
+					t.Errorf("%s: find %d: no element expected, got %d", name, v, f)
+				}
+			} else if !ok || f != e[i] {
+				t.Errorf("%s: find %d: want %d, got %d", name, v, e[i], f)
+			}
+		}
+		if v, _ := s.Min(); v != e[0] {
+			t.Errorf("%s: want %d, got %d", name, e[0], v)
+		}
+		if v, _ := s.Max(); v != e[len(e)-1] {
+			t.Errorf("%s: want %d, got %d", name, e[len(e)-1], v)
+		}
+		if a := slices.Collect(s.All()); !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		a := slices.Collect(s.Backward())
+		slices.Reverse(a)
+		if !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		for _, v := range e {
+			s.Remove(v)
+			x.check(t)
+		}
+		if s.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, s.Len())
+		}
+	}
+}
+
+func TestBinarySearchTreeSorted(t *testing.T) {
+	const n = 100
+	b := NewBinarySearchTree(cmp.Compare[int])
+	for i := 0; i < n; i++ {
+		b.Add(i)
+	}
+	if h := treeHeight(&b.bst, b.r); h != n-1 {
+		t.Errorf("want height %d, got %d", n-1, h)
+	}
+	checkBST(t, &b.bst)
+}
+
+func TestTreapSplitAbsorb(t *testing.T) {
+	const n = 200
+	tr := NewTreap(cmp.Compare[int])
+	tr.Seed(1)
+	for i := 0; i < n; i += 2 {
+		tr.Add(i)
+	}
+	for _, v := range []int{-1, 0, 51, 100, n} {
+		o := tr.split(v)
+		checkTreap(t, tr)
+		checkTreap(t, o)
+		if m, ok := o.Max(); ok && m > v {
+			t.Errorf("split %d: want elements <= %d, got %d", v, v, m)
+		}
+		if m, ok := tr.Min(); ok && m <= v {
+			t.Errorf("split %d: want elements > %d, got %d", v, v, m)
+		}
+		if o.Len()+tr.Len() != n/2 {
+			t.Errorf("split %d: want %d, got %d", v, n/2, o.Len()+tr.Len())
+		}
+		tr.absorb(o)
+		checkTreap(t, tr)
+		if o.Len() != 0 {
+			t.Errorf("want %d, got %d", 0, o.Len())
+		}
+		if tr.Len() != n/2 {
+			t.Errorf("want %d, got %d", n/2, tr.Len())
+		}
+	}
+}
+
+// checkBST checks the order of the
+// elements, the parent pointers and
+// the number of elements of a tree.
+func checkBST(t *testing.T, b *bst[int]) {
+	t.Helper()
+	if b.r != b.z && b.r.p != b.z {
+		t.Fatalf("root has a parent")
+	}
+	var walk func(u *tnode[int], lo, hi *int) int
+	walk = func(u *tnode[int], lo, hi *int) int {
+		if u == b.z {
+			return 0
+		}
+		if (lo != nil && u.v <= *lo) || (hi != nil && u.v >= *hi) {
+			t.Fatalf("%d out of order", u.v)
+		}
+		if (u.l != b.z && u.l.p != u) || (u.r != b.z && u.r.p != u) {
+			t.Fatalf("broken parent pointer at %d", u.v)
+		}
+		return 1 + walk(u.l, lo, &u.v) + walk(u.r, &u.v, hi)
+	}
+	if n := walk(b.r, nil, nil); n != b.n {
+		t.Fatalf("want %d nodes, got %d", b.n, n)
+	}
+}
+
+// checkTreap checks that the priorities
+// of the nodes form a min-heap.
+func checkTreap(t *testing.T, tr *Treap[int]) {
+	t.Helper()
+	checkBST(t, &tr.bst)
+	var walk func(u *tnode[int])
+	walk = func(u *tnode[int]) {
+		if u == nil {
+			return
+		}
+		if (u.l != nil && u.l.c < u.c) || (u.r != nil && u.r.c < u.c) {
+			t.Fatalf("heap property violated at %d", u.v)
+		}
+		walk(u.l)
+		walk(u.r)
+	}
+	walk(tr.r)
+}
+
+// checkScapegoatTree checks that the
+// tree is not too deep and that q is
+// a valid upper bound on n.
+func checkScapegoatTree(t *testing.T, s *ScapegoatTree[int]) {
+	t.Helper()
+	checkBST(t, &s.bst)
+	if s.q < s.n || s.q > 2*s.n {
+		t.Fatalf("want q in [%d, %d], got %d", s.n, 2*s.n, s.q)
+	}
+	if h := treeHeight(&s.bst, s.r); s.n > 0 && h > log32(s.q) {
+		t.Fatalf("height %d exceeds %d", h, log32(s.q))
+	}
+}
+
+// checkRedBlackTree checks that the tree is
+// a left-leaning red-black tree: the root is
+// black, no red node has a red child, no node
+// has a red right child without a red left
+// child and all root-to-leaf paths have the
+// same number of black nodes.
+func checkRedBlackTree(t *testing.T, rb *RedBlackTree[int]) {
+	t.Helper()
+	checkBST(t, &rb.bst)
+	if rb.z.c != black {
+		t.Fatalf("sentinel is not black")
+	}
+	if rb.r.c != black {
+		t.Fatalf("root is not black")
+	}
+	var walk func(u *tnode[int]) int
+	walk = func(u *tnode[int]) int {
+		if u == rb.z {
+			return 1
+		}
+		if u.c != red && u.c != black {
+			t.Fatalf("invalid colour %d at %d", u.c, u.v)
+		}
+		if u.c == red && (u.l.c == red || u.r.c == red) {
+			t.Fatalf("red node %d has a red child", u.v)
+		}
+		if u.r.c == red && u.l.c == black {
+			t.Fatalf("node %d is not left-leaning", u.v)
+		}
+		l, r := walk(u.l), walk(u.r)
+		if l != r {
+			t.Fatalf("black heights %d and %d differ at %d", l, r, u.v)
+		}
+		return l + u.c
+	}
+	walk(rb.r)
+}
+
+// treeHeight returns the height of the subtree rooted at u.
+func treeHeight(b *bst[int], u *tnode[int]) int {
+	if u == b.z {
+		return -1
+	}
+	return 1 + max(treeHeight(b, u.l), treeHeight(b, u.r))
+}
@@ -0,0 +1,510 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"cmp"
+	"iter"
+	"math/bits"
+	"math/rand"
+)
+
+// TrieKey is the type of the keys of integer
+// tries. The number of bits w of the key type
+// determines the height of a trie.
+type TrieKey interface {
+	~uint32 | ~uint64
+}
+
+// --- Binary tries -------
+
+// trienode represents a node in a binary trie.
+// In leaves, the children are used as pointers
+// to the previous and next leaf.
+type trienode[K TrieKey, P any] struct {
+	c [2]*trienode[K, P] // children, or previous and next leaf
+	p *trienode[K, P]    // parent
+	j *trienode[K, P]    // jump pointer
+	k K                  // key, in leaves
+	x P                  // payload, in leaves
+}
+
+// trie implements the operations shared
+// by binary tries. The leaves store the
+// keys and a payload and form a doubly
+// linked list.
+type trie[K TrieKey, P any] struct {
+	r *trienode[K, P]                       // root
+	d *trienode[K, P]                       // dummy, head and tail of the list of leaves
+	n int                                   // number of elements
+	t []LinearHashTable[K, *trienode[K, P]] // nodes by prefix, one table per level in x-fast tries
+}
+
+// init lazily initializes the root and the dummy.
+func (t *trie[K, P]) init() {
+	if t.r == nil {
+		t.d = new(trienode[K, P])
+		t.d.c[0], t.d.c[1] = t.d, t.d
+		t.r = &trienode[K, P]{j: t.d}
+	}
+}
+
+// initx lazily initializes the hash tables of
+// the prefixes of the keys in x-fast tries.
+func (t *trie[K, P]) initx() {
+	if t.t == nil {
+		t.t = make([]LinearHashTable[K, *trienode[K, P]], t.w()+1)
+	}
+}
+
+// w returns the number of bits of a key.
+func (t *trie[K, P]) w() int { return bits.Len64(uint64(^K(0))) }
+
+// bit returns the bit of k at depth i.
+func (t *trie[K, P]) bit(k K, i int) int { return int(k>>(t.w()-i-1)) & 1 }
+
+// Len returns the number of elements in the trie.
+func (t *trie[K, P]) Len() int { return t.n }
+
+// succ returns the leaf of the smallest key which is
+// greater than or equal to k, or the dummy if there is
+// none, by walking down the trie.
+func (t *trie[K, P]) succ(k K) *trienode[K, P] {
+	t.init()
+	u, i, c, w := t.r, 0, 0, t.w()
+	for ; i < w; i++ {
+		c = t.bit(k, i)
+		if u.c[c] == nil {
+			break
+		}
+		u = u.c[c]
+	}
+	if i == w {
+		return u
+	}
+	if c == 0 {
+		return u.j
+	}
+	return u.j.c[1]
+}
+
+// xsucc returns the leaf of the smallest key which is
+// greater than or equal to k, or the dummy if there is
+// none, by binary searching the longest prefix of k.
+func (t *trie[K, P]) xsucc(k K) *trienode[K, P] {
+	t.init()
+	w := t.w()
+	u, l, h := t.r, 0, w+1
+	for h-l > 1 {
+		i := (l + h) / 2
+		if v, ok := t.t[i].Get(k >> (w - i)); ok {
+			u, l = v, i
+		} else {
+			h = i
+		}
+	}
+	if l == w {
+		return u
+	}
+	if t.bit(k, l) == 0 {
+		return u.j
+	}
+	return u.j.c[1]
+}
+
+// add adds the key k with the payload x and reports
+// whether it was added, i.e. it was not already
+// contained in the trie.
+func (t *trie[K, P]) add(k K, x P) bool {
+	t.init()
+	u, i, c, w := t.r, 0, 0, t.w()
+	for ; i < w; i++ {
+		c = t.bit(k, i)
+		if u.c[c] == nil {
+			break
+		}
+		u = u.c[c]
+	}
+	if i == w {
+		return false
+	}
+	var pred *trienode[K, P]
+	if c == 1 {
+		pred = u.j
+	} else {
+		pred = u.j.c[0]
+	}
+	// u has two children after adding the path
+	u.j = nil
+	for ; i < w; i++ {
+		c = t.bit(k, i)
+		u.c[c] = &trienode[K, P]{p: u}
+		u = u.c[c]
+		if t.t != nil {
+			t.t[i+1].Put(k>>(w-i-1), u)
+		}
+	}
+	u.k, u.x = k, x
+	u.c[0], u.c[1] = pred, pred.c[1]
+	u.c[0].c[1], u.c[1].c[0] = u, u
+	for v := u.p; v != nil; v = v.p {
+		if (v.c[0] == nil && (v.j == nil || v.j.k > k)) ||
+			(v.c[1] == nil && (v.j == nil || v.j.k < k)) {
+			v.j = u
+		}
+	}
+	t.n++
+	return true
+}
+
+// remove removes the key k and returns its
+// payload, if it was contained in the trie.
+func (t *trie[K, P]) remove(k K) (P, bool) {
+	if t.n == 0 {
+		return *new(P), false
+	}
+	u, w := t.r, t.w()
+	for i := 0; i < w; i++ {
+		if u = u.c[t.bit(k, i)]; u == nil {
+			return *new(P), false
+		}
+	}
+	u.c[0].c[1], u.c[1].c[0] = u.c[1], u.c[0]
+	// delete the nodes on the path, which have no other child
+	v, i := u, w-1
+	for ; i >= 0; i-- {
+		c := t.bit(k, i)
+		if t.t != nil {
+			t.t[i+1].Remove(k >> (w - i - 1))
+		}
+		v = v.p
+		v.c[c] = nil
+		if v.c[1-c] != nil {
+			break
+		}
+	}
+	if i < 0 {
+		// the trie is empty
+		t.r.j = t.d
+	} else {
+		v.j = u.c[1-t.bit(k, i)]
+		for v, i = v.p, i-1; i >= 0; v, i = v.p, i-1 {
+			if v.j == u {
+				v.j = u.c[t.bit(k, i)]
+			}
+		}
+	}
+	t.n--
+	return u.x, true
+}
+
+// Min returns the smallest element of the trie.
+//
+// This operation has a time complexity of O(1).
+func (t *trie[K, P]) Min() (K, bool) {
+	if t.n == 0 {
+		return 0, false
+	}
+	return t.d.c[1].k, true
+}
+
+// Max returns the largest element of the trie.
+//
+// This operation has a time complexity of O(1).
+func (t *trie[K, P]) Max() (K, bool) {
+	if t.n == 0 {
+		return 0, false
+	}
+	return t.d.c[0].k, true
+}
+
+// All returns an iterator over the
+// elements of the trie, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (t *trie[K, P]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		if t.n == 0 {
+			return
+		}
+		for u := t.d.c[1]; u != t.d; u = u.c[1] {
+			if !yield(u.k) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the
+// elements of the trie, in descending order.
+//
+// This operation has a time complexity of O(n).
+func (t *trie[K, P]) Backward() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		if t.n == 0 {
+			return
+		}
+		for u := t.d.c[0]; u != t.d; u = u.c[0] {
+			if !yield(u.k) {
+				return
+			}
+		}
+	}
+}
+
+// --- BinaryTrie -------
+
+// BinaryTrie implements a sorted set of
+// w-bit integers on top of a binary tree
+// of height w, whose leaves are the keys.
+// The zero value is an empty trie.
+type BinaryTrie[K TrieKey] struct {
+	trie[K, struct{}]
+}
+
+// Find returns the smallest element
+// which is greater than or equal to
+// k and reports whether it exists.
+//
+// This operation has a time complexity of O(w).
+func (t *BinaryTrie[K]) Find(k K) (K, bool) {
+	u := t.succ(k)
+	if u == t.d {
+		return 0, false
+	}
+	return u.k, true
+}
+
+// Add adds an element to the trie and
+// reports whether it was added, i.e. it
+// was not already contained in the trie.
+//
+// This operation has a time complexity of O(w).
+func (t *BinaryTrie[K]) Add(k K) bool { return t.add(k, struct{}{}) }
+
+// Remove removes an element from the trie
+// and reports whether it was removed, i.e.
+// it was contained in the trie.
+//
+// This operation has a time complexity of O(w).
+func (t *BinaryTrie[K]) Remove(k K) bool {
+	_, ok := t.remove(k)
+	return ok
+}
+
+// --- XFastTrie -------
+
+// XFastTrie implements a sorted set of
+// w-bit integers on top of a binary trie,
+// whose nodes are also stored in one hash
+// table per level. The zero value is an
+// empty trie.
+type XFastTrie[K TrieKey] struct {
+	trie[K, struct{}]
+}
+
+// Find returns the smallest element
+// which is greater than or equal to
+// k and reports whether it exists.
+//
+// This operation has an expected time
+// complexity of O(log w).
+func (t *XFastTrie[K]) Find(k K) (K, bool) {
+	t.initx()
+	u := t.xsucc(k)
+	if u == t.d {
+		return 0, false
+	}
+	return u.k, true
+}
+
+// Add adds an element to the trie and
+// reports whether it was added, i.e. it
+// was not already contained in the trie.
+//
+// This operation has an amortized expected
+// time complexity of O(w).
+func (t *XFastTrie[K]) Add(k K) bool {
+	t.initx()
+	return t.add(k, struct{}{})
+}
+
+// Remove removes an element from the trie
+// and reports whether it was removed, i.e.
+// it was contained in the trie.
+//
+// This operation has an amortized expected
+// time complexity of O(w).
+func (t *XFastTrie[K]) Remove(k K) bool {
+	_, ok := t.remove(k)
+	return ok
+}
+
+// --- YFastTrie -------
+
+// YFastTrie implements a sorted set of w-bit
+// integers on top of treaps, which store about
+// w elements each. An x-fast trie stores the
+// largest key each treap is responsible for.
+// The zero value is an empty trie.
+type YFastTrie[K TrieKey] struct {
+	x   trie[K, *Treap[K]] // treaps by the largest key they are responsible for
+	n   int                // number of elements
+	rnd *rand.Rand         // source for splits and priorities
+}
+
+// Seed seeds the source for the splits of the
+// treaps and for the priorities of their nodes,
+// which makes the shape of the trie deterministic.
+func (t *YFastTrie[K]) Seed(seed int64) {
+	t.rnd = rand.New(rand.NewSource(seed))
+	if t.x.n == 0 {
+		return
+	}
+	for u := t.x.d.c[1]; u != t.x.d; u = u.c[1] {
+		u.x.rnd = t.rnd
+	}
+}
+
+// init lazily initializes the x-fast trie
+// with a treap for all keys and the source
+// for splits and priorities.
+func (t *YFastTrie[K]) init() {
+	if t.rnd == nil {
+		t.rnd = rand.New(rand.NewSource(rand.Int63()))
+	}
+	if t.x.n == 0 {
+		t.x.initx()
+		t.x.add(^K(0), t.newTreap())
+	}
+}
+
+func (t *YFastTrie[K]) newTreap() *Treap[K] {
+	return &Treap[K]{bst: bst[K]{cmp: cmp.Compare[K]}, rnd: t.rnd}
+}
+
+// Len returns the number of elements in the trie.
+func (t *YFastTrie[K]) Len() int { return t.n }
+
+// Find returns the smallest element
+// which is greater than or equal to
+// k and reports whether it exists.
+//
+// This operation has an expected time
+// complexity of O(log w).
+func (t *YFastTrie[K]) Find(k K) (K, bool) {
+	if t.n == 0 {
+		return 0, false
+	}
+	return t.x.xsucc(k).x.Find(k)
+}
+
+// Add adds an element to the trie and
+// reports whether it was added, i.e. it
+// was not already contained in the trie.
+//
+// This operation has an amortized expected
+// time complexity of O(log w).
+func (t *YFastTrie[K]) Add(k K) bool {
+	t.init()
+	r := t.x.xsucc(k).x
+	if !r.Add(k) {
+		return false
+	}
+	t.n++
+	// make k the largest key of a new treap with probability 1/w
+	if k != ^K(0) && t.rnd.Intn(t.x.w()) == 0 {
+		t.x.add(k, r.split(k))
+	}
+	return true
+}
+
+// Remove removes an element from the trie
+// and reports whether it was removed, i.e.
+// it was contained in the trie.
+//
+// This operation has an amortized expected
+// time complexity of O(log w).
+func (t *YFastTrie[K]) Remove(k K) bool {
+	if t.n == 0 {
+		return false
+	}
+	u := t.x.xsucc(k)
+	if !u.x.Remove(k) {
+		return false
+	}
+	t.n--
+	// merge the treap of k into the next one
+	if u.k == k && k != ^K(0) {
+		u.c[1].x.absorb(u.x)
+		t.x.remove(k)
+	}
+	return true
+}
+
+// Min returns the smallest element of the trie.
+//
+// This operation has an expected time
+// complexity of O(log w).
+func (t *YFastTrie[K]) Min() (K, bool) {
+	if t.n == 0 {
+		return 0, false
+	}
+	return t.x.d.c[1].x.Min()
+}
+
+// Max returns the largest element of the trie.
+//
+// This operation has an expected time
+// complexity of O(log w).
+func (t *YFastTrie[K]) Max() (K, bool) {
+	if t.n == 0 {
+		return 0, false
+	}
+	// only the last treap may be empty
+	u := t.x.d.c[0]
+	if u.x.Len() == 0 {
+		u = u.c[0]
+	}
+	return u.x.Max()
+}
+
+// All returns an iterator over the
+// elements of the trie, in ascending order.
+//
+// This operation has a time complexity of O(n).
+func (t *YFastTrie[K]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		if t.n == 0 {
+			return
+		}
+		for u := t.x.d.c[1]; u != t.x.d; u = u.c[1] {
+			for k := range u.x.All() {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the
+// elements of the trie, in descending order.
+//
+// This operation has a time complexity of O(n).
+func (t *YFastTrie[K]) Backward() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		if t.n == 0 {
+			return
+		}
+		for u := t.x.d.c[0]; u != t.x.d; u = u.c[0] {
+			for k := range u.x.Backward() {
+				if !yield(k) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2016 David R. Jenni. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ds
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTries(t *testing.T) {
+	t.Run("32", func(t *testing.T) { testTries[uint32](t) })
+	t.Run("64", func(t *testing.T) { testTries[uint64](t) })
+}
+
+func testTries[K TrieKey](t *testing.T) {
+	bt := new(BinaryTrie[K])
+	xt := new(XFastTrie[K])
+	yt := new(YFastTrie[K])
+	yt.Seed(1)
+
+	tries := map[string]struct {
+		s     SSet[K]
+		check func(t *testing.T)
+	}{
+		"BinaryTrie": {bt, func(t *testing.T) { checkTrie(t, &bt.trie) }},
+		"XFastTrie":  {xt, func(t *testing.T) { checkTrie(t, &xt.trie) }},
+		"YFastTrie":  {yt, func(t *testing.T) { checkYFastTrie(t, yt) }},
+	}
+	// small keys collide, large keys exercise the high bits
+	keys := func(r *rand.Rand) K {
+		switch r.Intn(3) {
+		case 0:
+			return K(r.Intn(500))
+		case 1:
+			return ^K(0) - K(r.Intn(500))
+		}
+		return K(r.Uint64())
+	}
+	for name, x := range tries {
+		s := x.s
+		if _, ok := s.Find(0); ok {
+			t.Errorf("%s: no element in trie expected", name)
+		}
+		if _, ok := s.Min(); ok {
+			t.Errorf("%s: no element in trie expected", name)
+		}
+		if _, ok := s.Max(); ok {
+			t.Errorf("%s: no element in trie expected", name)
+		}
+		if ok := s.Remove(0); ok {
+			t.Errorf("%s: no element to remove expected", name)
+		}
+
+		const n = 3000
+		r := rand.New(rand.NewSource(1))
+		var e []K
+		for k := 0; k < n; k++ {
+			v := keys(r)
+			if len(e) > 0 && r.Intn(2) == 0 {
+				v = e[r.Intn(len(e))]
+			}
+			i, found := slices.BinarySearch(e, v)
+			if (k < n/2 && r.Intn(4) > 0) || (k >= n/2 && r.Intn(2) == 0) {
+				if ok := s.Add(v); ok == found {
+					t.Fatalf("%s: add %d: want %t, got %t", name, v, !found, ok)
+				}
+				if !found {
+					e = slices.Insert(e, i, v)
+				}
+			} else {
+				if ok := s.Remove(v); ok != found {
+					t.Fatalf("%s: remove %d: want %t, got %t", name, v, found, ok)
+				}
+				if found {
+					e = slices.Delete(e, i, i+1)
+				}
+			}
+			if s.Len() != len(e) {
+				t.Fatalf("%s: want %d, got %d", name, len(e), s.Len())
+			}
+			if k%50 == 0 {
+				x.check(t)
+			}
+		}
+		x.check(t)
+
+		for k := 0; k < n; k++ {
+			v := keys(r)
+			if k%3 == 0 && len(e) > 0 {
+				v = e[r.Intn(len(e))]
+			}
+			i, _ := slices.BinarySearch(e, v)
+			f, ok := s.Find(v)
+			if i == len(e) {
+				if ok {
+					t.Errorf("%s: find %d: no element expected, got %d", name, v, f)
+				}
+			} else if !ok || f != e[i] {
+				t.Errorf("%s: find %d: want %d, got %d", name, v, e[i], f)
+			}
+		}
+		if v, _ := s.Min(); v != e[0] {
+			t.Errorf("%s: want %d, got %d", name, e[0], v)
+		}
+		if v, _ := s.Max(); v != e[len(e)-1] {
+			t.Errorf("%s: want %d, got %d", name, e[len(e)-1], v)
+		}
+		if a := slices.Collect(s.All()); !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		a := slices.Collect(s.Backward())
+		slices.Reverse(a)
+		if !slices.Equal(a, e) {
+			t.Errorf("%s: want %v, got %v", name, e, a)
+		}
+		for _, v := range e {
+			s.Remove(v)
+		}
+		x.check(t)
+		if s.Len() != 0 {
+			t.Errorf("%s: want %d, got %d", name, 0, s.Len())
+		}
+	}
+}
+
+func TestYFastTrieMaxKey(t *testing.T) {
+	var y YFastTrie[uint32]
+	y.Seed(1)
+	for _, v := range []uint32{^uint32(0), 7, ^uint32(0) - 1} {
+		if !y.Add(v) {
+			t.Errorf("add %d: want true, got false", v)
+		}
+	}
+	if v, ok := y.Max(); !ok || v != ^uint32(0) {
+		t.Errorf("want %d, got %d", ^uint32(0), v)
+	}
+	if !y.Remove(^uint32(0)) {
+		t.Errorf("remove %d: want true, got false", ^uint32(0))
+	}
+	if v, ok := y.Max(); !ok || v != ^uint32(0)-1 {
+		t.Errorf("want %d, got %d", ^uint32(0)-1, v)
+	}
+	if v, ok := y.Find(8); !ok || v != ^uint32(0)-1 {
+		t.Errorf("want %d, got %d", ^uint32(0)-1, v)
+	}
+	checkYFastTrie(t, &y)
+}
+
+// checkTrie checks the leaves, the jump
+// pointers and the hash tables of a trie.
+func checkTrie[K TrieKey, P any](t *testing.T, tr *trie[K, P]) {
+	t.Helper()
+	if tr.r == nil {
+		return
+	}
+	w := tr.w()
+	var leaves []*trienode[K, P]
+	levels := make([]int, w+1)
+	var walk func(u *trienode[K, P], i int, prefix K) (lo, hi *trienode[K, P])
+	walk = func(u *trienode[K, P], i int, prefix K) (lo, hi *trienode[K, P]) {
+		levels[i]++
+		if tr.t != nil && i > 0 {
+			if v, ok := tr.t[i].Get(prefix); !ok || v != u {
+				t.Fatalf("node with prefix %x at depth %d not in hash table", prefix, i)
+			}
+		}
+		if i == w {
+			if u.k != prefix {
+				t.Fatalf("want key %x, got %x", prefix, u.k)
+			}
+			leaves = append(leaves, u)
+			return u, u
+		}
+		l, r := u.c[0], u.c[1]
+		if l != nil {
+			if l.p != u {
+				t.Fatalf("wrong parent at depth %d", i+1)
+			}
+			lo, hi = walk(l, i+1, prefix<<1)
+		}
+		if r != nil {
+			if r.p != u {
+				t.Fatalf("wrong parent at depth %d", i+1)
+			}
+			rlo, rhi := walk(r, i+1, prefix<<1|1)
+			if lo == nil {
+				lo = rlo
+			}
+			hi = rhi
+		}
+		switch {
+		case l != nil && r != nil && u.j != nil:
+			t.Fatalf("unexpected jump pointer at depth %d", i)
+		case l == nil && r != nil && u.j != lo:
+			t.Fatalf("jump pointer at depth %d: want %x", i, lo.k)
+		case l != nil && r == nil && u.j != hi:
+			t.Fatalf("jump pointer at depth %d: want %x", i, hi.k)
+		case l == nil && r == nil && (u != tr.r || u.j != tr.d):
+			t.Fatalf("unexpected node without children at depth %d", i)
+		}
+		return lo, hi
+	}
+	walk(tr.r, 0, 0)
+	if len(leaves) != tr.n {
+		t.Fatalf("want %d leaves, got %d", tr.n, len(leaves))
+	}
+	p := tr.d
+	for _, u := range leaves {
+		if p.c[1] != u || u.c[0] != p {
+			t.Fatalf("leaf %x is not linked", u.k)
+		}
+		p = u
+	}
+	if p.c[1] != tr.d || tr.d.c[0] != p {
+		t.Fatalf("dummy is not linked")
+	}
+	if tr.t != nil {
+		for i := 1; i <= w; i++ {
+			if tr.t[i].Len() != levels[i] {
+				t.Fatalf("want %d prefixes at depth %d, got %d", levels[i], i, tr.t[i].Len())
+			}
+		}
+	}
+}
+
+// checkYFastTrie checks the x-fast trie and
+// the ranges of the treaps of a y-fast trie.
+func checkYFastTrie[K TrieKey](t *testing.T, y *YFastTrie[K]) {
+	t.Helper()
+	checkTrie(t, &y.x)
+	if y.x.n == 0 {
+		return
+	}
+	n := 0
+	for u := y.x.d.c[1]; u != y.x.d; u = u.c[1] {
+		tr := u.x
+		if tr.size(tr.r) != tr.n {
+			t.Fatalf("want %d nodes in treap, got %d", tr.n, tr.size(tr.r))
+		}
+		if u.k != ^K(0) {
+			if v, ok := tr.Max(); !ok || v != u.k {
+				t.Fatalf("treap of %x: want largest element %x, got %x", u.k, u.k, v)
+			}
+		}
+		if p := u.c[0]; p != y.x.d {
+			if v, ok := tr.Min(); ok && v <= p.k {
+				t.Fatalf("treap of %x: %x belongs to %x", u.k, v, p.k)
+			}
+		}
+		n += tr.n
+	}
+	if n != y.n {
+		t.Fatalf("want %d elements, got %d", y.n, n)
+	}
+}